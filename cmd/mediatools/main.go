@@ -1,14 +1,31 @@
 package main
 
 import (
+	"flag"
+	"fmt"
+	"os"
+
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/app"
 	"github.com/Developpeur-du-dimanche/MediaTools/internal/i18n"
 	"github.com/Developpeur-du-dimanche/MediaTools/internal/mediatools"
+	"github.com/Developpeur-du-dimanche/MediaTools/internal/services"
 	"github.com/Developpeur-du-dimanche/MediaTools/internal/theme"
 )
 
 func main() {
+	statsFolder := flag.String("stats", "", "scan `folder` and print its library statistics as JSON")
+	wastedCount := flag.Int("wasted", services.DefaultWastedSpaceCount, "number of files listed in most_wasted_space")
+	flag.Parse()
+
+	// The statistics are printed without starting the graphical application
+	if *statsFolder != "" {
+		if err := runStats(*statsFolder, *wastedCount); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
 
 	a := app.NewWithID("com.TOomaAh.mediatools")
 	app.SetMetadata(fyne.AppMetadata{
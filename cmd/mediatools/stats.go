@@ -0,0 +1,43 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"time"
+
+	"github.com/Developpeur-du-dimanche/MediaTools/internal/services"
+	"github.com/Developpeur-du-dimanche/MediaTools/internal/utils"
+	"github.com/Developpeur-du-dimanche/MediaTools/pkg/logger"
+	"github.com/Developpeur-du-dimanche/MediaTools/pkg/medias"
+)
+
+// runStats scans a folder and prints its library statistics as JSON on stdout
+func runStats(folder string, wastedCount int) error {
+	// Keep stdout for the JSON summary
+	logger.SetDefault(logger.New(os.Stderr, logger.LevelWarn))
+
+	ctx := context.Background()
+	mediaService := services.NewMediaService(utils.GetValidExtensions(), 10*time.Second)
+
+	paths, err := mediaService.ScanFolder(ctx, folder, nil)
+	if err != nil {
+		return err
+	}
+
+	files := make([]*medias.FfprobeResult, 0, len(paths))
+	for _, path := range paths {
+		info, err := mediaService.GetMediaInfo(ctx, path)
+		if err != nil {
+			logger.Warnf("Skipping %s: %v", path, err)
+			continue
+		}
+		files = append(files, info)
+	}
+
+	stats := services.ComputeLibraryStats(files, wastedCount)
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(stats)
+}
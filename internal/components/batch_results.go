@@ -0,0 +1,139 @@
+package components
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+	"github.com/Developpeur-du-dimanche/MediaTools/internal/services"
+	"github.com/Developpeur-du-dimanche/MediaTools/pkg/logger"
+)
+
+// batchResultColumns are the columns of the batch results table
+var batchResultColumns = []struct {
+	title string
+	width float32
+}{
+	{"File", 220},
+	{"Status", 80},
+	{"Time", 70},
+	{"Output", 220},
+	{"Details", 360},
+}
+
+// ShowBatchResultsDialog shows the outcome of every file of a batch in a
+// table. The log can be exported; onRetry is called to run the failed files
+// again and is hidden when nil.
+func ShowBatchResultsDialog(window fyne.Window, result *services.BatchResult, onRetry func()) {
+	_, done, failed := result.Counts()
+	summary := widget.NewLabelWithStyle(
+		fmt.Sprintf("%d/%d files processed, %d failed, in %s", done, len(result.Files), failed, result.Duration.Round(time.Second)),
+		fyne.TextAlignLeading,
+		fyne.TextStyle{Bold: true},
+	)
+
+	table := widget.NewTable(
+		func() (int, int) {
+			return len(result.Files) + 1, len(batchResultColumns)
+		},
+		func() fyne.CanvasObject {
+			label := widget.NewLabel("")
+			label.Truncation = fyne.TextTruncateEllipsis
+			return label
+		},
+		func(id widget.TableCellID, obj fyne.CanvasObject) {
+			label := obj.(*widget.Label)
+			label.TextStyle = fyne.TextStyle{Bold: id.Row == 0}
+			label.Importance = widget.MediumImportance
+			if id.Row == 0 {
+				label.SetText(batchResultColumns[id.Col].title)
+				return
+			}
+
+			file := result.Files[id.Row-1]
+			if file.Status == services.EntryFailed {
+				label.Importance = widget.DangerImportance
+			}
+			label.SetText(batchResultCell(file, id.Col))
+		},
+	)
+	for i, column := range batchResultColumns {
+		table.SetColumnWidth(i, column.width)
+	}
+
+	var resultsDialog dialog.Dialog
+
+	exportButton := widget.NewButtonWithIcon("Export Log", theme.DocumentSaveIcon(), func() {
+		exportBatchLog(window, result)
+	})
+
+	buttons := container.NewHBox(exportButton)
+	if onRetry != nil {
+		retryButton := widget.NewButtonWithIcon("Retry Failed", theme.ViewRefreshIcon(), func() {
+			resultsDialog.Hide()
+			onRetry()
+		})
+		retryButton.Importance = widget.HighImportance
+		if len(result.Retryable()) == 0 {
+			retryButton.Disable()
+		}
+		buttons.Add(retryButton)
+	}
+
+	content := container.NewBorder(summary, buttons, nil, nil, table)
+
+	resultsDialog = dialog.NewCustom("Batch Results", "Close", content, window)
+	resultsDialog.Resize(fyne.NewSize(1000, 600))
+	resultsDialog.Show()
+}
+
+// batchResultCell returns the text of a column for a file
+func batchResultCell(file *services.JournalEntry, column int) string {
+	switch column {
+	case 0:
+		return filepath.Base(file.InputPath)
+	case 1:
+		return string(file.Status)
+	case 2:
+		if file.Duration == 0 {
+			return ""
+		}
+		return file.Duration.Round(100 * time.Millisecond).String()
+	case 3:
+		if file.Status != services.EntryDone {
+			return ""
+		}
+		return file.OutputPath
+	default:
+		if file.Error != "" {
+			return strings.ReplaceAll(file.Error, "\n", " ")
+		}
+		if file.Step != nil {
+			return file.Step.Note
+		}
+		return ""
+	}
+}
+
+// exportBatchLog saves the log of a batch to a file chosen by the user
+func exportBatchLog(window fyne.Window, result *services.BatchResult) {
+	saveDialog := dialog.NewFileSave(func(writer fyne.URIWriteCloser, err error) {
+		if err != nil || writer == nil {
+			return
+		}
+		defer writer.Close()
+
+		if _, err := writer.Write([]byte(result.Log())); err != nil {
+			logger.Errorf("Failed to export batch log: %v", err)
+			dialog.ShowError(err, window)
+		}
+	}, window)
+	saveDialog.SetFileName(fmt.Sprintf("batch_%s.log", result.StartedAt.Format("20060102_150405")))
+	saveDialog.Show()
+}
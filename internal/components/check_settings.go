@@ -0,0 +1,84 @@
+package components
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"github.com/Developpeur-du-dimanche/MediaTools/internal/services"
+	"github.com/Developpeur-du-dimanche/MediaTools/pkg/logger"
+	"github.com/Developpeur-du-dimanche/MediaTools/pkg/medias"
+)
+
+// PreferenceKeyCheckOptions is the key used to store the integrity check
+// options of each library, as a JSON object keyed by library folder
+const PreferenceKeyCheckOptions = "check_options_by_library"
+
+// libraryFolder returns the deepest folder holding every file, which
+// identifies the library the check options are saved for
+func libraryFolder(files []*medias.FfprobeResult) string {
+	if len(files) == 0 {
+		return ""
+	}
+
+	folder := filepath.Dir(files[0].Format.Filename)
+	for _, file := range files[1:] {
+		for !isInFolder(file.Format.Filename, folder) {
+			parent := filepath.Dir(folder)
+			if parent == folder {
+				return folder
+			}
+			folder = parent
+		}
+	}
+	return folder
+}
+
+// isInFolder reports whether path is inside folder
+func isInFolder(path, folder string) bool {
+	rel, err := filepath.Rel(folder, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// savedCheckOptions returns the check options saved for every library
+func savedCheckOptions() map[string]services.CheckOptions {
+	saved := make(map[string]services.CheckOptions)
+	data := fyne.CurrentApp().Preferences().String(PreferenceKeyCheckOptions)
+	if data == "" {
+		return saved
+	}
+	if err := json.Unmarshal([]byte(data), &saved); err != nil {
+		logger.Warnf("Ignoring invalid saved check options: %v", err)
+	}
+	return saved
+}
+
+// loadCheckOptions returns the check options of the closest library holding
+// folder, or the default options
+func loadCheckOptions(folder string) services.CheckOptions {
+	saved := savedCheckOptions()
+	for {
+		if options, ok := saved[folder]; ok {
+			return options
+		}
+		parent := filepath.Dir(folder)
+		if parent == folder {
+			return services.DefaultCheckOptions()
+		}
+		folder = parent
+	}
+}
+
+// saveCheckOptions stores the check options of a library
+func saveCheckOptions(folder string, options services.CheckOptions) {
+	saved := savedCheckOptions()
+	saved[folder] = options
+
+	data, err := json.Marshal(saved)
+	if err != nil {
+		logger.Warnf("Failed to save check options: %v", err)
+		return
+	}
+	fyne.CurrentApp().Preferences().SetString(PreferenceKeyCheckOptions, string(data))
+}
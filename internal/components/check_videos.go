@@ -4,6 +4,8 @@ import (
 	"context"
 	"fmt"
 	"path/filepath"
+	"strconv"
+	"time"
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/container"
@@ -19,23 +21,54 @@ import (
 type CheckVideosComponent struct {
 	widget.BaseWidget
 
-	window        fyne.Window
-	ffmpegService *services.FFmpegService
-	selectedFiles []*medias.FfprobeResult
+	window          fyne.Window
+	ffmpegService   *services.FFmpegService
+	checksumService *services.ChecksumService
+	selectedFiles   []*medias.FfprobeResult
 
 	// UI elements
-	resultsList *widget.List
-	progressBar *widget.ProgressBar
-	statusLabel *widget.Label
-	checkButton *widget.Button
-	filesList   *widget.List
+	resultsList       *widget.List
+	progressBar       *widget.ProgressBar
+	statusLabel       *widget.Label
+	checkButton       *widget.Button
+	regressionsButton *widget.Button
+	manifestButton    *widget.Button
+	filesList         *widget.List
+	modeRadio         *widget.RadioGroup
+	samplesEntry      *widget.Entry
+	streamsRadio      *widget.RadioGroup
+	errDetect         *widget.Select
+	stopCheck         *widget.Check
+	skipCheck         *widget.Check
+
+	// library is the folder the check options are saved for
+	library string
 
 	// Data
 	checkResults []*services.VideoCheckResult
+	// lastChecks are the last checks of the files that didn't change since, by path
+	lastChecks map[string]services.CheckRecord
 
 	onComplete func(results []*services.VideoCheckResult)
 }
 
+// checkModeLabels are the labels of the check modes, with what they detect
+var checkModeLabels = map[services.CheckMode]string{
+	services.CheckModeQuick:   "Quick - container, index and duration",
+	services.CheckModeSampled: "Sampled - decode evenly spaced segments",
+	services.CheckModeFull:    "Full - decode the whole file",
+}
+
+// checkStreamsLabels are the labels of the streams a check can read
+var checkStreamsLabels = map[services.CheckStreams]string{
+	services.CheckStreamsAll:   "All streams",
+	services.CheckStreamsVideo: "Video only",
+	services.CheckStreamsAudio: "Audio only",
+}
+
+// defaultErrDetectLabel selects the decoder default error detection
+const defaultErrDetectLabel = "Decoder default"
+
 // NewCheckVideosComponent creates a new component for checking videos
 func NewCheckVideosComponent(window fyne.Window, files []*medias.FfprobeResult, ffmpegService *services.FFmpegService) *CheckVideosComponent {
 	cvc := &CheckVideosComponent{
@@ -43,8 +76,10 @@ func NewCheckVideosComponent(window fyne.Window, files []*medias.FfprobeResult,
 		ffmpegService: ffmpegService,
 		selectedFiles: files,
 		checkResults:  make([]*services.VideoCheckResult, 0),
+		library:       libraryFolder(files),
 	}
 
+	cvc.loadLastChecks()
 	cvc.initUI()
 	cvc.ExtendBaseWidget(cvc)
 	return cvc
@@ -62,7 +97,11 @@ func (cvc *CheckVideosComponent) initUI() {
 		func(id widget.ListItemID, obj fyne.CanvasObject) {
 			label := obj.(*widget.Label)
 			file := cvc.selectedFiles[id]
-			label.SetText(filepath.Base(file.Format.Filename))
+			text := filepath.Base(file.Format.Filename)
+			if last, ok := cvc.lastChecks[file.Format.Filename]; ok {
+				text += " — last check: " + last.String()
+			}
+			label.SetText(text)
 		},
 	)
 
@@ -84,12 +123,16 @@ func (cvc *CheckVideosComponent) initUI() {
 				statusLabel := hbox.Objects[0].(*widget.Label)
 				fileLabel := hbox.Objects[1].(*widget.Label)
 
-				if result.IsValid {
+				if result.Skipped {
+					statusLabel.SetText("✓ SKIPPED")
+				} else if result.NoStreams {
+					statusLabel.SetText("– NO STREAM")
+				} else if result.IsValid {
 					statusLabel.SetText("✓ OK")
 				} else {
 					statusLabel.SetText("✗ CORRUPTED")
 				}
-				fileLabel.SetText(filepath.Base(result.FilePath))
+				fileLabel.SetText(fmt.Sprintf("%s [%s]", filepath.Base(result.FilePath), result.Options.Mode))
 
 				// Add click to show details
 				if !result.IsValid && result.Error != "" {
@@ -105,7 +148,7 @@ func (cvc *CheckVideosComponent) initUI() {
 			if !result.IsValid && result.Error != "" {
 				dialog.ShowInformation(
 					"Error Details",
-					fmt.Sprintf("File: %s\n\nErrors:\n%s", filepath.Base(result.FilePath), result.Error),
+					fmt.Sprintf("File: %s\nCheck: %s\n\nErrors:\n%s", filepath.Base(result.FilePath), result.Options, result.Error),
 					cvc.window,
 				)
 			}
@@ -113,6 +156,43 @@ func (cvc *CheckVideosComponent) initUI() {
 		cvc.resultsList.UnselectAll()
 	}
 
+	// Check options, restored from the last check of the library
+	saved := loadCheckOptions(cvc.library)
+
+	modeOptions := make([]string, len(services.CheckModes))
+	for i, mode := range services.CheckModes {
+		modeOptions[i] = checkModeLabels[mode]
+	}
+	cvc.samplesEntry = widget.NewEntry()
+	cvc.samplesEntry.SetText(strconv.Itoa(saved.Samples))
+	cvc.errDetect = widget.NewSelect(append([]string{defaultErrDetectLabel}, services.ErrDetectLevels...), nil)
+	cvc.errDetect.SetSelected(defaultErrDetectLabel)
+	if saved.ErrDetect != "" {
+		cvc.errDetect.SetSelected(saved.ErrDetect)
+	}
+	cvc.modeRadio = widget.NewRadioGroup(modeOptions, cvc.updateModeOptions)
+	cvc.modeRadio.Horizontal = true
+	cvc.modeRadio.Required = true
+	cvc.modeRadio.SetSelected(checkModeLabels[saved.Mode])
+
+	cvc.streamsRadio = widget.NewRadioGroup([]string{
+		checkStreamsLabels[services.CheckStreamsAll],
+		checkStreamsLabels[services.CheckStreamsVideo],
+		checkStreamsLabels[services.CheckStreamsAudio],
+	}, nil)
+	cvc.streamsRadio.Horizontal = true
+	cvc.streamsRadio.Required = true
+	cvc.streamsRadio.SetSelected(checkStreamsLabels[services.CheckStreamsAll])
+	if label, ok := checkStreamsLabels[saved.Streams]; ok {
+		cvc.streamsRadio.SetSelected(label)
+	}
+
+	cvc.stopCheck = widget.NewCheck("Stop at the first error", nil)
+	cvc.stopCheck.SetChecked(saved.StopAtFirstError)
+
+	cvc.skipCheck = widget.NewCheck("Skip files unchanged since they passed", nil)
+	cvc.skipCheck.SetChecked(saved.SkipPassed)
+
 	// Progress bar
 	cvc.progressBar = widget.NewProgressBar()
 	cvc.progressBar.Hide()
@@ -126,6 +206,30 @@ func (cvc *CheckVideosComponent) initUI() {
 		cvc.startChecking()
 	})
 	cvc.checkButton.Importance = widget.HighImportance
+
+	cvc.regressionsButton = widget.NewButtonWithIcon("Regressions", theme.WarningIcon(), func() {
+		cvc.showRegressions()
+	})
+
+	cvc.manifestButton = widget.NewButtonWithIcon("Verify Manifest", theme.ConfirmIcon(), func() {
+		paths := make([]string, len(cvc.selectedFiles))
+		for i, file := range cvc.selectedFiles {
+			paths[i] = file.Format.Filename
+		}
+		ShowChecksumsDialog(cvc.window, cvc.checksumService, paths)
+	})
+	cvc.manifestButton.Hide()
+}
+
+// SetOnComplete sets the function called with the results of every finished check
+func (cvc *CheckVideosComponent) SetOnComplete(onComplete func(results []*services.VideoCheckResult)) {
+	cvc.onComplete = onComplete
+}
+
+// SetChecksumService enables checksum manifests for the files to check
+func (cvc *CheckVideosComponent) SetChecksumService(checksumService *services.ChecksumService) {
+	cvc.checksumService = checksumService
+	cvc.manifestButton.Show()
 }
 
 func (cvc *CheckVideosComponent) CreateRenderer() fyne.WidgetRenderer {
@@ -135,9 +239,19 @@ func (cvc *CheckVideosComponent) CreateRenderer() fyne.WidgetRenderer {
 		fyne.TextStyle{Bold: true},
 	)
 
-	instructions := widget.NewLabel("Click 'Start Checking' to verify all selected videos for corruption.")
+	instructions := widget.NewLabel("Choose how thoroughly to check, then click 'Start Checking' to verify all selected videos for corruption.")
 	instructions.Wrapping = fyne.TextWrapWord
 
+	modeSection := container.NewVBox(
+		widget.NewLabel("Check mode:"),
+		cvc.modeRadio,
+		container.NewBorder(nil, nil, widget.NewLabel("Samples:"), nil, cvc.samplesEntry),
+		container.NewBorder(nil, nil, widget.NewLabel("Streams:"), nil, cvc.streamsRadio),
+		container.NewBorder(nil, nil, widget.NewLabel("Error detection:"), nil, cvc.errDetect),
+		cvc.stopCheck,
+		cvc.skipCheck,
+	)
+
 	filesSection := container.NewBorder(
 		widget.NewLabel("Files to check:"),
 		nil,
@@ -156,14 +270,15 @@ func (cvc *CheckVideosComponent) CreateRenderer() fyne.WidgetRenderer {
 			header,
 			widget.NewSeparator(),
 			instructions,
-			widget.NewLabel(""),
+			modeSection,
+			widget.NewSeparator(),
 		),
 		container.NewVBox(
 			widget.NewLabel(""),
 			cvc.progressBar,
 			cvc.statusLabel,
 			widget.NewLabel(""),
-			cvc.checkButton,
+			container.NewBorder(nil, nil, nil, container.NewHBox(cvc.manifestButton, cvc.regressionsButton), cvc.checkButton),
 		),
 		nil,
 		nil,
@@ -173,28 +288,97 @@ func (cvc *CheckVideosComponent) CreateRenderer() fyne.WidgetRenderer {
 	return widget.NewSimpleRenderer(content)
 }
 
+// setOptionsEnabled enables or disables the check options while checking
+func (cvc *CheckVideosComponent) setOptionsEnabled(enabled bool) {
+	for _, option := range []fyne.Disableable{cvc.modeRadio, cvc.samplesEntry, cvc.streamsRadio, cvc.errDetect, cvc.stopCheck, cvc.skipCheck, cvc.regressionsButton} {
+		if enabled {
+			option.Enable()
+		} else {
+			option.Disable()
+		}
+	}
+	if enabled {
+		cvc.updateModeOptions(cvc.modeRadio.Selected)
+	}
+}
+
+// updateModeOptions enables the options used by the selected check mode
+func (cvc *CheckVideosComponent) updateModeOptions(selected string) {
+	if selected == checkModeLabels[services.CheckModeSampled] {
+		cvc.samplesEntry.Enable()
+	} else {
+		cvc.samplesEntry.Disable()
+	}
+	// Quick checks don't decode
+	if selected == checkModeLabels[services.CheckModeQuick] {
+		cvc.errDetect.Disable()
+	} else {
+		cvc.errDetect.Enable()
+	}
+}
+
+// checkOptions returns the check options chosen by the user
+func (cvc *CheckVideosComponent) checkOptions() (services.CheckOptions, error) {
+	options := services.DefaultCheckOptions()
+	for mode, label := range checkModeLabels {
+		if label == cvc.modeRadio.Selected {
+			options.Mode = mode
+		}
+	}
+
+	if options.Mode == services.CheckModeSampled {
+		samples, err := strconv.Atoi(cvc.samplesEntry.Text)
+		if err != nil || samples < 1 {
+			return options, fmt.Errorf("invalid number of samples: %q", cvc.samplesEntry.Text)
+		}
+		options.Samples = samples
+	}
+
+	for streams, label := range checkStreamsLabels {
+		if label == cvc.streamsRadio.Selected {
+			options.Streams = streams
+		}
+	}
+	if cvc.errDetect.Selected != defaultErrDetectLabel {
+		options.ErrDetect = cvc.errDetect.Selected
+	}
+	options.StopAtFirstError = cvc.stopCheck.Checked
+	options.SkipPassed = cvc.skipCheck.Checked
+	return options, nil
+}
+
 func (cvc *CheckVideosComponent) startChecking() {
+	options, err := cvc.checkOptions()
+	if err != nil {
+		dialog.ShowError(err, cvc.window)
+		return
+	}
+	saveCheckOptions(cvc.library, options)
+
 	// Reset results
 	cvc.checkResults = make([]*services.VideoCheckResult, 0)
 	cvc.resultsList.Refresh()
 
 	// Disable UI during check
 	cvc.checkButton.Disable()
+	cvc.setOptionsEnabled(false)
 	cvc.progressBar.Show()
 	cvc.progressBar.SetValue(0)
-	cvc.statusLabel.SetText("Checking videos...")
+	cvc.statusLabel.SetText(fmt.Sprintf("Checking videos (%s)...", options))
 	cvc.statusLabel.Show()
 
 	// Start checking in background
 	go func() {
 		ctx := context.Background()
-		results, err := cvc.ffmpegService.BatchCheckVideos(ctx, cvc.selectedFiles, func(progress float64, message string) {
+		started := time.Now()
+		results, err := cvc.ffmpegService.BatchCheckVideos(ctx, cvc.selectedFiles, options, func(progress float64, message string) {
 			cvc.progressBar.SetValue(progress)
 			cvc.statusLabel.SetText(message)
 		})
 
 		// Re-enable UI
 		cvc.checkButton.Enable()
+		cvc.setOptionsEnabled(true)
 
 		if err != nil {
 			logger.Errorf("Check failed: %v", err)
@@ -205,27 +389,48 @@ func (cvc *CheckVideosComponent) startChecking() {
 
 		cvc.checkResults = results
 		cvc.resultsList.Refresh()
+		cvc.loadLastChecks()
+		cvc.filesList.Refresh()
 
-		// Count corrupted files
-		corruptedCount := 0
+		// Count corrupted and skipped files
+		corruptedCount, skippedCount, noStreamsCount := 0, 0, 0
 		for _, result := range results {
+			if result.NoStreams {
+				noStreamsCount++
+				continue
+			}
 			if !result.IsValid {
 				corruptedCount++
 			}
+			if result.Skipped {
+				skippedCount++
+			}
 		}
 
-		cvc.statusLabel.SetText(fmt.Sprintf("Complete: %d OK, %d corrupted", len(results)-corruptedCount, corruptedCount))
+		// Files that passed their previous check and failed this one
+		regressed := 0
+		for _, regression := range cvc.ffmpegService.GetCheckHistory().Regressions() {
+			if !regression.Failed.CheckedAt.Before(started) {
+				regressed++
+			}
+		}
+
+		status := fmt.Sprintf("Complete (%s): %d OK, %d corrupted, %d skipped", options, len(results)-corruptedCount-noStreamsCount, corruptedCount, skippedCount)
+		if noStreamsCount > 0 {
+			status += fmt.Sprintf(", %d without %s streams", noStreamsCount, options.Streams)
+		}
+		cvc.statusLabel.SetText(status)
 
 		if corruptedCount > 0 {
-			dialog.ShowInformation(
-				"Check Complete",
-				fmt.Sprintf("Found %d corrupted file(s) out of %d.\n\nClick on a corrupted file in the results to see details.", corruptedCount, len(results)),
-				cvc.window,
-			)
+			message := fmt.Sprintf("Found %d corrupted file(s) out of %d.\n\nClick on a corrupted file in the results to see details.", corruptedCount, len(results))
+			if regressed > 0 {
+				message += fmt.Sprintf("\n\n%d file(s) passed their previous check: click 'Regressions' to see them. This often means a failing disk.", regressed)
+			}
+			dialog.ShowInformation("Check Complete", message, cvc.window)
 		} else {
 			dialog.ShowInformation(
 				"Check Complete",
-				fmt.Sprintf("All %d files are valid!", len(results)),
+				fmt.Sprintf("All %d files are valid!", len(results)-noStreamsCount),
 				cvc.window,
 			)
 		}
@@ -235,3 +440,59 @@ func (cvc *CheckVideosComponent) startChecking() {
 		}
 	}()
 }
+
+// loadLastChecks reads the last check of every file from the check history
+func (cvc *CheckVideosComponent) loadLastChecks() {
+	history := cvc.ffmpegService.GetCheckHistory()
+	cvc.lastChecks = make(map[string]services.CheckRecord)
+	for _, file := range cvc.selectedFiles {
+		if checks := history.Lookup(file.Format.Filename); checks != nil {
+			cvc.lastChecks[file.Format.Filename] = checks.Last()
+		}
+	}
+}
+
+// showRegressions lists the files that went from OK to corrupted while
+// their size and modification time stayed the same
+func (cvc *CheckVideosComponent) showRegressions() {
+	regressions := cvc.ffmpegService.GetCheckHistory().Regressions()
+	if len(regressions) == 0 {
+		dialog.ShowInformation("Regressions", "No file went from OK to corrupted.", cvc.window)
+		return
+	}
+
+	list := widget.NewList(
+		func() int {
+			return len(regressions)
+		},
+		func() fyne.CanvasObject {
+			return widget.NewLabel("")
+		},
+		func(id widget.ListItemID, obj fyne.CanvasObject) {
+			regression := regressions[id]
+			obj.(*widget.Label).SetText(fmt.Sprintf("%s — passed %s, failed %s",
+				regression.Path,
+				regression.Passed.CheckedAt.Format("2006-01-02 15:04"),
+				regression.Failed.String(),
+			))
+		},
+	)
+	list.OnSelected = func(id widget.ListItemID) {
+		regression := regressions[id]
+		dialog.ShowInformation(
+			"Error Details",
+			fmt.Sprintf("File: %s\nPassed: %s\nFailed: %s\n\nErrors:\n%s", regression.Path, regression.Passed, regression.Failed, regression.Failed.Error),
+			cvc.window,
+		)
+		list.UnselectAll()
+	}
+
+	content := container.NewBorder(
+		widget.NewLabel(fmt.Sprintf("%d file(s) went from OK to corrupted without being modified. This often means a failing disk.", len(regressions))),
+		nil, nil, nil,
+		list,
+	)
+	regressionsDialog := dialog.NewCustom("Regressions", "Close", content, cvc.window)
+	regressionsDialog.Resize(fyne.NewSize(900, 500))
+	regressionsDialog.Show()
+}
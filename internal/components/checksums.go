@@ -0,0 +1,239 @@
+package components
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sync"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+	"github.com/Developpeur-du-dimanche/MediaTools/internal/services"
+	"github.com/Developpeur-du-dimanche/MediaTools/pkg/logger"
+)
+
+// checksumColumns are the columns of the checksum results table
+var checksumColumns = []struct {
+	title string
+	width float32
+}{
+	{"File", 220},
+	{"Status", 90},
+	{"Algorithm", 80},
+	{"Manifest", 220},
+	{"Details", 320},
+}
+
+// checksumFormatLabels are the labels of the manifest formats
+var checksumFormatLabels = map[services.ManifestFormat]string{
+	services.ManifestText: "Text (checksums.sha256 / .xxh64)",
+	services.ManifestJSON: "JSON with sizes and dates (checksums.json)",
+}
+
+// ShowChecksumsDialog generates or verifies the checksum manifests of the
+// folders of paths. Hashing runs in the background and can be cancelled.
+func ShowChecksumsDialog(window fyne.Window, checksumService *services.ChecksumService, paths []string) {
+	var (
+		mu      sync.Mutex
+		results []*services.ChecksumResult
+		cancel  context.CancelFunc
+	)
+
+	algorithms := make([]string, len(services.HashAlgorithms))
+	for i, algorithm := range services.HashAlgorithms {
+		algorithms[i] = string(algorithm)
+	}
+	algorithmSelect := widget.NewSelect(algorithms, nil)
+	algorithmSelect.SetSelected(string(services.HashSHA256))
+
+	formatSelect := widget.NewSelect([]string{
+		checksumFormatLabels[services.ManifestText],
+		checksumFormatLabels[services.ManifestJSON],
+	}, nil)
+	formatSelect.SetSelected(checksumFormatLabels[services.ManifestJSON])
+
+	summary := widget.NewLabelWithStyle(fmt.Sprintf("%d files selected", len(paths)), fyne.TextAlignLeading, fyne.TextStyle{Bold: true})
+	progressBar := widget.NewProgressBar()
+	progressBar.Hide()
+	statusLabel := widget.NewLabel("")
+
+	table := widget.NewTable(
+		func() (int, int) {
+			mu.Lock()
+			defer mu.Unlock()
+			return len(results) + 1, len(checksumColumns)
+		},
+		func() fyne.CanvasObject {
+			label := widget.NewLabel("")
+			label.Truncation = fyne.TextTruncateEllipsis
+			return label
+		},
+		func(id widget.TableCellID, obj fyne.CanvasObject) {
+			label := obj.(*widget.Label)
+			label.TextStyle = fyne.TextStyle{Bold: id.Row == 0}
+			label.Importance = widget.MediumImportance
+			if id.Row == 0 {
+				label.SetText(checksumColumns[id.Col].title)
+				return
+			}
+
+			mu.Lock()
+			result := results[id.Row-1]
+			mu.Unlock()
+			switch result.Status {
+			case services.ChecksumCorrupted, services.ChecksumFailed:
+				label.Importance = widget.DangerImportance
+			case services.ChecksumModified, services.ChecksumUnlisted:
+				label.Importance = widget.WarningImportance
+			}
+			label.SetText(checksumCell(result, id.Col))
+		},
+	)
+	for i, column := range checksumColumns {
+		table.SetColumnWidth(i, column.width)
+	}
+
+	var generateButton, verifyButton, cancelButton *widget.Button
+
+	run := func(verb string, operation func(ctx context.Context, progress services.ProgressCallback) ([]*services.ChecksumResult, error)) {
+		ctx, cancelFunc := context.WithCancel(context.Background())
+		cancel = cancelFunc
+		generateButton.Disable()
+		verifyButton.Disable()
+		cancelButton.Enable()
+		progressBar.SetValue(0)
+		progressBar.Show()
+		statusLabel.SetText(verb + "...")
+
+		go func() {
+			found, err := operation(ctx, func(progress float64, message string) {
+				progressBar.SetValue(progress)
+				statusLabel.SetText(message)
+			})
+			cancelFunc()
+
+			mu.Lock()
+			results = found
+			mu.Unlock()
+			table.Refresh()
+
+			generateButton.Enable()
+			verifyButton.Enable()
+			cancelButton.Disable()
+			progressBar.Hide()
+
+			counts := make(map[services.ChecksumStatus]int)
+			for _, result := range found {
+				counts[result.Status]++
+			}
+			summary.SetText(checksumSummary(counts, len(found)))
+
+			switch {
+			case ctx.Err() != nil && err != nil:
+				statusLabel.SetText(fmt.Sprintf("%s cancelled", verb))
+			case err != nil:
+				logger.Errorf("Checksum operation failed: %v", err)
+				statusLabel.SetText(fmt.Sprintf("Error: %v", err))
+				dialog.ShowError(err, window)
+			default:
+				statusLabel.SetText(fmt.Sprintf("%s complete", verb))
+			}
+
+			if counts[services.ChecksumCorrupted] > 0 {
+				dialog.ShowInformation("Bit Rot Detected",
+					fmt.Sprintf("%d file(s) changed although their modification time did not. Their data is damaged: restore them from a backup and check the disk.", counts[services.ChecksumCorrupted]),
+					window,
+				)
+			}
+		}()
+	}
+
+	generateButton = widget.NewButtonWithIcon("Generate Manifest", theme.DocumentCreateIcon(), func() {
+		algorithm := services.HashAlgorithm(algorithmSelect.Selected)
+		format := services.ManifestText
+		if formatSelect.Selected == checksumFormatLabels[services.ManifestJSON] {
+			format = services.ManifestJSON
+		}
+		run("Hashing", func(ctx context.Context, progress services.ProgressCallback) ([]*services.ChecksumResult, error) {
+			return checksumService.GenerateManifests(ctx, paths, algorithm, format, progress)
+		})
+	})
+
+	verifyButton = widget.NewButtonWithIcon("Verify Manifest", theme.ConfirmIcon(), func() {
+		run("Verifying", func(ctx context.Context, progress services.ProgressCallback) ([]*services.ChecksumResult, error) {
+			return checksumService.VerifyManifests(ctx, paths, progress)
+		})
+	})
+	verifyButton.Importance = widget.HighImportance
+
+	cancelButton = widget.NewButtonWithIcon("Cancel", theme.CancelIcon(), func() {
+		if cancel != nil {
+			cancel()
+		}
+	})
+	cancelButton.Disable()
+
+	options := container.NewVBox(
+		summary,
+		container.NewBorder(nil, nil, widget.NewLabel("Algorithm:"), nil, algorithmSelect),
+		container.NewBorder(nil, nil, widget.NewLabel("Format:"), nil, formatSelect),
+		container.NewHBox(generateButton, verifyButton, cancelButton),
+		progressBar,
+		statusLabel,
+	)
+
+	checksumsDialog := dialog.NewCustom("Checksum Manifests", "Close", container.NewBorder(options, nil, nil, nil, table), window)
+	checksumsDialog.SetOnClosed(func() {
+		if cancel != nil {
+			cancel()
+		}
+	})
+	checksumsDialog.Resize(fyne.NewSize(1000, 600))
+	checksumsDialog.Show()
+}
+
+// checksumCell returns the text of a column for a file
+func checksumCell(result *services.ChecksumResult, column int) string {
+	switch column {
+	case 0:
+		return filepath.Base(result.Path)
+	case 1:
+		return string(result.Status)
+	case 2:
+		return string(result.Algorithm)
+	case 3:
+		return result.ManifestPath
+	default:
+		details := result.Hash
+		if result.Expected != "" && result.Hash != "" && result.Expected != result.Hash {
+			details = fmt.Sprintf("expected %s, got %s", result.Expected, result.Hash)
+		}
+		if result.Error != "" && details != "" {
+			details = result.Error + ": " + details
+		} else if result.Error != "" {
+			details = result.Error
+		}
+		return details
+	}
+}
+
+// checksumSummary describes the number of files of each status
+func checksumSummary(counts map[services.ChecksumStatus]int, total int) string {
+	text := fmt.Sprintf("%d files", total)
+	for _, status := range []services.ChecksumStatus{
+		services.ChecksumAdded,
+		services.ChecksumOK,
+		services.ChecksumModified,
+		services.ChecksumCorrupted,
+		services.ChecksumUnlisted,
+		services.ChecksumFailed,
+	} {
+		if counts[status] > 0 {
+			text += fmt.Sprintf(", %d %s", counts[status], status)
+		}
+	}
+	return text
+}
@@ -0,0 +1,289 @@
+package components
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+	"github.com/Developpeur-du-dimanche/MediaTools/internal/quality"
+	"github.com/Developpeur-du-dimanche/MediaTools/pkg/medias"
+)
+
+// compareRow describes a property row of the comparison view
+type compareRow struct {
+	title string
+	value func(file *medias.FfprobeResult) string
+	// highlight marks the rows whose differences are highlighted
+	highlight bool
+}
+
+// compareSection groups the rows of a part of the files
+type compareSection struct {
+	title string
+	rows  []compareRow
+}
+
+// compareSections lists the properties shown in the comparison view
+var compareSections = []compareSection{
+	{
+		title: "Format",
+		rows: []compareRow{
+			{title: "Container", value: columnValue("container")},
+			{title: "Duration", value: columnValue("duration")},
+			{title: "Size", value: columnValue("size")},
+			{title: "Bitrate", value: columnValue("bitrate"), highlight: true},
+			{title: "Chapters", value: func(file *medias.FfprobeResult) string { return strconv.Itoa(len(file.Chapters)) }},
+		},
+	},
+	{
+		title: "Video",
+		rows: []compareRow{
+			{title: "Codec", value: videoValue(func(v medias.Video) string { return v.CodecName }), highlight: true},
+			{title: "Resolution", value: columnValue("resolution"), highlight: true},
+			{title: "Frame Rate", value: videoValue(func(v medias.Video) string { return v.FrameRate })},
+			{title: "Pixel Format", value: videoValue(func(v medias.Video) string { return v.PixFmt })},
+			{
+				title: "HDR",
+				value: func(file *medias.FfprobeResult) string {
+					if quality.IsHDR(file) {
+						return file.Videos[0].ColorTransfer
+					}
+					return "No"
+				},
+				highlight: true,
+			},
+			{title: "Bitrate", value: videoValue(func(v medias.Video) string { return formatBitrateString(v.Bitrate) }), highlight: true},
+		},
+	},
+	{
+		title: "Audio",
+		rows: []compareRow{
+			{title: "Tracks", value: func(file *medias.FfprobeResult) string { return strconv.Itoa(len(file.Audios)) }},
+			{title: "Codecs", value: columnValue("audio_codecs"), highlight: true},
+			{title: "Languages", value: columnValue("audio_languages"), highlight: true},
+			{
+				title: "Channels",
+				value: func(file *medias.FfprobeResult) string {
+					channels := make([]string, 0, len(file.Audios))
+					for _, audio := range file.Audios {
+						channels = append(channels, strconv.Itoa(audio.Channels))
+					}
+					return strings.Join(channels, ", ")
+				},
+			},
+		},
+	},
+	{
+		title: "Subtitles",
+		rows: []compareRow{
+			{title: "Tracks", value: func(file *medias.FfprobeResult) string { return strconv.Itoa(len(file.Subtitles)) }, highlight: true},
+			{title: "Languages", value: columnValue("subtitle_languages"), highlight: true},
+			{
+				title: "Codecs",
+				value: func(file *medias.FfprobeResult) string {
+					codecs := make([]string, 0, len(file.Subtitles))
+					for _, subtitle := range file.Subtitles {
+						codecs = append(codecs, subtitle.CodecName)
+					}
+					return joinUnique(codecs)
+				},
+				highlight: true,
+			},
+		},
+	},
+}
+
+// columnValue reuses the value of a media table column
+func columnValue(id string) func(file *medias.FfprobeResult) string {
+	column, _ := findMediaColumn(id)
+	return column.value
+}
+
+// videoValue returns a property of the first video stream
+func videoValue(value func(v medias.Video) string) func(file *medias.FfprobeResult) string {
+	return func(file *medias.FfprobeResult) string {
+		if len(file.Videos) == 0 {
+			return ""
+		}
+		return value(file.Videos[0])
+	}
+}
+
+// CompareMediaComponent shows the properties of several files side by side
+// and recommends which one to keep
+type CompareMediaComponent struct {
+	widget.BaseWidget
+
+	window        fyne.Window
+	selectedFiles []*medias.FfprobeResult
+	profileStore  *quality.Store
+	profiles      *quality.ProfileSet
+
+	grid             *fyne.Container
+	recommendLabel   *widget.Label
+	profilesButton   *widget.Button
+	highlightedCount int
+
+	onProfileChanged func()
+}
+
+// NewCompareMediaComponent creates a new component comparing the given files.
+// onProfileChanged is called when the user changes the active quality profile.
+func NewCompareMediaComponent(window fyne.Window, files []*medias.FfprobeResult, profileStore *quality.Store, profiles *quality.ProfileSet, onProfileChanged func()) *CompareMediaComponent {
+	cmc := &CompareMediaComponent{
+		window:           window,
+		selectedFiles:    files,
+		profileStore:     profileStore,
+		profiles:         profiles,
+		onProfileChanged: onProfileChanged,
+	}
+
+	cmc.initUI()
+	cmc.ExtendBaseWidget(cmc)
+	return cmc
+}
+
+func (cmc *CompareMediaComponent) initUI() {
+	cmc.grid = container.NewGridWithColumns(len(cmc.selectedFiles) + 1)
+
+	cmc.recommendLabel = widget.NewLabel("")
+	cmc.recommendLabel.Wrapping = fyne.TextWrapWord
+
+	cmc.profilesButton = widget.NewButtonWithIcon("Quality Profiles", theme.SettingsIcon(), func() {
+		ShowQualityProfilesDialog(cmc.window, cmc.profileStore, cmc.profiles, func() {
+			cmc.updateComparison()
+			if cmc.onProfileChanged != nil {
+				cmc.onProfileChanged()
+			}
+		})
+	})
+
+	cmc.updateComparison()
+}
+
+func (cmc *CompareMediaComponent) CreateRenderer() fyne.WidgetRenderer {
+	header := widget.NewLabelWithStyle(
+		fmt.Sprintf("Compare %d Files", len(cmc.selectedFiles)),
+		fyne.TextAlignCenter,
+		fyne.TextStyle{Bold: true},
+	)
+
+	content := container.NewBorder(
+		container.NewVBox(
+			header,
+			widget.NewSeparator(),
+		),
+		container.NewVBox(
+			widget.NewSeparator(),
+			container.NewBorder(nil, nil, nil, cmc.profilesButton, cmc.recommendLabel),
+		),
+		nil,
+		nil,
+		container.NewScroll(cmc.grid),
+	)
+
+	return widget.NewSimpleRenderer(content)
+}
+
+// updateComparison rebuilds the comparison grid with the active quality profile
+func (cmc *CompareMediaComponent) updateComparison() {
+	profile := quality.Active()
+	best, scores := quality.Recommend(cmc.selectedFiles, profile)
+
+	objects := []fyne.CanvasObject{widget.NewLabel("")}
+	for i, file := range cmc.selectedFiles {
+		name := widget.NewLabelWithStyle(filepath.Base(file.Format.Filename), fyne.TextAlignLeading, fyne.TextStyle{Bold: true})
+		name.Truncation = fyne.TextTruncateEllipsis
+		if i == best {
+			name.Importance = widget.SuccessImportance
+		}
+		objects = append(objects, name)
+	}
+
+	cmc.highlightedCount = 0
+	for _, section := range compareSections {
+		objects = append(objects, widget.NewLabelWithStyle(section.title, fyne.TextAlignLeading, fyne.TextStyle{Bold: true}))
+		for range cmc.selectedFiles {
+			objects = append(objects, widget.NewLabel(""))
+		}
+
+		for _, row := range section.rows {
+			objects = append(objects, cmc.rowObjects(row)...)
+		}
+	}
+
+	// Quality criteria of the active profile
+	objects = append(objects, widget.NewLabelWithStyle(fmt.Sprintf("Quality (%s)", profile.Name), fyne.TextAlignLeading, fyne.TextStyle{Bold: true}))
+	for range cmc.selectedFiles {
+		objects = append(objects, widget.NewLabel(""))
+	}
+	criteria := make([][]quality.Criterion, len(cmc.selectedFiles))
+	for i, file := range cmc.selectedFiles {
+		criteria[i] = quality.Criteria(file, profile)
+	}
+	for c, criterion := range criteria[0] {
+		objects = append(objects, widget.NewLabel(fmt.Sprintf("%s (x%g)", criterion.Name, criterion.Weight)))
+		for i := range cmc.selectedFiles {
+			objects = append(objects, widget.NewLabel(fmt.Sprintf("%.0f%%", criteria[i][c].Score*100)))
+		}
+	}
+
+	objects = append(objects, widget.NewLabelWithStyle("Quality Score", fyne.TextAlignLeading, fyne.TextStyle{Bold: true}))
+	for i, score := range scores {
+		label := widget.NewLabel(fmt.Sprintf("%.1f / 100", score))
+		if i == best {
+			label.Importance = widget.SuccessImportance
+			label.TextStyle = fyne.TextStyle{Bold: true}
+		}
+		objects = append(objects, label)
+	}
+
+	cmc.grid.Objects = objects
+	cmc.grid.Refresh()
+
+	if best >= 0 {
+		cmc.recommendLabel.SetText(fmt.Sprintf(
+			"Recommended: keep %s (score %.1f). %d highlighted differences.",
+			filepath.Base(cmc.selectedFiles[best].Format.Filename), scores[best], cmc.highlightedCount,
+		))
+	}
+}
+
+// rowObjects returns the labels of a property row, highlighting the values
+// when they differ between files
+func (cmc *CompareMediaComponent) rowObjects(row compareRow) []fyne.CanvasObject {
+	values := make([]string, len(cmc.selectedFiles))
+	differs := false
+	for i, file := range cmc.selectedFiles {
+		values[i] = row.value(file)
+		if values[i] != values[0] {
+			differs = true
+		}
+	}
+
+	highlight := row.highlight && differs
+	if highlight {
+		cmc.highlightedCount++
+	}
+
+	objects := make([]fyne.CanvasObject, 0, len(values)+1)
+	objects = append(objects, widget.NewLabel(row.title))
+	for _, value := range values {
+		if value == "" {
+			value = "-"
+		}
+		label := widget.NewLabel(value)
+		label.Truncation = fyne.TextTruncateEllipsis
+		if highlight {
+			label.Importance = widget.WarningImportance
+			label.TextStyle = fyne.TextStyle{Bold: true}
+		}
+		objects = append(objects, label)
+	}
+	return objects
+}
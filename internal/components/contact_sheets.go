@@ -0,0 +1,227 @@
+package components
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strconv"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+	"github.com/Developpeur-du-dimanche/MediaTools/internal/services"
+	"github.com/Developpeur-du-dimanche/MediaTools/pkg/logger"
+	"github.com/Developpeur-du-dimanche/MediaTools/pkg/medias"
+)
+
+// ContactSheetsComponent provides UI for exporting contact sheets
+type ContactSheetsComponent struct {
+	widget.BaseWidget
+
+	window           fyne.Window
+	thumbnailService *services.ThumbnailService
+	selectedFiles    []*medias.FfprobeResult
+
+	// UI elements
+	columnsEntry   *widget.Entry
+	rowsEntry      *widget.Entry
+	widthEntry     *widget.Entry
+	outputDirEntry *widget.Entry
+	outputDirRow   *fyne.Container
+	progressBar    *widget.ProgressBar
+	statusLabel    *widget.Label
+	exportButton   *widget.Button
+	filesList      *widget.List
+}
+
+// NewContactSheetsComponent creates a new component for exporting contact sheets
+func NewContactSheetsComponent(window fyne.Window, files []*medias.FfprobeResult, thumbnailService *services.ThumbnailService) *ContactSheetsComponent {
+	csc := &ContactSheetsComponent{
+		window:           window,
+		thumbnailService: thumbnailService,
+		selectedFiles:    files,
+	}
+
+	csc.initUI()
+	csc.ExtendBaseWidget(csc)
+	return csc
+}
+
+func (csc *ContactSheetsComponent) initUI() {
+	defaults := services.DefaultContactSheetOptions()
+
+	csc.columnsEntry = widget.NewEntry()
+	csc.columnsEntry.SetText(strconv.Itoa(defaults.Columns))
+	csc.rowsEntry = widget.NewEntry()
+	csc.rowsEntry.SetText(strconv.Itoa(defaults.Rows))
+	csc.widthEntry = widget.NewEntry()
+	csc.widthEntry.SetText(strconv.Itoa(defaults.TileWidth))
+
+	// Output directory
+	csc.outputDirEntry = widget.NewEntry()
+	csc.outputDirEntry.SetPlaceHolder("Output directory")
+	csc.outputDirEntry.Text = "./contact_sheets"
+
+	browseDirButton := widget.NewButtonWithIcon("", theme.FolderOpenIcon(), func() {
+		dialog.ShowFolderOpen(func(dir fyne.ListableURI, err error) {
+			if err != nil || dir == nil {
+				return
+			}
+			csc.outputDirEntry.SetText(dir.Path())
+		}, csc.window)
+	})
+
+	csc.outputDirRow = container.NewBorder(nil, nil, nil, browseDirButton, csc.outputDirEntry)
+
+	// Files list
+	csc.filesList = widget.NewList(
+		func() int {
+			return len(csc.selectedFiles)
+		},
+		func() fyne.CanvasObject {
+			return widget.NewLabel("")
+		},
+		func(id widget.ListItemID, obj fyne.CanvasObject) {
+			label := obj.(*widget.Label)
+			file := csc.selectedFiles[id]
+			label.SetText(filepath.Base(file.Format.Filename))
+		},
+	)
+
+	// Progress bar
+	csc.progressBar = widget.NewProgressBar()
+	csc.progressBar.Hide()
+
+	// Status label
+	csc.statusLabel = widget.NewLabel("")
+	csc.statusLabel.Hide()
+
+	// Export button
+	csc.exportButton = widget.NewButtonWithIcon("Export Contact Sheets", theme.DocumentSaveIcon(), func() {
+		csc.startExport()
+	})
+	csc.exportButton.Importance = widget.HighImportance
+}
+
+func (csc *ContactSheetsComponent) CreateRenderer() fyne.WidgetRenderer {
+	header := widget.NewLabelWithStyle(
+		fmt.Sprintf("Contact Sheets - %d Files", len(csc.selectedFiles)),
+		fyne.TextAlignCenter,
+		fyne.TextStyle{Bold: true},
+	)
+
+	form := container.New(layout.NewFormLayout(),
+		widget.NewLabel("Columns:"), csc.columnsEntry,
+		widget.NewLabel("Rows:"), csc.rowsEntry,
+		widget.NewLabel("Frame width (px):"), csc.widthEntry,
+		widget.NewLabel("Output Directory:"), csc.outputDirRow,
+	)
+
+	content := container.NewBorder(
+		container.NewVBox(
+			header,
+			widget.NewSeparator(),
+			form,
+			widget.NewSeparator(),
+			widget.NewLabel("Files:"),
+		),
+		container.NewVBox(
+			widget.NewLabel(""),
+			csc.progressBar,
+			csc.statusLabel,
+			widget.NewLabel(""),
+			csc.exportButton,
+		),
+		nil,
+		nil,
+		csc.filesList,
+	)
+
+	return widget.NewSimpleRenderer(content)
+}
+
+func (csc *ContactSheetsComponent) getOptions() (services.ContactSheetOptions, error) {
+	columns, err := strconv.Atoi(csc.columnsEntry.Text)
+	if err != nil {
+		return services.ContactSheetOptions{}, fmt.Errorf("invalid columns: %s", csc.columnsEntry.Text)
+	}
+	rows, err := strconv.Atoi(csc.rowsEntry.Text)
+	if err != nil {
+		return services.ContactSheetOptions{}, fmt.Errorf("invalid rows: %s", csc.rowsEntry.Text)
+	}
+	width, err := strconv.Atoi(csc.widthEntry.Text)
+	if err != nil {
+		return services.ContactSheetOptions{}, fmt.Errorf("invalid frame width: %s", csc.widthEntry.Text)
+	}
+
+	return services.ContactSheetOptions{
+		Columns:   columns,
+		Rows:      rows,
+		TileWidth: width,
+	}, nil
+}
+
+func (csc *ContactSheetsComponent) startExport() {
+	outputDir := csc.outputDirEntry.Text
+	if outputDir == "" {
+		dialog.ShowError(fmt.Errorf("please specify an output directory"), csc.window)
+		return
+	}
+
+	options, err := csc.getOptions()
+	if err != nil {
+		dialog.ShowError(err, csc.window)
+		return
+	}
+
+	// Disable UI during export
+	csc.exportButton.Disable()
+	csc.progressBar.Show()
+	csc.progressBar.SetValue(0)
+	csc.statusLabel.SetText("Exporting contact sheets...")
+	csc.statusLabel.Show()
+
+	// Start export in background
+	go func() {
+		ctx := context.Background()
+		result, err := csc.thumbnailService.BatchExportContactSheets(ctx, csc.selectedFiles, outputDir, options, func(progress float64, message string) {
+			csc.progressBar.SetValue(progress)
+			csc.statusLabel.SetText(message)
+		})
+
+		// Re-enable UI
+		csc.exportButton.Enable()
+
+		if err != nil {
+			logger.Errorf("Contact sheet export failed: %v", err)
+			csc.statusLabel.SetText(fmt.Sprintf("Error: %v", err))
+			dialog.ShowError(err, csc.window)
+			return
+		}
+
+		_, done, _ := result.Counts()
+		csc.statusLabel.SetText(fmt.Sprintf("Exported %d/%d contact sheets to %s", done, len(result.Files), outputDir))
+		ShowBatchResultsDialog(csc.window, result, nil)
+	}()
+}
+
+// GetSettings returns the output settings saved in sessions
+func (csc *ContactSheetsComponent) GetSettings() map[string]string {
+	return map[string]string{
+		"output_dir": csc.outputDirEntry.Text,
+		"columns":    csc.columnsEntry.Text,
+		"rows":       csc.rowsEntry.Text,
+		"tile_width": csc.widthEntry.Text,
+	}
+}
+
+// ApplySettings restores output settings saved in a session
+func (csc *ContactSheetsComponent) ApplySettings(settings map[string]string) {
+	applyEntrySetting(csc.outputDirEntry, settings, "output_dir")
+	applyEntrySetting(csc.columnsEntry, settings, "columns")
+	applyEntrySetting(csc.rowsEntry, settings, "rows")
+	applyEntrySetting(csc.widthEntry, settings, "tile_width")
+}
@@ -1,29 +1,42 @@
 package components
 
 import (
+	"context"
 	"fmt"
 	"math"
 	"path/filepath"
 	"strconv"
+	"strings"
+	"time"
 
 	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
 	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
 	"fyne.io/fyne/v2/widget"
+	"github.com/Developpeur-du-dimanche/MediaTools/internal/services"
+	"github.com/Developpeur-du-dimanche/MediaTools/pkg/logger"
 	"github.com/Developpeur-du-dimanche/MediaTools/pkg/medias"
 )
 
 type FileInfoComponent struct {
 	widget.BaseWidget
-	file    *medias.FfprobeResult
-	appTabs []container.AppTabs
-	window  fyne.Window
+	file          *medias.FfprobeResult
+	appTabs       []container.AppTabs
+	window        fyne.Window
+	ffmpegService *services.FFmpegService
+
+	thumbnailService *services.ThumbnailService
 }
 
-func NewFileInfoComponent(file *medias.FfprobeResult, window fyne.Window) *FileInfoComponent {
+func NewFileInfoComponent(file *medias.FfprobeResult, window fyne.Window, ffmpegService *services.FFmpegService, thumbnailService *services.ThumbnailService) *FileInfoComponent {
 	fic := &FileInfoComponent{
-		file:    file,
-		appTabs: []container.AppTabs{},
-		window:  window,
+		file:             file,
+		appTabs:          []container.AppTabs{},
+		window:           window,
+		ffmpegService:    ffmpegService,
+		thumbnailService: thumbnailService,
 	}
 	fic.ExtendBaseWidget(fic)
 	return fic
@@ -66,9 +79,11 @@ func (fic *FileInfoComponent) CreateRenderer() fyne.WidgetRenderer {
 		tabs.Append(container.NewTabItem("Subtitle Streams", fic.createSubtitleTabs()))
 	}
 
+	tabs.Append(container.NewTabItem(fmt.Sprintf("Chapters (%d)", len(fic.file.Chapters)), fic.createChaptersTab()))
+
 	// Main layout
 	content := container.NewBorder(
-		fileInfoGrid,
+		container.NewBorder(nil, nil, nil, fic.createPoster(), fileInfoGrid),
 		nil,
 		nil,
 		nil,
@@ -80,6 +95,27 @@ func (fic *FileInfoComponent) CreateRenderer() fyne.WidgetRenderer {
 	return widget.NewSimpleRenderer(content)
 }
 
+// createPoster returns an image filled in the background with the poster frame
+func (fic *FileInfoComponent) createPoster() fyne.CanvasObject {
+	poster := canvas.NewImageFromResource(nil)
+	poster.FillMode = canvas.ImageFillContain
+	poster.SetMinSize(fyne.NewSize(240, 135))
+
+	if fic.thumbnailService != nil && len(fic.file.Videos) > 0 {
+		go func() {
+			path, err := fic.thumbnailService.PosterFrame(context.Background(), fic.file, services.DefaultPosterPercent)
+			if err != nil {
+				logger.Debugf("No poster for %s: %v", fic.file.Format.Filename, err)
+				return
+			}
+			poster.File = path
+			poster.Refresh()
+		}()
+	}
+
+	return poster
+}
+
 func (fic *FileInfoComponent) createInfoRow(label, value string) fyne.CanvasObject {
 	return container.NewHBox(
 		widget.NewLabelWithStyle(label, fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
@@ -160,10 +196,7 @@ func (fic *FileInfoComponent) createAudioTabs() *container.AppTabs {
 	audioAppTabs := container.NewAppTabs()
 
 	for _, stream := range fic.file.Audios {
-		language := stream.Language
-		if language == "" {
-			language = "Unknown"
-		}
+		language := displayLanguage(stream.Language)
 
 		streamInfo := container.NewVBox(
 			widget.NewLabelWithStyle(fmt.Sprintf("Audio Stream #%d", stream.StreamIndex), fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
@@ -173,6 +206,9 @@ func (fic *FileInfoComponent) createAudioTabs() *container.AppTabs {
 			fic.createInfoRow("Language:", language),
 			fic.createInfoRow("Bitrate", formatBitrateString(stream.Bitrate)),
 		)
+		if stream.Loudness != nil {
+			streamInfo.Add(fic.createInfoRow("Loudness:", formatLoudness(*stream.Loudness)))
+		}
 
 		audioAppTab := container.NewTabItem(fmt.Sprintf("Stream #%d", stream.StreamIndex), streamInfo)
 		audioAppTabs.Append(audioAppTab)
@@ -185,10 +221,7 @@ func (fic *FileInfoComponent) createSubtitleTabs() *container.AppTabs {
 	subtitleAppTabs := container.NewAppTabs()
 
 	for _, stream := range fic.file.Subtitles {
-		language := stream.Language
-		if language == "" {
-			language = "Unknown"
-		}
+		language := displayLanguage(stream.Language)
 
 		streamInfo := container.NewVBox(
 			widget.NewLabelWithStyle(fmt.Sprintf("Subtitle Stream #%d", stream.StreamIndex), fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
@@ -203,3 +236,87 @@ func (fic *FileInfoComponent) createSubtitleTabs() *container.AppTabs {
 
 	return subtitleAppTabs
 }
+
+func (fic *FileInfoComponent) createChaptersTab() fyne.CanvasObject {
+	chaptersList := widget.NewList(
+		func() int {
+			return len(fic.file.Chapters)
+		},
+		func() fyne.CanvasObject {
+			return widget.NewLabel("")
+		},
+		func(id widget.ListItemID, obj fyne.CanvasObject) {
+			chapter := fic.file.Chapters[id]
+			title := chapter.Title
+			if title == "" {
+				title = fmt.Sprintf("Chapter %d", id+1)
+			}
+			obj.(*widget.Label).SetText(fmt.Sprintf("%s - %s  %s",
+				formatChapterTime(chapter.StartTime),
+				formatChapterTime(chapter.EndTime),
+				title,
+			))
+		},
+	)
+
+	removeButton := widget.NewButtonWithIcon("Remove Chapters", theme.DeleteIcon(), func() {
+		fic.saveChapterOutput("nochapters", func(ctx context.Context, outputPath string) error {
+			return fic.ffmpegService.RemoveChapters(ctx, fic.file.Format.Filename, outputPath, nil)
+		})
+	})
+	if len(fic.file.Chapters) == 0 {
+		removeButton.Disable()
+	}
+
+	importButton := widget.NewButtonWithIcon("Import Chapters...", theme.FileIcon(), func() {
+		dialog.ShowFileOpen(func(reader fyne.URIReadCloser, err error) {
+			if err != nil || reader == nil {
+				return
+			}
+			chapterFile := reader.URI().Path()
+			reader.Close()
+
+			fic.saveChapterOutput("chapters", func(ctx context.Context, outputPath string) error {
+				return fic.ffmpegService.ImportChapters(ctx, fic.file.Format.Filename, chapterFile, outputPath, nil)
+			})
+		}, fic.window)
+	})
+
+	var content fyne.CanvasObject = chaptersList
+	if len(fic.file.Chapters) == 0 {
+		content = widget.NewLabelWithStyle("No chapters", fyne.TextAlignCenter, fyne.TextStyle{Italic: true})
+	}
+
+	return container.NewBorder(
+		nil,
+		container.NewHBox(removeButton, importButton),
+		nil,
+		nil,
+		content,
+	)
+}
+
+// saveChapterOutput runs a chapter operation into a new file next to the source
+func (fic *FileInfoComponent) saveChapterOutput(suffix string, run func(ctx context.Context, outputPath string) error) {
+	inputPath := fic.file.Format.Filename
+	ext := filepath.Ext(inputPath)
+	outputPath := fmt.Sprintf("%s_%s%s", strings.TrimSuffix(inputPath, ext), suffix, ext)
+
+	go func() {
+		if err := run(context.Background(), outputPath); err != nil {
+			logger.Errorf("Chapter operation failed: %v", err)
+			dialog.ShowError(err, fic.window)
+			return
+		}
+		dialog.ShowInformation("Success", fmt.Sprintf("Output: %s", outputPath), fic.window)
+	}()
+}
+
+func formatChapterTime(d time.Duration) string {
+	d = d.Round(time.Millisecond)
+	hours := int(d / time.Hour)
+	minutes := int(d/time.Minute) % 60
+	seconds := int(d/time.Second) % 60
+	millis := int(d/time.Millisecond) % 1000
+	return fmt.Sprintf("%02d:%02d:%02d.%03d", hours, minutes, seconds, millis)
+}
@@ -10,6 +10,7 @@ import (
 	"fyne.io/fyne/v2/theme"
 	"fyne.io/fyne/v2/widget"
 	"github.com/Developpeur-du-dimanche/MediaTools/internal/filters"
+	"github.com/Developpeur-du-dimanche/MediaTools/internal/services"
 )
 
 // FilterConditionRow represents a single filter condition with dropdowns
@@ -433,3 +434,33 @@ func (fb *FilterBar) updateBadge() {
 func (fb *FilterBar) GetFilterText() string {
 	return fb.buildFilterString()
 }
+
+// SetConditions replaces the conditions with the ones of a parsed filter expression
+func (fb *FilterBar) SetConditions(expr *services.FilterExpression) {
+	fb.conditions = make([]*FilterConditionRow, 0)
+
+	allFilters := getFilterFieldConfigs()
+	for i, condition := range expr.Conditions {
+		fb.addCondition()
+		row := fb.conditions[len(fb.conditions)-1]
+
+		for _, config := range allFilters {
+			if config.GetFieldConfig().Key == string(condition.Field) {
+				row.fieldSelect.SetSelected(config.GetFieldConfig().DisplayName)
+				break
+			}
+		}
+		row.operatorSelect.SetSelected(string(condition.Operator))
+		if row.valueSelect.Visible() {
+			row.valueSelect.SetSelected(condition.Value)
+		} else {
+			row.valueEntry.SetText(condition.Value)
+		}
+		if i > 0 {
+			row.logicalOp.SetSelected(string(expr.Operators[i-1]))
+		}
+	}
+
+	fb.activeFilters = len(expr.Conditions)
+	fb.updateBadge()
+}
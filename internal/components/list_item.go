@@ -1,22 +1,33 @@
 package components
 
 import (
+	"context"
+	"sync"
+
 	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
 	"fyne.io/fyne/v2/container"
 	"fyne.io/fyne/v2/theme"
 	"fyne.io/fyne/v2/widget"
 	"github.com/Developpeur-du-dimanche/MediaTools/pkg/medias"
 )
 
+// ListItem is a cell of the media table. The first column shows the row
+// actions (selection, remove, info and thumbnail), the others a text value.
 type ListItem struct {
 	widget.BaseWidget
 
 	checkBox     *widget.Check
-	filename     *widget.Label
 	removeButton *widget.Button
 	infoButton   *widget.Button
+	thumbnail    *canvas.Image
+	actions      *fyne.Container
+	value        *widget.Label
 
 	ffprobeData *medias.FfprobeResult
+
+	thumbnailMutex  sync.Mutex
+	cancelThumbnail context.CancelFunc
 }
 
 func NewListItem() *ListItem {
@@ -28,20 +39,94 @@ func NewListItem() *ListItem {
 
 func (li *ListItem) initUI() {
 	li.checkBox = widget.NewCheck("", nil)
-	li.filename = widget.NewLabel("filename")
 	li.removeButton = widget.NewButtonWithIcon("", theme.DeleteIcon(), nil)
 	li.infoButton = widget.NewButtonWithIcon("", theme.InfoIcon(), nil)
+	li.thumbnail = canvas.NewImageFromResource(nil)
+	li.thumbnail.FillMode = canvas.ImageFillContain
+	li.thumbnail.SetMinSize(fyne.NewSize(64, 36))
+	li.actions = container.NewHBox(
+		li.checkBox,
+		li.removeButton,
+		li.infoButton,
+		li.thumbnail,
+	)
+
+	li.value = widget.NewLabel("")
+	li.value.Truncation = fyne.TextTruncateEllipsis
+	li.value.Hide()
 }
 
 func (mi *ListItem) CreateRenderer() fyne.WidgetRenderer {
-	return widget.NewSimpleRenderer(container.NewHBox(
-		mi.checkBox,
-		mi.removeButton,
-		mi.infoButton,
-		mi.filename,
+	return widget.NewSimpleRenderer(container.NewStack(
+		mi.actions,
+		mi.value,
 	))
 }
 
+// ShowActions displays the row actions
+func (li *ListItem) ShowActions() {
+	li.value.Hide()
+	li.actions.Show()
+}
+
+// ShowValue displays a column value
+func (li *ListItem) ShowValue(text string) {
+	li.actions.Hide()
+	li.value.SetText(text)
+	li.value.Show()
+}
+
+// SetFfprobeData binds the cell to a file. Binding another file stops loading
+// the thumbnail of the previous one.
 func (li *ListItem) SetFfprobeData(data *medias.FfprobeResult) {
+	if li.ffprobeData != data {
+		li.stopThumbnail()
+	}
 	li.ffprobeData = data
 }
+
+// startThumbnail returns the context of the thumbnail loading of the current file
+func (li *ListItem) startThumbnail() context.Context {
+	li.thumbnailMutex.Lock()
+	defer li.thumbnailMutex.Unlock()
+
+	if li.cancelThumbnail != nil {
+		li.cancelThumbnail()
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	li.cancelThumbnail = cancel
+	return ctx
+}
+
+// stopThumbnail cancels the thumbnail loading in progress
+func (li *ListItem) stopThumbnail() {
+	li.thumbnailMutex.Lock()
+	defer li.thumbnailMutex.Unlock()
+
+	if li.cancelThumbnail != nil {
+		li.cancelThumbnail()
+		li.cancelThumbnail = nil
+	}
+}
+
+// showLoadedThumbnail displays a loaded thumbnail unless the cell was bound to another file since
+func (li *ListItem) showLoadedThumbnail(ctx context.Context, path string) {
+	li.thumbnailMutex.Lock()
+	defer li.thumbnailMutex.Unlock()
+
+	if ctx.Err() != nil {
+		return
+	}
+	li.SetThumbnail(path)
+}
+
+// SetThumbnail displays the image at path, or clears the thumbnail if path is empty
+func (li *ListItem) SetThumbnail(path string) {
+	if path == "" {
+		li.thumbnail.File = ""
+		li.thumbnail.Resource = nil
+	} else {
+		li.thumbnail.File = path
+	}
+	li.thumbnail.Refresh()
+}
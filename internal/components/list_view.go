@@ -1,200 +1,463 @@
 package components
 
 import (
-	"sync"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
 
 	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
 	"fyne.io/fyne/v2/dialog"
 	"fyne.io/fyne/v2/widget"
 	"github.com/Developpeur-du-dimanche/MediaTools/internal/services"
+	"github.com/Developpeur-du-dimanche/MediaTools/pkg/logger"
 	"github.com/Developpeur-du-dimanche/MediaTools/pkg/medias"
 )
 
+const (
+	// PreferenceKeyTableColumns is the key used to store the visible media table columns
+	PreferenceKeyTableColumns = "table_columns"
+	// PreferenceKeyTableColumnWidths is the key used to store the media table column widths, as "id=width"
+	PreferenceKeyTableColumnWidths = "table_column_widths"
+
+	actionsColumnWidth = 200
+	minColumnWidth     = 50
+
+	// refreshInterval is the delay used to batch table refreshes while items are added
+	refreshInterval = 100 * time.Millisecond
+)
+
+// ListView is the media table. Its items live in a MediaStore; additions are
+// shown by batched refreshes so that scanning large libraries stays responsive.
 type ListView struct {
 	widget.BaseWidget
 
-	list          *widget.List
-	mutex         *sync.Mutex
+	table         *widget.Table
 	window        fyne.Window
 	ffmpegService *services.FFmpegService
 
-	items       []*medias.FfprobeResult
-	OnUpdate    chan bool
-	OnRefresh   func()
-	isSelected  map[int]bool
-	maxSize     int
-	currentSize int
+	thumbnailService *services.ThumbnailService
+
+	columns       []mediaColumn
+	columnWidths  map[string]float32
+	sortColumn    string
+	sortAscending bool
+
+	store          *MediaStore
+	refreshPending atomic.Bool
+
+	OnUpdate  chan bool
+	OnRefresh func()
 }
 
 func NewListView(onRefresh func(), window fyne.Window, ffmpegService *services.FFmpegService) *ListView {
-	mutex := &sync.Mutex{}
 	lv := &ListView{
 		OnUpdate:      make(chan bool),
-		items:         make([]*medias.FfprobeResult, 100),
-		mutex:         mutex,
+		store:         NewMediaStore(),
 		OnRefresh:     onRefresh,
-		list:          widget.NewList(nil, nil, nil),
+		table:         widget.NewTable(nil, nil, nil),
 		window:        window,
 		ffmpegService: ffmpegService,
-		isSelected:    make(map[int]bool),
-		maxSize:       100,
-		currentSize:   0,
 	}
 
+	lv.loadColumnSettings()
 	lv.ExtendBaseWidget(lv)
 	return lv
 }
 
+// SetThumbnailService enables poster frames in the list rows
+func (lv *ListView) SetThumbnailService(thumbnailService *services.ThumbnailService) {
+	lv.thumbnailService = thumbnailService
+}
+
 func (lv *ListView) CreateRenderer() fyne.WidgetRenderer {
-	lv.list.Length = lv.length
-	lv.list.CreateItem = lv.CreateItem
-	lv.list.UpdateItem = lv.updateItem
-	lv.list.OnSelected = lv.onSelected
-	return widget.NewSimpleRenderer(lv.list)
+	lv.table.Length = lv.length
+	lv.table.CreateCell = lv.CreateItem
+	lv.table.UpdateCell = lv.updateItem
+	lv.table.OnSelected = lv.onSelected
+	lv.table.ShowHeaderRow = true
+	lv.table.StickyColumnCount = 1
+	lv.table.CreateHeader = func() fyne.CanvasObject {
+		return newTableHeader()
+	}
+	lv.table.UpdateHeader = lv.updateHeader
+	lv.applyColumnWidths()
+	return widget.NewSimpleRenderer(lv.table)
 }
 
 func (lv *ListView) CreateItem() fyne.CanvasObject {
 	return NewListItem()
 }
 
-func (lv *ListView) onSelected(id int) {
-	lv.list.Unselect(id)
+func (lv *ListView) onSelected(id widget.TableCellID) {
+	lv.table.Unselect(id)
 }
 
-func (lv *ListView) length() int {
-	return lv.currentSize
+func (lv *ListView) length() (int, int) {
+	return lv.store.VisibleLen(), len(lv.columns) + 1
 }
 
-func (lv *ListView) updateItem(i widget.ListItemID, o fyne.CanvasObject) {
+func (lv *ListView) updateItem(id widget.TableCellID, o fyne.CanvasObject) {
 	root := o.(*ListItem)
-	item := lv.items[i]
+	item, ok := lv.store.VisibleAt(id.Row)
+	if !ok {
+		return
+	}
 
-	if item == nil {
+	if id.Col > 0 {
+		root.SetFfprobeData(nil)
+		root.ShowValue(lv.columns[id.Col-1].value(item))
 		return
 	}
 
-	root.checkBox.Checked = lv.isSelected[int(i)]
-	root.checkBox.Refresh()
+	root.ShowActions()
+
+	root.checkBox.OnChanged = nil
+	root.checkBox.SetChecked(lv.store.IsSelected(item))
 	root.checkBox.OnChanged = func(checked bool) {
-		lv.isSelected[int(i)] = checked
+		lv.store.SetSelected(item, checked)
 	}
 
 	root.removeButton.OnTapped = func() {
-		lv.RemoveItemAt(int(i))
-		lv.Refresh()
+		lv.RemoveItem(item)
 	}
 
 	root.infoButton.OnTapped = func() {
-		fic := NewFileInfoComponent(item, lv.window)
+		fic := NewFileInfoComponent(item, lv.window, lv.ffmpegService, lv.thumbnailService)
 		dialog := dialog.NewCustom("Media Info", "Close", fic, lv.window)
 		dialog.Show()
 	}
 
-	root.filename.SetText(item.Format.Filename)
+	if root.ffprobeData != item {
+		root.SetFfprobeData(item)
+		root.SetThumbnail("")
+		lv.loadThumbnail(root, item)
+	}
 }
 
-func (lv *ListView) RemoveItemAt(index int) {
-	lv.mutex.Lock()
-	defer lv.mutex.Unlock()
-	if lv.currentSize == 0 || index >= lv.currentSize {
+func (lv *ListView) updateHeader(id widget.TableCellID, o fyne.CanvasObject) {
+	header := o.(*tableHeader)
+
+	if id.Col <= 0 || id.Col > len(lv.columns) {
+		header.SetHeader("", 0)
+		header.OnTapped = nil
+		header.OnDragged = nil
+		header.OnDragEnd = nil
 		return
 	}
 
-	// Shift all items after the removed index
-	for i := index; i < lv.currentSize-1; i++ {
-		lv.items[i] = lv.items[i+1]
+	column := lv.columns[id.Col-1]
+	direction := 0
+	if column.id == lv.sortColumn {
+		direction = -1
+		if lv.sortAscending {
+			direction = 1
+		}
 	}
-	lv.items[lv.currentSize-1] = nil
-	lv.currentSize--
+	header.SetHeader(column.title, direction)
 
-	// Update selection map
-	newSelected := make(map[int]bool)
-	for i, selected := range lv.isSelected {
-		if i < index {
-			newSelected[i] = selected
-		} else if i > index {
-			newSelected[i-1] = selected
+	header.OnTapped = func() {
+		lv.SortBy(column.id)
+	}
+	header.OnDragged = func(dx float32) {
+		lv.resizeColumn(id.Col, column.id, dx)
+	}
+	header.OnDragEnd = lv.saveColumnSettings
+}
+
+// loadThumbnail generates the poster frame in the background and shows it
+// if the row still displays the same item. Rebinding the row cancels it.
+func (lv *ListView) loadThumbnail(root *ListItem, item *medias.FfprobeResult) {
+	if lv.thumbnailService == nil || len(item.Videos) == 0 {
+		return
+	}
+
+	ctx := root.startThumbnail()
+	go func() {
+		path, err := lv.thumbnailService.PosterFrame(ctx, item, services.DefaultPosterPercent)
+		if err != nil {
+			if ctx.Err() == nil {
+				logger.Debugf("No thumbnail for %s: %v", item.Format.Filename, err)
+			}
+			return
 		}
+		root.showLoadedThumbnail(ctx, path)
+	}()
+}
+
+// SetFilter shows only the items for which match returns true. A nil match shows every item.
+func (lv *ListView) SetFilter(match func(item *medias.FfprobeResult) bool) {
+	lv.store.SetMatcher(match)
+	lv.table.Refresh()
+}
+
+// VisibleCount returns the number of items matching the filter
+func (lv *ListView) VisibleCount() int {
+	return lv.store.VisibleLen()
+}
+
+// SortBy sorts the rows by a column. Sorting again by the same column
+// reverses the order.
+func (lv *ListView) SortBy(columnID string) {
+	column, ok := findMediaColumn(columnID)
+	if !ok {
+		return
+	}
+
+	if lv.sortColumn == columnID {
+		lv.sortAscending = !lv.sortAscending
+	} else {
+		lv.sortColumn = columnID
+		lv.sortAscending = true
 	}
-	lv.isSelected = newSelected
 
-	lv.list.Refresh()
+	lv.applySort(column)
 }
 
-func (lv *ListView) AddItem(item *medias.FfprobeResult) {
-	if item == nil {
+// Resort sorts the rows again by the current column, after the values it
+// depends on changed
+func (lv *ListView) Resort() {
+	column, ok := findMediaColumn(lv.sortColumn)
+	if !ok {
+		lv.table.Refresh()
 		return
 	}
-	lv.mutex.Lock()
-	defer lv.mutex.Unlock()
-	if lv.currentSize >= lv.maxSize-10 {
-		newList := make([]*medias.FfprobeResult, lv.maxSize*2)
-		copy(newList, lv.items)
-		lv.items = newList
-		lv.maxSize *= 2
+	lv.applySort(column)
+}
+
+func (lv *ListView) applySort(column mediaColumn) {
+	ascending := lv.sortAscending
+	lv.store.SetSort(func(a, b *medias.FfprobeResult) bool {
+		if ascending {
+			return column.less(a, b)
+		}
+		return column.less(b, a)
+	})
+	lv.table.Refresh()
+}
+
+// resizeColumn changes the width of a column while its header is dragged
+func (lv *ListView) resizeColumn(tableColumn int, columnID string, dx float32) {
+	width := lv.columnWidth(columnID) + dx
+	if width < minColumnWidth {
+		width = minColumnWidth
+	}
+	lv.columnWidths[columnID] = width
+	lv.table.SetColumnWidth(tableColumn, width)
+}
+
+func (lv *ListView) columnWidth(columnID string) float32 {
+	if width, ok := lv.columnWidths[columnID]; ok {
+		return width
 	}
-	lv.items[lv.currentSize] = item
-	lv.currentSize++
-	lv.list.Refresh()
+	column, _ := findMediaColumn(columnID)
+	return column.width
 }
 
-func (lv *ListView) Clear() {
-	lv.mutex.Lock()
-	defer lv.mutex.Unlock()
-	lv.items = make([]*medias.FfprobeResult, 100)
-	lv.currentSize = 0
-	lv.maxSize = 100
-	lv.isSelected = make(map[int]bool)
-	lv.list.Refresh()
-}
-
-func (lv *ListView) GetItems() []medias.FfprobeResult {
-	lv.mutex.Lock()
-	defer lv.mutex.Unlock()
-	items := make([]medias.FfprobeResult, lv.currentSize)
-	for i, item := range lv.items {
-		if item != nil {
-			items[i] = *item
+func (lv *ListView) applyColumnWidths() {
+	lv.table.SetColumnWidth(0, actionsColumnWidth)
+	for i, column := range lv.columns {
+		lv.table.SetColumnWidth(i+1, lv.columnWidth(column.id))
+	}
+}
+
+// ShowColumnsDialog lets the user choose the visible columns
+func (lv *ListView) ShowColumnsDialog() {
+	visible := make(map[string]bool)
+	for _, column := range lv.columns {
+		visible[column.id] = true
+	}
+
+	checks := container.NewVBox()
+	for _, column := range mediaColumns {
+		id := column.id
+		check := widget.NewCheck(column.title, func(checked bool) {
+			visible[id] = checked
+			lv.setVisibleColumns(visible)
+		})
+		check.Checked = visible[id]
+		checks.Add(check)
+	}
+
+	dialog.ShowCustom("Columns", "Close", checks, lv.window)
+}
+
+// setVisibleColumns shows the columns marked visible, keeping at least one
+func (lv *ListView) setVisibleColumns(visible map[string]bool) {
+	columns := make([]mediaColumn, 0, len(mediaColumns))
+	for _, column := range mediaColumns {
+		if visible[column.id] {
+			columns = append(columns, column)
 		}
 	}
-	return items
+	if len(columns) == 0 {
+		columns = append(columns, mediaColumns[0])
+	}
+
+	lv.columns = columns
+	lv.applyColumnWidths()
+	lv.table.Refresh()
+	lv.saveColumnSettings()
 }
 
-func (lv *ListView) GetSelectedItems() []*medias.FfprobeResult {
-	lv.mutex.Lock()
-	defer lv.mutex.Unlock()
+// loadColumnSettings restores the visible columns and their widths from the preferences
+func (lv *ListView) loadColumnSettings() {
+	prefs := fyne.CurrentApp().Preferences()
 
-	selected := make([]*medias.FfprobeResult, 0)
-	for i := 0; i < lv.currentSize; i++ {
-		if lv.isSelected[i] && lv.items[i] != nil {
-			selected = append(selected, lv.items[i])
+	defaultIDs := make([]string, 0, len(mediaColumns))
+	for _, column := range mediaColumns {
+		defaultIDs = append(defaultIDs, column.id)
+	}
+
+	visible := make(map[string]bool)
+	for _, id := range prefs.StringListWithFallback(PreferenceKeyTableColumns, defaultIDs) {
+		visible[id] = true
+	}
+	lv.columns = make([]mediaColumn, 0, len(mediaColumns))
+	for _, column := range mediaColumns {
+		if visible[column.id] {
+			lv.columns = append(lv.columns, column)
 		}
 	}
-	return selected
+	if len(lv.columns) == 0 {
+		lv.columns = append(lv.columns, mediaColumns...)
+	}
+
+	lv.columnWidths = make(map[string]float32)
+	for _, entry := range prefs.StringList(PreferenceKeyTableColumnWidths) {
+		id, value, found := strings.Cut(entry, "=")
+		if !found {
+			continue
+		}
+		width, err := strconv.ParseFloat(value, 32)
+		if err != nil || width < minColumnWidth {
+			continue
+		}
+		lv.columnWidths[id] = float32(width)
+	}
 }
 
-func (lv *ListView) SelectAll() {
-	lv.mutex.Lock()
-	defer lv.mutex.Unlock()
+// saveColumnSettings stores the visible columns and their widths in the preferences
+func (lv *ListView) saveColumnSettings() {
+	prefs := fyne.CurrentApp().Preferences()
 
-	for i := 0; i < lv.currentSize; i++ {
-		lv.isSelected[i] = true
+	ids := make([]string, 0, len(lv.columns))
+	for _, column := range lv.columns {
+		ids = append(ids, column.id)
 	}
-	lv.list.Refresh()
-	// Force update of all visible items
-	for i := 0; i < lv.currentSize; i++ {
-		lv.list.RefreshItem(i)
+	prefs.SetStringList(PreferenceKeyTableColumns, ids)
+
+	widths := make([]string, 0, len(lv.columnWidths))
+	for id, width := range lv.columnWidths {
+		widths = append(widths, fmt.Sprintf("%s=%.0f", id, width))
 	}
+	sort.Strings(widths)
+	prefs.SetStringList(PreferenceKeyTableColumnWidths, widths)
 }
 
-func (lv *ListView) UnselectAll() {
-	lv.mutex.Lock()
-	defer lv.mutex.Unlock()
+// RemoveItem removes an item from the table
+func (lv *ListView) RemoveItem(item *medias.FfprobeResult) {
+	lv.store.Remove(item)
+	lv.table.Refresh()
+}
 
-	lv.isSelected = make(map[int]bool)
-	lv.list.Refresh()
-	// Force update of all visible items
-	for i := 0; i < lv.currentSize; i++ {
-		lv.list.RefreshItem(i)
+// AddItem adds an item. It can be called from any goroutine; the table is
+// refreshed in batches.
+func (lv *ListView) AddItem(item *medias.FfprobeResult) {
+	if item == nil {
+		return
 	}
+	lv.store.Add(item)
+	lv.scheduleRefresh()
+}
+
+// AddItems adds several items at once
+func (lv *ListView) AddItems(items []*medias.FfprobeResult) {
+	lv.store.Add(items...)
+	lv.scheduleRefresh()
+}
+
+// scheduleRefresh refreshes the table after refreshInterval, coalescing the
+// requests made in the meantime
+func (lv *ListView) scheduleRefresh() {
+	if !lv.refreshPending.CompareAndSwap(false, true) {
+		return
+	}
+	time.AfterFunc(refreshInterval, func() {
+		lv.refreshPending.Store(false)
+		lv.table.Refresh()
+	})
+}
+
+func (lv *ListView) Clear() {
+	lv.store.Clear()
+	lv.table.Refresh()
+}
+
+// GetItems returns all the items, including the ones hidden by the filter
+func (lv *ListView) GetItems() []*medias.FfprobeResult {
+	return lv.store.Items()
+}
+
+// GetSelectedItems returns the selected items among the visible ones
+func (lv *ListView) GetSelectedItems() []*medias.FfprobeResult {
+	return lv.store.SelectedVisible()
+}
+
+// SelectAll selects the visible items
+func (lv *ListView) SelectAll() {
+	lv.store.SetVisibleSelected(true)
+	lv.table.Refresh()
+}
+
+// UnselectAll unselects the visible items
+func (lv *ListView) UnselectAll() {
+	lv.store.SetVisibleSelected(false)
+	lv.table.Refresh()
+}
+
+// SelectMatching replaces the selection with the visible items for which match
+// returns true and returns their number
+func (lv *ListView) SelectMatching(match func(item *medias.FfprobeResult) bool) int {
+	count := lv.store.SelectVisibleWhere(match, true)
+	lv.table.Refresh()
+	return count
+}
+
+// AddMatchingToSelection selects the visible items for which match returns true,
+// keeping the current selection, and returns their number
+func (lv *ListView) AddMatchingToSelection(match func(item *medias.FfprobeResult) bool) int {
+	count := lv.store.SelectVisibleWhere(match, false)
+	lv.table.Refresh()
+	return count
+}
+
+// SetSelectedItems replaces the selection with the given items, even those hidden by the filter
+func (lv *ListView) SetSelectedItems(items []*medias.FfprobeResult) {
+	lv.store.SetSelection(items)
+	lv.table.Refresh()
+}
+
+// InvertSelection inverts the selection of the visible items
+func (lv *ListView) InvertSelection() {
+	lv.store.InvertVisibleSelection()
+	lv.table.Refresh()
+}
+
+// RemoveNonMatching removes the items for which match returns false and returns
+// the number of removed items
+func (lv *ListView) RemoveNonMatching(match func(item *medias.FfprobeResult) bool) int {
+	removed := lv.store.RemoveWhere(func(item *medias.FfprobeResult) bool {
+		return !match(item)
+	})
+	lv.table.Refresh()
+	return removed
+}
+
+// IsSelected reports whether an item is selected, even if it is hidden by the filter
+func (lv *ListView) IsSelected(item *medias.FfprobeResult) bool {
+	return lv.store.IsSelected(item)
 }
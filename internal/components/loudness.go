@@ -0,0 +1,444 @@
+package components
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strconv"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+	"github.com/Developpeur-du-dimanche/MediaTools/internal/services"
+	"github.com/Developpeur-du-dimanche/MediaTools/pkg/logger"
+	"github.com/Developpeur-du-dimanche/MediaTools/pkg/medias"
+)
+
+// loudnessColumns are the columns of the audio streams table
+var loudnessColumns = []struct {
+	title string
+	width float32
+}{
+	{"File", 260},
+	{"Stream", 70},
+	{"Codec", 80},
+	{"Language", 90},
+	{"Loudness (LUFS)", 120},
+	{"True Peak (dBTP)", 120},
+	{"Range (LU)", 90},
+}
+
+// loudnessStream is an audio stream of the table
+type loudnessStream struct {
+	file  *medias.FfprobeResult
+	index int
+}
+
+// LoudnessComponent measures the loudness of the audio streams of files and
+// normalizes them to an EBU R128 target
+type LoudnessComponent struct {
+	widget.BaseWidget
+
+	window        fyne.Window
+	ffmpegService *services.FFmpegService
+	selectedFiles []*medias.FfprobeResult
+	streams       []loudnessStream
+
+	// UI elements
+	streamsTable    *widget.Table
+	integratedEntry *widget.Entry
+	truePeakEntry   *widget.Entry
+	lraEntry        *widget.Entry
+	outputDirEntry  *widget.Entry
+	outputDirRow    *fyne.Container
+	progressBar     *widget.ProgressBar
+	statusLabel     *widget.Label
+	analyzeButton   *widget.Button
+	previewButton   *widget.Button
+	normalizeButton *widget.Button
+	cancelButton    *widget.Button
+
+	cancel context.CancelFunc
+
+	// onAnalyzed is called once new measurements are stored in the files
+	onAnalyzed func()
+}
+
+// NewLoudnessComponent creates a new component for loudness normalization
+func NewLoudnessComponent(window fyne.Window, files []*medias.FfprobeResult, ffmpegService *services.FFmpegService, onAnalyzed func()) *LoudnessComponent {
+	lc := &LoudnessComponent{
+		window:        window,
+		ffmpegService: ffmpegService,
+		selectedFiles: files,
+		onAnalyzed:    onAnalyzed,
+	}
+	for _, file := range files {
+		for i := range file.Audios {
+			lc.streams = append(lc.streams, loudnessStream{file: file, index: i})
+		}
+	}
+
+	lc.initUI()
+	lc.ExtendBaseWidget(lc)
+	return lc
+}
+
+func (lc *LoudnessComponent) initUI() {
+	lc.streamsTable = widget.NewTable(
+		func() (int, int) {
+			return len(lc.streams) + 1, len(loudnessColumns)
+		},
+		func() fyne.CanvasObject {
+			label := widget.NewLabel("")
+			label.Truncation = fyne.TextTruncateEllipsis
+			return label
+		},
+		func(id widget.TableCellID, obj fyne.CanvasObject) {
+			label := obj.(*widget.Label)
+			label.TextStyle = fyne.TextStyle{Bold: id.Row == 0}
+			if id.Row == 0 {
+				label.SetText(loudnessColumns[id.Col].title)
+				return
+			}
+			label.SetText(lc.streamCell(lc.streams[id.Row-1], id.Col))
+		},
+	)
+	for i, column := range loudnessColumns {
+		lc.streamsTable.SetColumnWidth(i, column.width)
+	}
+
+	// Target, EBU R128 by default
+	target := services.DefaultLoudnessTarget()
+	lc.integratedEntry = widget.NewEntry()
+	lc.integratedEntry.SetText(strconv.FormatFloat(target.Integrated, 'f', -1, 64))
+	lc.truePeakEntry = widget.NewEntry()
+	lc.truePeakEntry.SetText(strconv.FormatFloat(target.TruePeak, 'f', -1, 64))
+	lc.lraEntry = widget.NewEntry()
+	lc.lraEntry.SetText(strconv.FormatFloat(target.LRA, 'f', -1, 64))
+
+	// Output directory
+	lc.outputDirEntry = widget.NewEntry()
+	lc.outputDirEntry.SetPlaceHolder("Output directory")
+	lc.outputDirEntry.Text = "./normalized"
+
+	browseDirButton := widget.NewButtonWithIcon("", theme.FolderOpenIcon(), func() {
+		dialog.ShowFolderOpen(func(dir fyne.ListableURI, err error) {
+			if err != nil || dir == nil {
+				return
+			}
+			lc.outputDirEntry.SetText(dir.Path())
+		}, lc.window)
+	})
+	lc.outputDirRow = container.NewBorder(nil, nil, nil, browseDirButton, lc.outputDirEntry)
+
+	lc.progressBar = widget.NewProgressBar()
+	lc.progressBar.Hide()
+	lc.statusLabel = widget.NewLabel("")
+	lc.statusLabel.Hide()
+
+	lc.analyzeButton = widget.NewButtonWithIcon("Analyze Loudness", theme.SearchIcon(), func() {
+		lc.analyze()
+	})
+
+	// Preview shows the ffmpeg commands without running them
+	lc.previewButton = widget.NewButtonWithIcon("Preview (Dry Run)", theme.VisibilityIcon(), func() {
+		lc.previewPlan()
+	})
+
+	lc.normalizeButton = widget.NewButtonWithIcon("Normalize", theme.MediaPlayIcon(), func() {
+		lc.startNormalizing()
+	})
+	lc.normalizeButton.Importance = widget.HighImportance
+
+	lc.cancelButton = widget.NewButtonWithIcon("Cancel", theme.CancelIcon(), func() {
+		if lc.cancel != nil {
+			lc.cancel()
+		}
+	})
+	lc.cancelButton.Disable()
+}
+
+func (lc *LoudnessComponent) CreateRenderer() fyne.WidgetRenderer {
+	header := widget.NewLabelWithStyle(
+		fmt.Sprintf("Audio Loudness - %d Files, %d Audio Streams", len(lc.selectedFiles), len(lc.streams)),
+		fyne.TextAlignCenter,
+		fyne.TextStyle{Bold: true},
+	)
+
+	form := container.NewVBox(
+		widget.NewLabel("Target (EBU R128 broadcast: -23 LUFS, -1 dBTP):"),
+		container.NewGridWithColumns(6,
+			widget.NewLabel("Loudness (LUFS):"), lc.integratedEntry,
+			widget.NewLabel("True Peak (dBTP):"), lc.truePeakEntry,
+			widget.NewLabel("Range (LU):"), lc.lraEntry,
+		),
+		widget.NewLabel("Output Directory:"),
+		lc.outputDirRow,
+	)
+
+	content := container.NewBorder(
+		container.NewVBox(
+			header,
+			widget.NewSeparator(),
+			form,
+			widget.NewSeparator(),
+		),
+		container.NewVBox(
+			lc.progressBar,
+			lc.statusLabel,
+			container.NewGridWithColumns(4, lc.analyzeButton, lc.previewButton, lc.normalizeButton, lc.cancelButton),
+		),
+		nil,
+		nil,
+		lc.streamsTable,
+	)
+
+	return widget.NewSimpleRenderer(content)
+}
+
+// streamCell returns the text of a column for an audio stream
+func (lc *LoudnessComponent) streamCell(stream loudnessStream, column int) string {
+	audio := stream.file.Audios[stream.index]
+	switch column {
+	case 0:
+		return filepath.Base(stream.file.Format.Filename)
+	case 1:
+		return fmt.Sprintf("#%d", audio.StreamIndex)
+	case 2:
+		return audio.CodecName
+	case 3:
+		return displayLanguage(audio.Language)
+	}
+
+	if audio.Loudness == nil {
+		return "-"
+	}
+	switch column {
+	case 4:
+		return fmt.Sprintf("%.1f", audio.Loudness.Integrated)
+	case 5:
+		return fmt.Sprintf("%.1f", audio.Loudness.TruePeak)
+	default:
+		return fmt.Sprintf("%.1f", audio.Loudness.LRA)
+	}
+}
+
+// analyze measures every audio stream of the files, even those measured before
+func (lc *LoudnessComponent) analyze() {
+	ctx := lc.setBusy(true, "Measuring loudness...")
+
+	go func() {
+		results, err := lc.ffmpegService.BatchAnalyzeLoudness(ctx, lc.selectedFiles, lc.onProgress)
+		lc.measured()
+
+		failed := 0
+		for _, result := range results {
+			if result.Err != nil {
+				failed++
+			}
+		}
+
+		switch {
+		case ctx.Err() != nil:
+			lc.setBusy(false, fmt.Sprintf("Analysis cancelled after %d/%d files", len(results), len(lc.selectedFiles)))
+		case err != nil:
+			lc.showError(err)
+		case failed > 0:
+			lc.setBusy(false, fmt.Sprintf("Analyzed %d/%d files, %d failed (see logs)", len(results)-failed, len(lc.selectedFiles), failed))
+		default:
+			lc.setBusy(false, fmt.Sprintf("Analyzed %d files", len(results)))
+		}
+	}()
+}
+
+// measured shows the measurements stored in the files
+func (lc *LoudnessComponent) measured() {
+	lc.streamsTable.Refresh()
+	if lc.onAnalyzed != nil {
+		lc.onAnalyzed()
+	}
+}
+
+func (lc *LoudnessComponent) startNormalizing() {
+	target, outputDir, ok := lc.validateSettings()
+	if !ok {
+		return
+	}
+
+	ctx := lc.setBusy(true, "Planning...")
+
+	go func() {
+		plan, err := lc.ffmpegService.PlanNormalizeLoudness(ctx, lc.selectedFiles, outputDir, target, lc.onProgress)
+		lc.measured()
+		if err != nil {
+			lc.showError(err)
+			return
+		}
+		lc.executePlan(ctx, plan)
+	}()
+}
+
+// previewPlan measures the streams not analyzed yet and shows the ffmpeg
+// commands of the second pass. Running the preview executes the very same plan.
+func (lc *LoudnessComponent) previewPlan() {
+	target, outputDir, ok := lc.validateSettings()
+	if !ok {
+		return
+	}
+
+	ctx := lc.setBusy(true, "Planning...")
+
+	go func() {
+		plan, err := lc.ffmpegService.PlanNormalizeLoudness(ctx, lc.selectedFiles, outputDir, target, lc.onProgress)
+		lc.measured()
+		if err != nil {
+			lc.showError(err)
+			return
+		}
+
+		lc.setBusy(false, "")
+		lc.statusLabel.Hide()
+		lc.progressBar.Hide()
+
+		ShowPlanDialog(lc.window, plan, lc.ffmpegService.GetFFmpegPath(), func() {
+			ctx := lc.setBusy(true, "Normalizing files...")
+			go lc.executePlan(ctx, plan)
+		})
+	}()
+}
+
+// executePlan runs a plan and reports the results
+func (lc *LoudnessComponent) executePlan(ctx context.Context, plan *services.Plan) {
+	lc.statusLabel.SetText("Normalizing files...")
+	result, err := lc.ffmpegService.ExecutePlan(ctx, plan, lc.onProgress)
+	lc.showResult(result, err)
+}
+
+// retryFailed runs the failed files of a batch again
+func (lc *LoudnessComponent) retryFailed(result *services.BatchResult) {
+	ctx := lc.setBusy(true, "Retrying failed files...")
+	go func() {
+		retried, err := lc.ffmpegService.RetryFailed(ctx, result, lc.onProgress)
+		lc.showResult(retried, err)
+	}()
+}
+
+func (lc *LoudnessComponent) onProgress(progress float64, message string) {
+	lc.progressBar.SetValue(progress)
+	lc.statusLabel.SetText(message)
+}
+
+// showResult shows the outcome of every file, with a retry of the failed ones
+func (lc *LoudnessComponent) showResult(result *services.BatchResult, err error) {
+	if err != nil && result == nil {
+		lc.showError(err)
+		return
+	}
+
+	outputs := result.OutputPaths()
+	status := fmt.Sprintf("Successfully normalized %d/%d files", len(outputs), len(result.Files))
+	if err != nil {
+		status = fmt.Sprintf("Normalization stopped after %d/%d files", len(outputs), len(result.Files))
+	}
+	lc.setBusy(false, status)
+	ShowBatchResultsDialog(lc.window, result, func() {
+		lc.retryFailed(result)
+	})
+}
+
+// validateSettings reads the target and the output directory
+func (lc *LoudnessComponent) validateSettings() (services.LoudnessTarget, string, bool) {
+	var target services.LoudnessTarget
+	for _, field := range []struct {
+		entry *widget.Entry
+		name  string
+		value *float64
+	}{
+		{lc.integratedEntry, "target loudness", &target.Integrated},
+		{lc.truePeakEntry, "target true peak", &target.TruePeak},
+		{lc.lraEntry, "target loudness range", &target.LRA},
+	} {
+		value, err := strconv.ParseFloat(field.entry.Text, 64)
+		if err != nil {
+			dialog.ShowError(fmt.Errorf("invalid %s: %s", field.name, field.entry.Text), lc.window)
+			return target, "", false
+		}
+		*field.value = value
+	}
+
+	outputDir := lc.outputDirEntry.Text
+	if outputDir == "" {
+		dialog.ShowError(fmt.Errorf("please specify an output directory"), lc.window)
+		return target, "", false
+	}
+	return target, outputDir, true
+}
+
+func (lc *LoudnessComponent) showError(err error) {
+	logger.Errorf("Loudness normalization failed: %v", err)
+	lc.setBusy(false, fmt.Sprintf("Error: %v", err))
+	dialog.ShowError(err, lc.window)
+}
+
+// setBusy disables the UI during processing and shows the status. When busy,
+// it returns the context the Cancel button cancels.
+func (lc *LoudnessComponent) setBusy(busy bool, status string) context.Context {
+	widgets := []fyne.Disableable{
+		lc.analyzeButton,
+		lc.previewButton,
+		lc.normalizeButton,
+		lc.integratedEntry,
+		lc.truePeakEntry,
+		lc.lraEntry,
+		lc.outputDirEntry,
+	}
+	for _, w := range widgets {
+		if busy {
+			w.Disable()
+		} else {
+			w.Enable()
+		}
+	}
+
+	var ctx context.Context
+	if busy {
+		ctx, lc.cancel = context.WithCancel(context.Background())
+		lc.cancelButton.Enable()
+		lc.progressBar.Show()
+		lc.progressBar.SetValue(0)
+	} else {
+		if lc.cancel != nil {
+			lc.cancel()
+		}
+		lc.cancelButton.Disable()
+		lc.progressBar.Hide()
+	}
+	lc.statusLabel.SetText(status)
+	lc.statusLabel.Show()
+	return ctx
+}
+
+// formatLoudness describes the loudness of a stream
+func formatLoudness(loudness medias.Loudness) string {
+	return fmt.Sprintf("%.1f LUFS, true peak %.1f dBTP, range %.1f LU", loudness.Integrated, loudness.TruePeak, loudness.LRA)
+}
+
+// GetSettings returns the output settings saved in sessions
+func (lc *LoudnessComponent) GetSettings() map[string]string {
+	return map[string]string{
+		"output_dir": lc.outputDirEntry.Text,
+		"integrated": lc.integratedEntry.Text,
+		"true_peak":  lc.truePeakEntry.Text,
+		"lra":        lc.lraEntry.Text,
+	}
+}
+
+// ApplySettings restores output settings saved in a session
+func (lc *LoudnessComponent) ApplySettings(settings map[string]string) {
+	applyEntrySetting(lc.outputDirEntry, settings, "output_dir")
+	applyEntrySetting(lc.integratedEntry, settings, "integrated")
+	applyEntrySetting(lc.truePeakEntry, settings, "true_peak")
+	applyEntrySetting(lc.lraEntry, settings, "lra")
+}
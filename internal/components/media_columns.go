@@ -0,0 +1,241 @@
+package components
+
+import (
+	"fmt"
+	"math"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/Developpeur-du-dimanche/MediaTools/internal/quality"
+	"github.com/Developpeur-du-dimanche/MediaTools/internal/services"
+	"github.com/Developpeur-du-dimanche/MediaTools/pkg/languages"
+	"github.com/Developpeur-du-dimanche/MediaTools/pkg/medias"
+)
+
+// mediaColumn describes a column of the media table
+type mediaColumn struct {
+	id    string
+	title string
+	width float32
+	value func(item *medias.FfprobeResult) string
+	// sortKey is used to sort numeric columns; columns without one are sorted by value
+	sortKey func(item *medias.FfprobeResult) int64
+}
+
+// columnCheckHistory is the history read by the last check column
+var columnCheckHistory atomic.Pointer[services.CheckHistory]
+
+// SetColumnCheckHistory sets the check history shown by the last check column
+func SetColumnCheckHistory(history *services.CheckHistory) {
+	columnCheckHistory.Store(history)
+}
+
+// mediaColumns lists every available column, in display order
+var mediaColumns = []mediaColumn{
+	{
+		id:    "name",
+		title: "Name",
+		width: 320,
+		value: func(item *medias.FfprobeResult) string { return filepath.Base(item.Format.Filename) },
+	},
+	{
+		id:    "container",
+		title: "Container",
+		width: 90,
+		value: func(item *medias.FfprobeResult) string {
+			return strings.ToUpper(strings.TrimPrefix(filepath.Ext(item.Format.Filename), "."))
+		},
+	},
+	{
+		id:    "duration",
+		title: "Duration",
+		width: 100,
+		value: func(item *medias.FfprobeResult) string {
+			return item.Format.DurationSeconds.Round(time.Second).String()
+		},
+		sortKey: func(item *medias.FfprobeResult) int64 { return int64(item.Format.DurationSeconds) },
+	},
+	{
+		id:      "size",
+		title:   "Size",
+		width:   90,
+		value:   func(item *medias.FfprobeResult) string { return formatSizeString(item.Format.Size) },
+		sortKey: func(item *medias.FfprobeResult) int64 { return parseInt64(item.Format.Size) },
+	},
+	{
+		id:    "resolution",
+		title: "Resolution",
+		width: 110,
+		value: func(item *medias.FfprobeResult) string {
+			if len(item.Videos) == 0 {
+				return ""
+			}
+			return fmt.Sprintf("%dx%d", item.Videos[0].Width, item.Videos[0].Height)
+		},
+		sortKey: func(item *medias.FfprobeResult) int64 {
+			if len(item.Videos) == 0 {
+				return 0
+			}
+			return int64(item.Videos[0].Width) * int64(item.Videos[0].Height)
+		},
+	},
+	{
+		id:    "video_codec",
+		title: "Video Codec",
+		width: 110,
+		value: func(item *medias.FfprobeResult) string {
+			if len(item.Videos) == 0 {
+				return ""
+			}
+			return item.Videos[0].CodecName
+		},
+	},
+	{
+		id:    "audio_codecs",
+		title: "Audio Codecs",
+		width: 130,
+		value: func(item *medias.FfprobeResult) string {
+			codecs := make([]string, 0, len(item.Audios))
+			for _, audio := range item.Audios {
+				codecs = append(codecs, audio.CodecName)
+			}
+			return joinUnique(codecs)
+		},
+	},
+	{
+		id:    "audio_languages",
+		title: "Audio Languages",
+		width: 140,
+		value: func(item *medias.FfprobeResult) string {
+			names := make([]string, 0, len(item.Audios))
+			for _, audio := range item.Audios {
+				names = append(names, languages.DisplayName(audio.Language))
+			}
+			return joinUnique(names)
+		},
+	},
+	{
+		id:    "subtitle_languages",
+		title: "Subtitle Languages",
+		width: 150,
+		value: func(item *medias.FfprobeResult) string {
+			names := make([]string, 0, len(item.Subtitles))
+			for _, subtitle := range item.Subtitles {
+				names = append(names, languages.DisplayName(subtitle.Language))
+			}
+			return joinUnique(names)
+		},
+	},
+	{
+		id:      "bitrate",
+		title:   "Bitrate",
+		width:   110,
+		value:   func(item *medias.FfprobeResult) string { return formatBitrateString(item.Format.Bitrate) },
+		sortKey: func(item *medias.FfprobeResult) int64 { return parseInt64(item.Format.Bitrate) },
+	},
+	{
+		id:    "quality_score",
+		title: "Quality Score",
+		width: 110,
+		value: func(item *medias.FfprobeResult) string {
+			return fmt.Sprintf("%.1f", quality.Score(item, quality.Active()))
+		},
+		sortKey: func(item *medias.FfprobeResult) int64 { return int64(quality.Score(item, quality.Active()) * 10) },
+	},
+	{
+		id:    "loudness",
+		title: "Loudness",
+		width: 120,
+		value: func(item *medias.FfprobeResult) string {
+			values := make([]string, 0, len(item.Audios))
+			for _, audio := range item.Audios {
+				if audio.Loudness != nil {
+					values = append(values, fmt.Sprintf("%.1f", audio.Loudness.Integrated))
+				}
+			}
+			if len(values) == 0 {
+				return ""
+			}
+			return strings.Join(values, ", ") + " LUFS"
+		},
+		sortKey: func(item *medias.FfprobeResult) int64 {
+			// Files not analyzed come first
+			for _, audio := range item.Audios {
+				if audio.Loudness != nil {
+					return int64(audio.Loudness.Integrated * 10)
+				}
+			}
+			return math.MinInt64
+		},
+	},
+	{
+		id:    "last_check",
+		title: "Last Check",
+		width: 160,
+		// Sorting by value groups the corrupted files, then orders them by date
+		value: func(item *medias.FfprobeResult) string {
+			checks := columnCheckHistory.Load().Lookup(item.Format.Filename)
+			if checks == nil {
+				return ""
+			}
+			last := checks.Last()
+			status := "OK"
+			if !last.IsValid {
+				status = "Corrupted"
+			}
+			return fmt.Sprintf("%s, %s", status, last.CheckedAt.Format("2006-01-02 15:04"))
+		},
+	},
+}
+
+// findMediaColumn returns the column with the given id
+func findMediaColumn(id string) (mediaColumn, bool) {
+	for _, column := range mediaColumns {
+		if column.id == id {
+			return column, true
+		}
+	}
+	return mediaColumn{}, false
+}
+
+// less compares two items on this column
+func (c mediaColumn) less(a, b *medias.FfprobeResult) bool {
+	if c.sortKey != nil {
+		return c.sortKey(a) < c.sortKey(b)
+	}
+	return strings.ToLower(c.value(a)) < strings.ToLower(c.value(b))
+}
+
+// joinUnique joins the non-empty values, without duplicates, keeping the first occurrence order
+func joinUnique(values []string) string {
+	seen := make(map[string]bool)
+	unique := make([]string, 0, len(values))
+	for _, value := range values {
+		if value == "" || seen[value] {
+			continue
+		}
+		seen[value] = true
+		unique = append(unique, value)
+	}
+	return strings.Join(unique, ", ")
+}
+
+// displayLanguage returns the name of a language tag followed by its canonical code
+func displayLanguage(tag string) string {
+	if tag == "" {
+		return "Unknown"
+	}
+	language, ok := languages.Lookup(tag)
+	if !ok {
+		return tag
+	}
+	return fmt.Sprintf("%s (%s)", language.Name, language.Bibliographic)
+}
+
+func parseInt64(value string) int64 {
+	n, _ := strconv.ParseInt(value, 10, 64)
+	return n
+}
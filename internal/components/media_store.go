@@ -0,0 +1,314 @@
+package components
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/Developpeur-du-dimanche/MediaTools/pkg/medias"
+)
+
+// MediaStore holds the scanned items of the media table. It is safe for
+// concurrent use: scanning goroutines add items while the UI reads them.
+//
+// Added items are buffered and merged on the next read so that adding many
+// items is cheap. Selection is keyed by item, so it survives removals and sorting.
+type MediaStore struct {
+	mutex sync.Mutex
+
+	items    []*medias.FfprobeResult
+	pending  []*medias.FfprobeResult
+	selected map[*medias.FfprobeResult]bool
+
+	// visible holds the items matching the matcher, in display order
+	visible []*medias.FfprobeResult
+	matcher func(item *medias.FfprobeResult) bool
+	less    func(a, b *medias.FfprobeResult) bool
+}
+
+// NewMediaStore creates an empty store
+func NewMediaStore() *MediaStore {
+	return &MediaStore{
+		items:    make([]*medias.FfprobeResult, 0),
+		selected: make(map[*medias.FfprobeResult]bool),
+		visible:  make([]*medias.FfprobeResult, 0),
+	}
+}
+
+// Add appends items to the store
+func (ms *MediaStore) Add(items ...*medias.FfprobeResult) {
+	ms.mutex.Lock()
+	defer ms.mutex.Unlock()
+
+	for _, item := range items {
+		if item != nil {
+			ms.pending = append(ms.pending, item)
+		}
+	}
+}
+
+// Remove removes an item and its selection
+func (ms *MediaStore) Remove(item *medias.FfprobeResult) {
+	ms.mutex.Lock()
+	defer ms.mutex.Unlock()
+	ms.flush()
+
+	ms.items = removeItem(ms.items, item)
+	ms.visible = removeItem(ms.visible, item)
+	delete(ms.selected, item)
+}
+
+// Clear removes every item
+func (ms *MediaStore) Clear() {
+	ms.mutex.Lock()
+	defer ms.mutex.Unlock()
+
+	ms.items = make([]*medias.FfprobeResult, 0)
+	ms.pending = nil
+	ms.selected = make(map[*medias.FfprobeResult]bool)
+	ms.visible = make([]*medias.FfprobeResult, 0)
+}
+
+// Items returns a snapshot of all the items, in display order
+func (ms *MediaStore) Items() []*medias.FfprobeResult {
+	ms.mutex.Lock()
+	defer ms.mutex.Unlock()
+	ms.flush()
+
+	items := make([]*medias.FfprobeResult, len(ms.items))
+	copy(items, ms.items)
+	return items
+}
+
+// Len returns the number of items
+func (ms *MediaStore) Len() int {
+	ms.mutex.Lock()
+	defer ms.mutex.Unlock()
+	return len(ms.items) + len(ms.pending)
+}
+
+// VisibleLen returns the number of items matching the matcher
+func (ms *MediaStore) VisibleLen() int {
+	ms.mutex.Lock()
+	defer ms.mutex.Unlock()
+	ms.flush()
+	return len(ms.visible)
+}
+
+// VisibleAt returns the visible item of a row
+func (ms *MediaStore) VisibleAt(row int) (*medias.FfprobeResult, bool) {
+	ms.mutex.Lock()
+	defer ms.mutex.Unlock()
+	ms.flush()
+
+	if row < 0 || row >= len(ms.visible) {
+		return nil, false
+	}
+	return ms.visible[row], true
+}
+
+// SetMatcher shows only the items for which match returns true. A nil match shows every item.
+func (ms *MediaStore) SetMatcher(match func(item *medias.FfprobeResult) bool) {
+	ms.mutex.Lock()
+	defer ms.mutex.Unlock()
+	ms.flush()
+
+	ms.matcher = match
+	ms.updateVisible()
+}
+
+// SetSort sorts the items with less, and keeps them sorted as items are added
+func (ms *MediaStore) SetSort(less func(a, b *medias.FfprobeResult) bool) {
+	ms.mutex.Lock()
+	defer ms.mutex.Unlock()
+	ms.flush()
+
+	ms.less = less
+	if less != nil {
+		sort.SliceStable(ms.items, func(i, j int) bool { return less(ms.items[i], ms.items[j]) })
+	}
+	ms.updateVisible()
+}
+
+// IsSelected reports whether an item is selected
+func (ms *MediaStore) IsSelected(item *medias.FfprobeResult) bool {
+	ms.mutex.Lock()
+	defer ms.mutex.Unlock()
+	return ms.selected[item]
+}
+
+// SetSelected selects or unselects an item
+func (ms *MediaStore) SetSelected(item *medias.FfprobeResult, selected bool) {
+	ms.mutex.Lock()
+	defer ms.mutex.Unlock()
+
+	if selected {
+		ms.selected[item] = true
+	} else {
+		delete(ms.selected, item)
+	}
+}
+
+// SetVisibleSelected selects or unselects every visible item
+func (ms *MediaStore) SetVisibleSelected(selected bool) {
+	ms.mutex.Lock()
+	defer ms.mutex.Unlock()
+	ms.flush()
+
+	for _, item := range ms.visible {
+		if selected {
+			ms.selected[item] = true
+		} else {
+			delete(ms.selected, item)
+		}
+	}
+}
+
+// SelectVisibleWhere selects the visible items for which match returns true.
+// When replace is set, the other items are unselected. It returns the number
+// of matching items.
+func (ms *MediaStore) SelectVisibleWhere(match func(item *medias.FfprobeResult) bool, replace bool) int {
+	ms.mutex.Lock()
+	defer ms.mutex.Unlock()
+	ms.flush()
+
+	if replace {
+		ms.selected = make(map[*medias.FfprobeResult]bool)
+	}
+
+	count := 0
+	for _, item := range ms.visible {
+		if match(item) {
+			ms.selected[item] = true
+			count++
+		}
+	}
+	return count
+}
+
+// SetSelection replaces the selection with the given items, visible or not
+func (ms *MediaStore) SetSelection(items []*medias.FfprobeResult) {
+	ms.mutex.Lock()
+	defer ms.mutex.Unlock()
+	ms.flush()
+
+	ms.selected = make(map[*medias.FfprobeResult]bool, len(items))
+	for _, item := range items {
+		ms.selected[item] = true
+	}
+}
+
+// InvertVisibleSelection selects the unselected visible items and unselects the selected ones
+func (ms *MediaStore) InvertVisibleSelection() {
+	ms.mutex.Lock()
+	defer ms.mutex.Unlock()
+	ms.flush()
+
+	for _, item := range ms.visible {
+		if ms.selected[item] {
+			delete(ms.selected, item)
+		} else {
+			ms.selected[item] = true
+		}
+	}
+}
+
+// RemoveWhere removes the items for which match returns true and returns how many were removed
+func (ms *MediaStore) RemoveWhere(match func(item *medias.FfprobeResult) bool) int {
+	ms.mutex.Lock()
+	defer ms.mutex.Unlock()
+	ms.flush()
+
+	kept := make([]*medias.FfprobeResult, 0, len(ms.items))
+	for _, item := range ms.items {
+		if match(item) {
+			delete(ms.selected, item)
+		} else {
+			kept = append(kept, item)
+		}
+	}
+
+	removed := len(ms.items) - len(kept)
+	ms.items = kept
+	ms.updateVisible()
+	return removed
+}
+
+// SelectedVisible returns the selected items among the visible ones, in display order
+func (ms *MediaStore) SelectedVisible() []*medias.FfprobeResult {
+	ms.mutex.Lock()
+	defer ms.mutex.Unlock()
+	ms.flush()
+
+	selected := make([]*medias.FfprobeResult, 0)
+	for _, item := range ms.visible {
+		if ms.selected[item] {
+			selected = append(selected, item)
+		}
+	}
+	return selected
+}
+
+// flush merges the pending items. The mutex must be held.
+func (ms *MediaStore) flush() {
+	if len(ms.pending) == 0 {
+		return
+	}
+	pending := ms.pending
+	ms.pending = nil
+
+	if ms.less == nil {
+		ms.items = append(ms.items, pending...)
+		for _, item := range pending {
+			if ms.matches(item) {
+				ms.visible = append(ms.visible, item)
+			}
+		}
+		return
+	}
+
+	sort.SliceStable(pending, func(i, j int) bool { return ms.less(pending[i], pending[j]) })
+	ms.items = mergeSorted(ms.items, pending, ms.less)
+	ms.updateVisible()
+}
+
+// updateVisible rebuilds the visible items. The mutex must be held.
+func (ms *MediaStore) updateVisible() {
+	visible := make([]*medias.FfprobeResult, 0, len(ms.items))
+	for _, item := range ms.items {
+		if ms.matches(item) {
+			visible = append(visible, item)
+		}
+	}
+	ms.visible = visible
+}
+
+func (ms *MediaStore) matches(item *medias.FfprobeResult) bool {
+	return ms.matcher == nil || ms.matcher(item)
+}
+
+// mergeSorted merges two sorted slices. Items of a come first on ties.
+func mergeSorted(a, b []*medias.FfprobeResult, less func(a, b *medias.FfprobeResult) bool) []*medias.FfprobeResult {
+	merged := make([]*medias.FfprobeResult, 0, len(a)+len(b))
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		if less(b[j], a[i]) {
+			merged = append(merged, b[j])
+			j++
+		} else {
+			merged = append(merged, a[i])
+			i++
+		}
+	}
+	merged = append(merged, a[i:]...)
+	return append(merged, b[j:]...)
+}
+
+// removeItem removes the first occurrence of item, keeping the order
+func removeItem(items []*medias.FfprobeResult, item *medias.FfprobeResult) []*medias.FfprobeResult {
+	for i, v := range items {
+		if v == item {
+			return append(items[:i], items[i+1:]...)
+		}
+	}
+	return items
+}
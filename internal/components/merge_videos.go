@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"path/filepath"
+	"strconv"
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/container"
@@ -26,11 +27,18 @@ type MergeVideosComponent struct {
 
 	filesList     *widget.List
 	mergeButton   *widget.Button
+	previewButton *widget.Button
 	outputEntry   *widget.Entry
 	outputRow     *fyne.Container
 	progressBar   *widget.ProgressBar
 	statusLabel   *widget.Label
 	refreshButton *widget.Button
+	reportLabel   *widget.Label
+	modeRadio     *widget.RadioGroup
+	chaptersCheck *widget.Check
+	chapterEntry  *widget.Entry
+
+	report *services.MergeCompatibilityReport
 
 	refreshList func() []*medias.FfprobeResult
 	onComplete  func(outputPath string)
@@ -117,14 +125,83 @@ func (mvc *MergeVideosComponent) initUI() {
 	})
 	mvc.mergeButton.Importance = widget.HighImportance
 
+	// Preview button shows the ffmpeg command without running it
+	mvc.previewButton = widget.NewButtonWithIcon("Preview (Dry Run)", theme.VisibilityIcon(), func() {
+		mvc.planMerge(true)
+	})
+
 	mvc.refreshButton = widget.NewButtonWithIcon("", theme.ViewRefreshIcon(), func() {
 		mvc.selectedFiles = mvc.refreshList()
 		mvc.filesList.Refresh()
+		mvc.updateCompatibility()
+	})
+
+	// Compatibility report and merge mode choice
+	mvc.reportLabel = widget.NewLabel("")
+	mvc.reportLabel.Wrapping = fyne.TextWrapWord
+
+	mvc.modeRadio = widget.NewRadioGroup([]string{
+		mergeModeCopyLabel,
+		mergeModeReencodeLabel,
+	}, func(string) {
+		mvc.updateMergeButton()
+	})
+	mvc.modeRadio.Hide()
+
+	// Chapter generation
+	mvc.chapterEntry = widget.NewEntry()
+	mvc.chapterEntry.SetPlaceHolder("Chapter title template (e.g., Part {index} - {name})")
+	mvc.chapterEntry.Text = services.DefaultChapterTitleTemplate
+	mvc.chapterEntry.Disable()
+
+	mvc.chaptersCheck = widget.NewCheck("Add one chapter per file", func(checked bool) {
+		if checked {
+			mvc.chapterEntry.Enable()
+		} else {
+			mvc.chapterEntry.Disable()
+		}
 	})
 
-	if len(mvc.selectedFiles) < 2 {
+	mvc.updateCompatibility()
+}
+
+const (
+	mergeModeCopyLabel     = "Stream copy anyway (fast, output may be broken)"
+	mergeModeReencodeLabel = "Re-encode to a common format (slow, safe)"
+)
+
+// updateCompatibility validates the current inputs and refreshes the report
+func (mvc *MergeVideosComponent) updateCompatibility() {
+	mvc.report = services.ValidateMergeInputs(mvc.selectedFiles)
+	mvc.reportLabel.SetText(mvc.report.String())
+
+	if mvc.report.IsCompatible() || !mvc.report.CanMerge() {
+		mvc.modeRadio.Hide()
+	} else {
+		mvc.modeRadio.Show()
+	}
+
+	mvc.updateMergeButton()
+}
+
+// updateMergeButton enables the merge button once the inputs can be merged
+// and, if they are incompatible, a merge mode has been chosen
+func (mvc *MergeVideosComponent) updateMergeButton() {
+	if len(mvc.selectedFiles) < 2 || !mvc.report.CanMerge() || (!mvc.report.IsCompatible() && mvc.modeRadio.Selected == "") {
 		mvc.mergeButton.Disable()
+		mvc.previewButton.Disable()
+		return
+	}
+	mvc.mergeButton.Enable()
+	mvc.previewButton.Enable()
+}
+
+// mergeMode returns the merge mode chosen by the user
+func (mvc *MergeVideosComponent) mergeMode() services.MergeMode {
+	if !mvc.report.IsCompatible() && mvc.modeRadio.Selected == mergeModeReencodeLabel {
+		return services.MergeModeReencode
 	}
+	return services.MergeModeCopy
 }
 
 func (mvc *MergeVideosComponent) CreateRenderer() fyne.WidgetRenderer {
@@ -145,13 +222,18 @@ func (mvc *MergeVideosComponent) CreateRenderer() fyne.WidgetRenderer {
 			widget.NewLabel("Files to merge:"),
 		),
 		container.NewVBox(
+			widget.NewLabel("Compatibility:"),
+			mvc.reportLabel,
+			mvc.modeRadio,
+			mvc.chaptersCheck,
+			mvc.chapterEntry,
 			widget.NewLabel("Output file:"),
 			mvc.outputRow,
 			widget.NewLabel(""),
 			mvc.progressBar,
 			mvc.statusLabel,
 			widget.NewLabel(""),
-			mvc.mergeButton,
+			container.NewGridWithColumns(2, mvc.previewButton, mvc.mergeButton),
 		),
 		nil,
 		nil,
@@ -167,6 +249,7 @@ func (mvc *MergeVideosComponent) moveFileUp(index int) {
 	}
 	mvc.selectedFiles[index], mvc.selectedFiles[index-1] = mvc.selectedFiles[index-1], mvc.selectedFiles[index]
 	mvc.filesList.Refresh()
+	mvc.updateCompatibility()
 }
 
 func (mvc *MergeVideosComponent) moveFileDown(index int) {
@@ -175,6 +258,7 @@ func (mvc *MergeVideosComponent) moveFileDown(index int) {
 	}
 	mvc.selectedFiles[index], mvc.selectedFiles[index+1] = mvc.selectedFiles[index+1], mvc.selectedFiles[index]
 	mvc.filesList.Refresh()
+	mvc.updateCompatibility()
 }
 
 func (mvc *MergeVideosComponent) removeFile(index int) {
@@ -183,13 +267,16 @@ func (mvc *MergeVideosComponent) removeFile(index int) {
 	}
 	mvc.selectedFiles = append(mvc.selectedFiles[:index], mvc.selectedFiles[index+1:]...)
 	mvc.filesList.Refresh()
-
-	if len(mvc.selectedFiles) < 2 {
-		mvc.mergeButton.Disable()
-	}
+	mvc.updateCompatibility()
 }
 
 func (mvc *MergeVideosComponent) startMerge() {
+	mvc.planMerge(false)
+}
+
+// planMerge resolves the ffmpeg command of the merge. With preview, the
+// command is shown and the very same plan runs when the user confirms.
+func (mvc *MergeVideosComponent) planMerge(preview bool) {
 	outputPath := mvc.outputEntry.Text
 	if outputPath == "" {
 		dialog.ShowError(fmt.Errorf("please specify an output file"), mvc.window)
@@ -197,12 +284,11 @@ func (mvc *MergeVideosComponent) startMerge() {
 	}
 
 	// Disable UI during merge
-	mvc.mergeButton.Disable()
-	mvc.outputEntry.Disable()
-	mvc.progressBar.Show()
-	mvc.progressBar.SetValue(0)
+	mvc.setBusy(true)
 	mvc.statusLabel.SetText("Merging videos...")
-	mvc.statusLabel.Show()
+	if preview {
+		mvc.statusLabel.SetText("Planning...")
+	}
 
 	// Extract file paths
 	inputPaths := make([]string, len(mvc.selectedFiles))
@@ -210,29 +296,97 @@ func (mvc *MergeVideosComponent) startMerge() {
 		inputPaths[i] = file.Format.Filename
 	}
 
+	options := services.MergeOptions{
+		Mode:                 mvc.mergeMode(),
+		WriteChapters:        mvc.chaptersCheck.Checked,
+		ChapterTitleTemplate: mvc.chapterEntry.Text,
+	}
+
 	// Start merge in background
 	go func() {
-		ctx := context.Background()
-		err := mvc.ffmpegService.MergeVideos(ctx, inputPaths, outputPath, func(progress float64, message string) {
-			mvc.progressBar.SetValue(progress)
-			mvc.statusLabel.SetText(message)
-		})
-
-		// Re-enable UI
-		mvc.mergeButton.Enable()
-		mvc.outputEntry.Enable()
-
+		plan, err := mvc.ffmpegService.PlanMerge(context.Background(), inputPaths, outputPath, options)
 		if err != nil {
-			logger.Errorf("Merge failed: %v", err)
-			mvc.statusLabel.SetText(fmt.Sprintf("Error: %v", err))
-			dialog.ShowError(err, mvc.window)
-		} else {
-			mvc.statusLabel.SetText(fmt.Sprintf("Successfully merged to: %s", outputPath))
-			dialog.ShowInformation("Success", fmt.Sprintf("Videos merged successfully!\n\nOutput: %s", outputPath), mvc.window)
+			mvc.finishMerge(outputPath, err)
+			return
+		}
 
-			if mvc.onComplete != nil {
-				mvc.onComplete(outputPath)
-			}
+		if !preview {
+			mvc.executeMerge(plan, outputPath)
+			return
 		}
+
+		mvc.setBusy(false)
+		mvc.progressBar.Hide()
+		mvc.statusLabel.Hide()
+		ShowPlanDialog(mvc.window, plan, mvc.ffmpegService.GetFFmpegPath(), func() {
+			mvc.setBusy(true)
+			mvc.statusLabel.SetText("Merging videos...")
+			go mvc.executeMerge(plan, outputPath)
+		})
 	}()
 }
+
+func (mvc *MergeVideosComponent) executeMerge(plan *services.Plan, outputPath string) {
+	err := mvc.ffmpegService.ExecuteMerge(context.Background(), plan, func(progress float64, message string) {
+		mvc.progressBar.SetValue(progress)
+		mvc.statusLabel.SetText(message)
+	})
+	mvc.finishMerge(outputPath, err)
+}
+
+func (mvc *MergeVideosComponent) finishMerge(outputPath string, err error) {
+	// Re-enable UI
+	mvc.setBusy(false)
+
+	if err != nil {
+		logger.Errorf("Merge failed: %v", err)
+		mvc.statusLabel.SetText(fmt.Sprintf("Error: %v", err))
+		dialog.ShowError(err, mvc.window)
+		return
+	}
+
+	mvc.statusLabel.SetText(fmt.Sprintf("Successfully merged to: %s", outputPath))
+	dialog.ShowInformation("Success", fmt.Sprintf("Videos merged successfully!\n\nOutput: %s", outputPath), mvc.window)
+
+	if mvc.onComplete != nil {
+		mvc.onComplete(outputPath)
+	}
+}
+
+// setBusy disables the UI while a merge is planned or running
+func (mvc *MergeVideosComponent) setBusy(busy bool) {
+	if busy {
+		mvc.mergeButton.Disable()
+		mvc.previewButton.Disable()
+		mvc.outputEntry.Disable()
+		mvc.modeRadio.Disable()
+		mvc.chaptersCheck.Disable()
+		mvc.progressBar.Show()
+		mvc.progressBar.SetValue(0)
+		mvc.statusLabel.Show()
+		return
+	}
+
+	mvc.updateMergeButton()
+	mvc.outputEntry.Enable()
+	mvc.modeRadio.Enable()
+	mvc.chaptersCheck.Enable()
+}
+
+// GetSettings returns the output settings saved in sessions
+func (mvc *MergeVideosComponent) GetSettings() map[string]string {
+	return map[string]string{
+		"output":           mvc.outputEntry.Text,
+		"chapters":         strconv.FormatBool(mvc.chaptersCheck.Checked),
+		"chapter_template": mvc.chapterEntry.Text,
+	}
+}
+
+// ApplySettings restores output settings saved in a session
+func (mvc *MergeVideosComponent) ApplySettings(settings map[string]string) {
+	applyEntrySetting(mvc.outputEntry, settings, "output")
+	applyEntrySetting(mvc.chapterEntry, settings, "chapter_template")
+	if chapters, err := strconv.ParseBool(settings["chapters"]); err == nil {
+		mvc.chaptersCheck.SetChecked(chapters)
+	}
+}
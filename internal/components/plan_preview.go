@@ -0,0 +1,77 @@
+package components
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+	"github.com/Developpeur-du-dimanche/MediaTools/internal/services"
+)
+
+// ShowPlanDialog shows the ffmpeg commands of a plan, with the predicted
+// output streams of every file, and lets the user copy them as a shell
+// script. onRun is called when the user runs the previewed plan.
+func ShowPlanDialog(window fyne.Window, plan *services.Plan, ffmpegPath string, onRun func()) {
+	summary := fmt.Sprintf("%d ffmpeg commands", len(plan.Steps))
+	if len(plan.Issues) > 0 {
+		summary += fmt.Sprintf(", %d files skipped", len(plan.Issues))
+	}
+
+	steps := container.NewVBox()
+	for _, step := range plan.Steps {
+		streams := make([]string, len(step.Streams))
+		for i, stream := range step.Streams {
+			streams[i] = stream.String()
+		}
+
+		details := fmt.Sprintf("Output: %s\nStreams: %s", step.OutputPath, strings.Join(streams, "; "))
+		if step.Note != "" {
+			details += "\n" + step.Note
+		}
+
+		steps.Add(widget.NewLabelWithStyle(filepath.Base(step.InputPath), fyne.TextAlignLeading, fyne.TextStyle{Bold: true}))
+		steps.Add(widget.NewLabel(details))
+	}
+	for _, issue := range plan.Issues {
+		steps.Add(widget.NewLabel(fmt.Sprintf("Skipped %s: %v", filepath.Base(issue.InputPath), issue.Err)))
+	}
+
+	script := plan.ShellScript(ffmpegPath)
+	scriptEntry := widget.NewMultiLineEntry()
+	scriptEntry.TextStyle = fyne.TextStyle{Monospace: true}
+	scriptEntry.SetText(script)
+
+	var planDialog dialog.Dialog
+
+	copyButton := widget.NewButtonWithIcon("Copy Script", theme.ContentCopyIcon(), func() {
+		window.Clipboard().SetContent(script)
+	})
+
+	runButton := widget.NewButtonWithIcon("Run This Plan", theme.MediaPlayIcon(), func() {
+		planDialog.Hide()
+		if onRun != nil {
+			onRun()
+		}
+	})
+	runButton.Importance = widget.HighImportance
+	if len(plan.Steps) == 0 {
+		runButton.Disable()
+	}
+
+	content := container.NewBorder(
+		widget.NewLabelWithStyle(summary, fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
+		container.NewHBox(copyButton, runButton),
+		nil,
+		nil,
+		container.NewVSplit(container.NewScroll(steps), scriptEntry),
+	)
+
+	planDialog = dialog.NewCustom("Preview (Dry Run)", "Close", content, window)
+	planDialog.Resize(fyne.NewSize(900, 600))
+	planDialog.Show()
+}
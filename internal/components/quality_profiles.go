@@ -0,0 +1,105 @@
+package components
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+	"github.com/Developpeur-du-dimanche/MediaTools/internal/quality"
+	"github.com/Developpeur-du-dimanche/MediaTools/pkg/logger"
+)
+
+// ShowQualityProfilesDialog lets the user pick, edit and create quality
+// scoring profiles. The chosen profile becomes active and the profiles are
+// saved to store; onChanged is called afterwards.
+func ShowQualityProfilesDialog(window fyne.Window, store *quality.Store, profiles *quality.ProfileSet, onChanged func()) {
+	nameEntry := widget.NewEntry()
+	languagesEntry := widget.NewEntry()
+	languagesEntry.SetPlaceHolder("e.g., fre, eng")
+
+	weightFields := []struct {
+		title string
+		value func(w *quality.Weights) *float64
+	}{
+		{"Resolution", func(w *quality.Weights) *float64 { return &w.Resolution }},
+		{"Codec Efficiency", func(w *quality.Weights) *float64 { return &w.CodecEfficiency }},
+		{"Bits per Pixel", func(w *quality.Weights) *float64 { return &w.BitsPerPixel }},
+		{"HDR", func(w *quality.Weights) *float64 { return &w.HDR }},
+		{"Audio Channels", func(w *quality.Weights) *float64 { return &w.AudioChannels }},
+		{"Audio Codec", func(w *quality.Weights) *float64 { return &w.AudioCodec }},
+		{"Language Coverage", func(w *quality.Weights) *float64 { return &w.LanguageCoverage }},
+	}
+	weightEntries := make([]*widget.Entry, len(weightFields))
+	for i := range weightFields {
+		weightEntries[i] = widget.NewEntry()
+	}
+
+	showProfile := func(profile quality.Profile) {
+		nameEntry.SetText(profile.Name)
+		languagesEntry.SetText(strings.Join(profile.PreferredLanguages, ", "))
+		for i, field := range weightFields {
+			weightEntries[i].SetText(strconv.FormatFloat(*field.value(&profile.Weights), 'f', -1, 64))
+		}
+	}
+
+	profileSelect := widget.NewSelect(profiles.Names(), func(name string) {
+		if profile, ok := profiles.Find(name); ok {
+			showProfile(profile)
+		}
+	})
+
+	items := []*widget.FormItem{
+		widget.NewFormItem("Profile", profileSelect),
+		widget.NewFormItem("Name", nameEntry),
+		widget.NewFormItem("Preferred Languages", languagesEntry),
+	}
+	for i, field := range weightFields {
+		items = append(items, widget.NewFormItem(field.title, weightEntries[i]))
+	}
+
+	profileSelect.SetSelected(profiles.ActiveProfile().Name)
+	if profileSelect.Selected == "" {
+		showProfile(profiles.ActiveProfile())
+	}
+
+	dialog.ShowForm("Quality Profiles", "Save & Use", "Cancel", items, func(confirmed bool) {
+		if !confirmed {
+			return
+		}
+
+		profile := quality.Profile{Name: strings.TrimSpace(nameEntry.Text)}
+		if profile.Name == "" {
+			dialog.ShowError(fmt.Errorf("please specify a profile name"), window)
+			return
+		}
+		for _, language := range strings.Split(languagesEntry.Text, ",") {
+			if language = strings.TrimSpace(language); language != "" {
+				profile.PreferredLanguages = append(profile.PreferredLanguages, language)
+			}
+		}
+		for i, field := range weightFields {
+			value, err := strconv.ParseFloat(strings.TrimSpace(weightEntries[i].Text), 64)
+			if err != nil || value < 0 {
+				dialog.ShowError(fmt.Errorf("invalid weight for %s: %q", field.title, weightEntries[i].Text), window)
+				return
+			}
+			*field.value(&profile.Weights) = value
+		}
+
+		profiles.Put(profile)
+		profiles.Active = profile.Name
+		quality.SetActive(profile)
+
+		if err := store.Save(profiles); err != nil {
+			logger.Errorf("Failed to save quality profiles: %v", err)
+			dialog.ShowError(err, window)
+		}
+
+		if onChanged != nil {
+			onChanged()
+		}
+	}, window)
+}
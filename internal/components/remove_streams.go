@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"path/filepath"
+	"strings"
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/container"
@@ -11,6 +12,7 @@ import (
 	"fyne.io/fyne/v2/theme"
 	"fyne.io/fyne/v2/widget"
 	"github.com/Developpeur-du-dimanche/MediaTools/internal/services"
+	"github.com/Developpeur-du-dimanche/MediaTools/pkg/languages"
 	"github.com/Developpeur-du-dimanche/MediaTools/pkg/logger"
 	"github.com/Developpeur-du-dimanche/MediaTools/pkg/medias"
 )
@@ -33,6 +35,7 @@ type RemoveStreamsComponent struct {
 	progressBar      *widget.ProgressBar
 	statusLabel      *widget.Label
 	processButton    *widget.Button
+	previewButton    *widget.Button
 	filesList        *widget.List
 
 	onComplete func(results []string)
@@ -58,6 +61,7 @@ func (rsc *RemoveStreamsComponent) initUI() {
 		"Remove streams by language",
 		"Remove streams by codec",
 		"Keep only streams by language",
+		"Remove chapters",
 	}, func(value string) {
 		rsc.updateCriteriaUI(value)
 	})
@@ -124,6 +128,11 @@ func (rsc *RemoveStreamsComponent) initUI() {
 		rsc.startProcessing()
 	})
 
+	// Preview button shows the ffmpeg commands without running them
+	rsc.previewButton = widget.NewButtonWithIcon("Preview (Dry Run)", theme.VisibilityIcon(), func() {
+		rsc.previewPlan()
+	})
+
 	rsc.operationSelect.SetSelectedIndex(0)
 	rsc.streamTypeSelect.SetSelected("Audio")
 
@@ -169,7 +178,7 @@ func (rsc *RemoveStreamsComponent) CreateRenderer() fyne.WidgetRenderer {
 			rsc.progressBar,
 			rsc.statusLabel,
 			widget.NewLabel(""),
-			rsc.processButton,
+			container.NewGridWithColumns(2, rsc.previewButton, rsc.processButton),
 		),
 		nil,
 		nil,
@@ -184,15 +193,18 @@ func (rsc *RemoveStreamsComponent) updateCriteriaUI(operation string) {
 	rsc.criteriaSelect.Hide()
 
 	switch operation {
-	case "Remove all streams of type":
+	case "Remove all streams of type", "Remove chapters":
 		// No additional criteria needed
 		return
 
-	case "Remove streams by language", "Keep only streams by language":
-		// Show language selector
-		rsc.criteriaSelect.Options = []string{
-			"fre", "eng", "spa", "deu", "ita", "jpn", "kor", "chi", "por", "rus", "ara", "hin",
-		}
+	case "Keep only streams by language":
+		// Ordered list of preferred languages
+		rsc.criteriaEntry.SetPlaceHolder("Languages by preference, e.g. French, English")
+		rsc.criteriaEntry.Show()
+
+	case "Remove streams by language":
+		// Show language selector, by name
+		rsc.criteriaSelect.Options = languages.Names()
 		rsc.criteriaSelect.PlaceHolder = "Select language..."
 		rsc.criteriaSelect.Show()
 
@@ -220,70 +232,138 @@ func (rsc *RemoveStreamsComponent) updateCriteriaUI(operation string) {
 }
 
 func (rsc *RemoveStreamsComponent) startProcessing() {
-	// Validate inputs
-	outputDir := rsc.outputDirEntry.Text
-	if outputDir == "" {
-		dialog.ShowError(fmt.Errorf("please specify an output directory"), rsc.window)
+	outputDir, ok := rsc.validateOutputDir()
+	if !ok {
 		return
 	}
 
 	operation := rsc.getOperationType()
 	criteria := rsc.getCriteria()
 
-	// Disable UI during processing
-	rsc.processButton.Disable()
-	rsc.operationSelect.Disable()
-	rsc.streamTypeSelect.Disable()
-	rsc.criteriaEntry.Disable()
-	rsc.criteriaSelect.Disable()
-	rsc.outputDirEntry.Disable()
-	rsc.progressBar.Show()
-	rsc.progressBar.SetValue(0)
-	rsc.statusLabel.SetText("Processing files...")
-	rsc.statusLabel.Show()
+	rsc.setBusy(true, "Processing files...")
 
 	// Start processing in background
 	go func() {
-		ctx := context.Background()
-		results, err := rsc.ffmpegService.BatchRemoveStreams(
-			ctx,
-			rsc.selectedFiles,
-			operation,
-			criteria,
-			outputDir,
-			func(progress float64, message string) {
-				rsc.progressBar.SetValue(progress)
-				rsc.statusLabel.SetText(message)
-			},
-		)
-
-		// Re-enable UI
-		rsc.processButton.Enable()
-		rsc.operationSelect.Enable()
-		rsc.streamTypeSelect.Enable()
-		rsc.criteriaEntry.Enable()
-		rsc.criteriaSelect.Enable()
-		rsc.outputDirEntry.Enable()
+		plan, err := rsc.ffmpegService.PlanRemoveStreams(context.Background(), rsc.selectedFiles, operation, criteria, outputDir)
+		if err != nil {
+			rsc.showError(err)
+			return
+		}
+		rsc.executePlan(plan)
+	}()
+}
+
+// previewPlan shows the ffmpeg commands of the operation without running
+// them. Running the preview executes the very same plan.
+func (rsc *RemoveStreamsComponent) previewPlan() {
+	outputDir, ok := rsc.validateOutputDir()
+	if !ok {
+		return
+	}
+
+	operation := rsc.getOperationType()
+	criteria := rsc.getCriteria()
+
+	rsc.setBusy(true, "Planning...")
 
+	go func() {
+		plan, err := rsc.ffmpegService.PlanRemoveStreams(context.Background(), rsc.selectedFiles, operation, criteria, outputDir)
 		if err != nil {
-			logger.Errorf("Processing failed: %v", err)
-			rsc.statusLabel.SetText(fmt.Sprintf("Error: %v", err))
-			dialog.ShowError(err, rsc.window)
-		} else {
-			rsc.statusLabel.SetText(fmt.Sprintf("Successfully processed %d files", len(results)))
-			dialog.ShowInformation(
-				"Success",
-				fmt.Sprintf("Successfully processed %d/%d files!\n\nOutput directory: %s", len(results), len(rsc.selectedFiles), outputDir),
-				rsc.window,
-			)
-
-			if rsc.onComplete != nil {
-				rsc.onComplete(results)
-			}
+			rsc.showError(err)
+			return
 		}
+
+		rsc.setBusy(false, "")
+		rsc.statusLabel.Hide()
+		rsc.progressBar.Hide()
+
+		ShowPlanDialog(rsc.window, plan, rsc.ffmpegService.GetFFmpegPath(), func() {
+			rsc.setBusy(true, "Processing files...")
+			go rsc.executePlan(plan)
+		})
 	}()
 }
 
+// executePlan runs a plan and reports the results
+func (rsc *RemoveStreamsComponent) executePlan(plan *services.Plan) {
+	result, err := rsc.ffmpegService.ExecutePlan(context.Background(), plan, rsc.onProgress)
+	rsc.showResult(result, err)
+}
+
+// retryFailed runs the failed files of a batch again
+func (rsc *RemoveStreamsComponent) retryFailed(result *services.BatchResult) {
+	rsc.setBusy(true, "Retrying failed files...")
+	go func() {
+		retried, err := rsc.ffmpegService.RetryFailed(context.Background(), result, rsc.onProgress)
+		rsc.showResult(retried, err)
+	}()
+}
+
+func (rsc *RemoveStreamsComponent) onProgress(progress float64, message string) {
+	rsc.progressBar.SetValue(progress)
+	rsc.statusLabel.SetText(message)
+}
+
+// showResult shows the outcome of every file, with a retry of the failed ones
+func (rsc *RemoveStreamsComponent) showResult(result *services.BatchResult, err error) {
+	if err != nil {
+		rsc.showError(err)
+		return
+	}
+
+	outputs := result.OutputPaths()
+	rsc.setBusy(false, fmt.Sprintf("Successfully processed %d/%d files", len(outputs), len(result.Files)))
+	ShowBatchResultsDialog(rsc.window, result, func() {
+		rsc.retryFailed(result)
+	})
+
+	if rsc.onComplete != nil {
+		rsc.onComplete(outputs)
+	}
+}
+
+func (rsc *RemoveStreamsComponent) validateOutputDir() (string, bool) {
+	outputDir := rsc.outputDirEntry.Text
+	if outputDir == "" {
+		dialog.ShowError(fmt.Errorf("please specify an output directory"), rsc.window)
+		return "", false
+	}
+	return outputDir, true
+}
+
+func (rsc *RemoveStreamsComponent) showError(err error) {
+	logger.Errorf("Processing failed: %v", err)
+	rsc.setBusy(false, fmt.Sprintf("Error: %v", err))
+	dialog.ShowError(err, rsc.window)
+}
+
+// setBusy disables the UI during processing and shows the status
+func (rsc *RemoveStreamsComponent) setBusy(busy bool, status string) {
+	widgets := []fyne.Disableable{
+		rsc.processButton,
+		rsc.previewButton,
+		rsc.operationSelect,
+		rsc.streamTypeSelect,
+		rsc.criteriaEntry,
+		rsc.criteriaSelect,
+		rsc.outputDirEntry,
+	}
+	for _, w := range widgets {
+		if busy {
+			w.Disable()
+		} else {
+			w.Enable()
+		}
+	}
+
+	if busy {
+		rsc.progressBar.Show()
+		rsc.progressBar.SetValue(0)
+	}
+	rsc.statusLabel.SetText(status)
+	rsc.statusLabel.Show()
+}
+
 func (rsc *RemoveStreamsComponent) getOperationType() string {
 	switch rsc.operationSelect.Selected {
 	case "Remove all streams of type":
@@ -294,6 +374,8 @@ func (rsc *RemoveStreamsComponent) getOperationType() string {
 		return "remove_by_codec"
 	case "Keep only streams by language":
 		return "keep_language"
+	case "Remove chapters":
+		return "remove_chapters"
 	default:
 		return "remove_by_type"
 	}
@@ -304,11 +386,13 @@ func (rsc *RemoveStreamsComponent) getCriteria() map[string]string {
 	criteria["type"] = rsc.streamTypeSelect.Selected
 
 	operation := rsc.operationSelect.Selected
-	if operation == "Remove streams by language" || operation == "Keep only streams by language" {
+	if operation == "Keep only streams by language" {
+		criteria["languages"] = strings.Join(services.ParseLanguageList(rsc.criteriaEntry.Text), ",")
+	} else if operation == "Remove streams by language" {
 		if rsc.criteriaSelect.Visible() {
-			criteria["language"] = rsc.criteriaSelect.Selected
+			criteria["language"] = languages.Canonical(rsc.criteriaSelect.Selected)
 		} else {
-			criteria["language"] = rsc.criteriaEntry.Text
+			criteria["language"] = languages.Canonical(rsc.criteriaEntry.Text)
 		}
 	} else if operation == "Remove streams by codec" {
 		if rsc.criteriaSelect.Visible() {
@@ -320,3 +404,15 @@ func (rsc *RemoveStreamsComponent) getCriteria() map[string]string {
 
 	return criteria
 }
+
+// GetSettings returns the output settings saved in sessions
+func (rsc *RemoveStreamsComponent) GetSettings() map[string]string {
+	return map[string]string{
+		"output_dir": rsc.outputDirEntry.Text,
+	}
+}
+
+// ApplySettings restores output settings saved in a session
+func (rsc *RemoveStreamsComponent) ApplySettings(settings map[string]string) {
+	applyEntrySetting(rsc.outputDirEntry, settings, "output_dir")
+}
@@ -1,12 +1,15 @@
 package components
 
 import (
+	"strconv"
+	"strings"
 	"time"
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/container"
 	"fyne.io/fyne/v2/lang"
 	"fyne.io/fyne/v2/widget"
+	"github.com/Developpeur-du-dimanche/MediaTools/internal/services"
 	"github.com/Developpeur-du-dimanche/MediaTools/pkg/logger"
 	"github.com/ncruces/zenity"
 )
@@ -20,14 +23,16 @@ type SettingsDialog struct {
 	ffmpegPathEntry     *widget.Entry
 	ffmpegChangedLabel  *widget.Label
 	onFFmpegPathChanged func(string)
+	onParallelChanged   func(services.ParallelOptions)
 }
 
 // NewSettingsDialog creates a new settings dialog
-func NewSettingsDialog(app fyne.App, window fyne.Window, onFFmpegPathChanged func(string)) *SettingsDialog {
+func NewSettingsDialog(app fyne.App, window fyne.Window, onFFmpegPathChanged func(string), onParallelChanged func(services.ParallelOptions)) *SettingsDialog {
 	sd := &SettingsDialog{
 		app:                 app,
 		window:              window,
 		onFFmpegPathChanged: onFFmpegPathChanged,
+		onParallelChanged:   onParallelChanged,
 	}
 
 	return sd
@@ -95,6 +100,8 @@ func (sd *SettingsDialog) Show() {
 		widget.NewSeparator(),
 	)
 
+	parallelSection := sd.createParallelSection()
+
 	// Close button
 	closeButton := widget.NewButton(lang.L("Close"), func() {
 		if sd.dialog != nil {
@@ -108,6 +115,7 @@ func (sd *SettingsDialog) Show() {
 		widget.NewSeparator(),
 		languageSection,
 		ffmpegSection,
+		parallelSection,
 		container.NewPadded(),
 		container.NewCenter(closeButton),
 	)
@@ -117,10 +125,67 @@ func (sd *SettingsDialog) Show() {
 		container.NewPadded(content),
 		sd.window.Canvas(),
 	)
-	sd.dialog.Resize(fyne.NewSize(400, 250))
+	sd.dialog.Resize(fyne.NewSize(400, 400))
 	sd.dialog.Show()
 }
 
+// createParallelSection lets the user choose how many files batches process at once
+func (sd *SettingsDialog) createParallelSection() fyne.CanvasObject {
+	options := LoadParallelOptions(sd.app.Preferences())
+
+	streamJobsEntry := widget.NewEntry()
+	streamJobsEntry.SetText(strconv.Itoa(options.StreamJobs))
+	checkJobsEntry := widget.NewEntry()
+	checkJobsEntry.SetText(strconv.Itoa(options.CheckJobs))
+	perDiskJobsEntry := widget.NewEntry()
+	perDiskJobsEntry.SetText(strconv.Itoa(options.PerDiskJobs))
+
+	savedLabel := widget.NewLabel("")
+
+	saveButton := widget.NewButton(lang.L("Save"), func() {
+		var values [3]int
+		for i, entry := range []*widget.Entry{streamJobsEntry, checkJobsEntry, perDiskJobsEntry} {
+			value, err := strconv.Atoi(strings.TrimSpace(entry.Text))
+			if err != nil || value < 0 {
+				savedLabel.SetText(lang.L("InvalidJobCount"))
+				return
+			}
+			values[i] = value
+		}
+
+		prefs := sd.app.Preferences()
+		prefs.SetInt(services.PreferenceKeyStreamJobs, values[0])
+		prefs.SetInt(services.PreferenceKeyCheckJobs, values[1])
+		prefs.SetInt(services.PreferenceKeyPerDiskJobs, values[2])
+		if sd.onParallelChanged != nil {
+			sd.onParallelChanged(LoadParallelOptions(prefs))
+		}
+		savedLabel.SetText(lang.L("ParallelJobsSaved"))
+	})
+
+	return container.NewVBox(
+		widget.NewLabelWithStyle(lang.L("ParallelJobs"), fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
+		widget.NewForm(
+			widget.NewFormItem(lang.L("StreamJobs"), streamJobsEntry),
+			widget.NewFormItem(lang.L("CheckJobs"), checkJobsEntry),
+			widget.NewFormItem(lang.L("PerDiskJobs"), perDiskJobsEntry),
+		),
+		container.NewBorder(nil, nil, nil, saveButton, savedLabel),
+		widget.NewSeparator(),
+	)
+}
+
+// LoadParallelOptions reads the parallel batch limits from the preferences,
+// falling back to the defaults
+func LoadParallelOptions(prefs fyne.Preferences) services.ParallelOptions {
+	defaults := services.DefaultParallelOptions()
+	return services.ParallelOptions{
+		StreamJobs:  prefs.IntWithFallback(services.PreferenceKeyStreamJobs, defaults.StreamJobs),
+		CheckJobs:   prefs.IntWithFallback(services.PreferenceKeyCheckJobs, defaults.CheckJobs),
+		PerDiskJobs: prefs.IntWithFallback(services.PreferenceKeyPerDiskJobs, defaults.PerDiskJobs),
+	}
+}
+
 func (sd *SettingsDialog) onLanguageChanged(selected string) {
 	var langCode string
 	switch selected {
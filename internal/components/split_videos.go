@@ -0,0 +1,361 @@
+package components
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+	"github.com/Developpeur-du-dimanche/MediaTools/internal/services"
+	"github.com/Developpeur-du-dimanche/MediaTools/pkg/logger"
+	"github.com/Developpeur-du-dimanche/MediaTools/pkg/medias"
+)
+
+const (
+	splitOperationTrim     = "Trim (keep start to end)"
+	splitOperationDuration = "Split by segment length"
+	splitOperationSize     = "Split by file size"
+	splitOperationChapters = "Split at chapters"
+)
+
+// SplitVideosComponent provides UI for trimming and splitting videos
+type SplitVideosComponent struct {
+	widget.BaseWidget
+
+	window        fyne.Window
+	ffmpegService *services.FFmpegService
+	selectedFiles []*medias.FfprobeResult
+
+	// UI elements
+	operationSelect *widget.Select
+	startEntry      *widget.Entry
+	endEntry        *widget.Entry
+	preciseCheck    *widget.Check
+	lengthEntry     *widget.Entry
+	sizeEntry       *widget.Entry
+	trimRow         *fyne.Container
+	outputDirEntry  *widget.Entry
+	outputDirRow    *fyne.Container
+	progressBar     *widget.ProgressBar
+	statusLabel     *widget.Label
+	processButton   *widget.Button
+	filesList       *widget.List
+}
+
+// NewSplitVideosComponent creates a new component for trimming and splitting videos
+func NewSplitVideosComponent(window fyne.Window, files []*medias.FfprobeResult, ffmpegService *services.FFmpegService) *SplitVideosComponent {
+	svc := &SplitVideosComponent{
+		window:        window,
+		ffmpegService: ffmpegService,
+		selectedFiles: files,
+	}
+
+	svc.initUI()
+	svc.ExtendBaseWidget(svc)
+	return svc
+}
+
+func (svc *SplitVideosComponent) initUI() {
+	// Trim criteria
+	svc.startEntry = widget.NewEntry()
+	svc.startEntry.SetPlaceHolder("Start (e.g., 00:01:30)")
+	svc.endEntry = widget.NewEntry()
+	svc.endEntry.SetPlaceHolder("End (empty = until the end)")
+	svc.preciseCheck = widget.NewCheck("Precise (re-encode the first GOP)", nil)
+	svc.trimRow = container.NewVBox(
+		container.NewGridWithColumns(2, svc.startEntry, svc.endEntry),
+		svc.preciseCheck,
+	)
+
+	// Split criteria
+	svc.lengthEntry = widget.NewEntry()
+	svc.lengthEntry.SetPlaceHolder("Segment length (e.g., 00:10:00)")
+	svc.sizeEntry = widget.NewEntry()
+	svc.sizeEntry.SetPlaceHolder("Segment size in MB (e.g., 700)")
+
+	svc.operationSelect = widget.NewSelect([]string{
+		splitOperationTrim,
+		splitOperationDuration,
+		splitOperationSize,
+		splitOperationChapters,
+	}, func(value string) {
+		svc.updateCriteriaUI(value)
+	})
+
+	// Output directory
+	svc.outputDirEntry = widget.NewEntry()
+	svc.outputDirEntry.SetPlaceHolder("Output directory")
+	svc.outputDirEntry.Text = "./processed"
+
+	browseDirButton := widget.NewButtonWithIcon("", theme.FolderOpenIcon(), func() {
+		dialog.ShowFolderOpen(func(dir fyne.ListableURI, err error) {
+			if err != nil || dir == nil {
+				return
+			}
+			svc.outputDirEntry.SetText(dir.Path())
+		}, svc.window)
+	})
+
+	svc.outputDirRow = container.NewBorder(nil, nil, nil, browseDirButton, svc.outputDirEntry)
+
+	// Files list
+	svc.filesList = widget.NewList(
+		func() int {
+			return len(svc.selectedFiles)
+		},
+		func() fyne.CanvasObject {
+			return widget.NewLabel("")
+		},
+		func(id widget.ListItemID, obj fyne.CanvasObject) {
+			label := obj.(*widget.Label)
+			file := svc.selectedFiles[id]
+			label.SetText(fmt.Sprintf("%s (%s)", filepath.Base(file.Format.Filename), file.Format.DurationSeconds.Round(time.Second)))
+		},
+	)
+
+	// Progress bar
+	svc.progressBar = widget.NewProgressBar()
+	svc.progressBar.Hide()
+
+	// Status label
+	svc.statusLabel = widget.NewLabel("")
+	svc.statusLabel.Hide()
+
+	// Process button
+	svc.processButton = widget.NewButtonWithIcon("Process Files", theme.ContentCutIcon(), func() {
+		svc.startProcessing()
+	})
+	svc.processButton.Importance = widget.HighImportance
+
+	svc.operationSelect.SetSelectedIndex(0)
+}
+
+func (svc *SplitVideosComponent) CreateRenderer() fyne.WidgetRenderer {
+	header := widget.NewLabelWithStyle(
+		fmt.Sprintf("Trim/Split - %d Files", len(svc.selectedFiles)),
+		fyne.TextAlignCenter,
+		fyne.TextStyle{Bold: true},
+	)
+
+	form := container.NewVBox(
+		widget.NewLabel("Operation:"),
+		svc.operationSelect,
+		widget.NewLabel(""),
+		widget.NewLabel("Criteria:"),
+		svc.trimRow,
+		svc.lengthEntry,
+		svc.sizeEntry,
+		widget.NewLabel(""),
+		widget.NewLabel("Output Directory:"),
+		svc.outputDirRow,
+	)
+
+	content := container.NewBorder(
+		container.NewVBox(
+			header,
+			widget.NewSeparator(),
+			form,
+			widget.NewSeparator(),
+			widget.NewLabel("Files to process:"),
+		),
+		container.NewVBox(
+			widget.NewLabel(""),
+			svc.progressBar,
+			svc.statusLabel,
+			widget.NewLabel(""),
+			svc.processButton,
+		),
+		nil,
+		nil,
+		svc.filesList,
+	)
+
+	return widget.NewSimpleRenderer(content)
+}
+
+func (svc *SplitVideosComponent) updateCriteriaUI(operation string) {
+	svc.trimRow.Hide()
+	svc.lengthEntry.Hide()
+	svc.sizeEntry.Hide()
+
+	switch operation {
+	case splitOperationTrim:
+		svc.trimRow.Show()
+	case splitOperationDuration:
+		svc.lengthEntry.Show()
+	case splitOperationSize:
+		svc.sizeEntry.Show()
+	}
+
+	svc.Refresh()
+}
+
+func (svc *SplitVideosComponent) startProcessing() {
+	outputDir := svc.outputDirEntry.Text
+	if outputDir == "" {
+		dialog.ShowError(fmt.Errorf("please specify an output directory"), svc.window)
+		return
+	}
+
+	operation := svc.operationSelect.Selected
+
+	var trimOptions services.TrimOptions
+	var splitOptions services.SplitOptions
+	var err error
+
+	switch operation {
+	case splitOperationTrim:
+		trimOptions, err = svc.getTrimOptions()
+	case splitOperationDuration:
+		var length time.Duration
+		length, err = parseTimestamp(svc.lengthEntry.Text)
+		splitOptions = services.SplitOptions{Mode: services.SplitByDuration, SegmentDuration: length}
+	case splitOperationSize:
+		var sizeMB float64
+		sizeMB, err = strconv.ParseFloat(strings.TrimSpace(svc.sizeEntry.Text), 64)
+		splitOptions = services.SplitOptions{Mode: services.SplitBySize, SegmentSize: int64(sizeMB * 1024 * 1024)}
+	case splitOperationChapters:
+		splitOptions = services.SplitOptions{Mode: services.SplitByChapters}
+	}
+	if err != nil {
+		dialog.ShowError(fmt.Errorf("invalid criteria: %w", err), svc.window)
+		return
+	}
+
+	// Disable UI during processing
+	svc.setInputsEnabled(false)
+	svc.progressBar.Show()
+	svc.progressBar.SetValue(0)
+	svc.statusLabel.SetText("Processing files...")
+	svc.statusLabel.Show()
+
+	// Start processing in background
+	go func() {
+		ctx := context.Background()
+		outputs := make([]string, 0)
+		failed := 0
+
+		for i, file := range svc.selectedFiles {
+			inputPath := file.Format.Filename
+			svc.statusLabel.SetText(fmt.Sprintf("[%d/%d] %s", i+1, len(svc.selectedFiles), filepath.Base(inputPath)))
+
+			var err error
+			if operation == splitOperationTrim {
+				outputPath := filepath.Join(outputDir, fmt.Sprintf("trimmed_%s", filepath.Base(inputPath)))
+				err = svc.ffmpegService.TrimVideo(ctx, inputPath, outputPath, trimOptions, nil)
+				if err == nil {
+					outputs = append(outputs, outputPath)
+				}
+			} else {
+				var parts []string
+				parts, err = svc.ffmpegService.SplitVideo(ctx, inputPath, outputDir, splitOptions, nil)
+				outputs = append(outputs, parts...)
+			}
+
+			if err != nil {
+				logger.Warnf("Failed to process %s: %v", inputPath, err)
+				failed++
+			}
+
+			svc.progressBar.SetValue(float64(i+1) / float64(len(svc.selectedFiles)))
+		}
+
+		// Re-enable UI
+		svc.setInputsEnabled(true)
+
+		svc.statusLabel.SetText(fmt.Sprintf("Created %d files, %d failures", len(outputs), failed))
+		dialog.ShowInformation(
+			"Done",
+			fmt.Sprintf("Processed %d/%d files, created %d output files.\n\nOutput directory: %s", len(svc.selectedFiles)-failed, len(svc.selectedFiles), len(outputs), outputDir),
+			svc.window,
+		)
+	}()
+}
+
+func (svc *SplitVideosComponent) getTrimOptions() (services.TrimOptions, error) {
+	start, err := parseTimestamp(svc.startEntry.Text)
+	if err != nil {
+		return services.TrimOptions{}, err
+	}
+
+	var end time.Duration
+	if strings.TrimSpace(svc.endEntry.Text) != "" {
+		end, err = parseTimestamp(svc.endEntry.Text)
+		if err != nil {
+			return services.TrimOptions{}, err
+		}
+	}
+
+	return services.TrimOptions{
+		Start:   start,
+		End:     end,
+		Precise: svc.preciseCheck.Checked,
+	}, nil
+}
+
+func (svc *SplitVideosComponent) setInputsEnabled(enabled bool) {
+	widgets := []fyne.Disableable{
+		svc.processButton,
+		svc.operationSelect,
+		svc.startEntry,
+		svc.endEntry,
+		svc.preciseCheck,
+		svc.lengthEntry,
+		svc.sizeEntry,
+		svc.outputDirEntry,
+	}
+	for _, w := range widgets {
+		if enabled {
+			w.Enable()
+		} else {
+			w.Disable()
+		}
+	}
+}
+
+// parseTimestamp parses "HH:MM:SS(.mmm)", "MM:SS" or a number of seconds
+func parseTimestamp(value string) (time.Duration, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, nil
+	}
+
+	parts := strings.Split(value, ":")
+	if len(parts) > 3 {
+		return 0, fmt.Errorf("invalid timestamp: %s", value)
+	}
+
+	seconds := 0.0
+	for _, part := range parts {
+		n, err := strconv.ParseFloat(part, 64)
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("invalid timestamp: %s", value)
+		}
+		seconds = seconds*60 + n
+	}
+
+	return time.Duration(seconds * float64(time.Second)), nil
+}
+
+// GetSettings returns the output settings saved in sessions
+func (svc *SplitVideosComponent) GetSettings() map[string]string {
+	return map[string]string{
+		"output_dir":     svc.outputDirEntry.Text,
+		"segment_length": svc.lengthEntry.Text,
+		"segment_size":   svc.sizeEntry.Text,
+	}
+}
+
+// ApplySettings restores output settings saved in a session
+func (svc *SplitVideosComponent) ApplySettings(settings map[string]string) {
+	applyEntrySetting(svc.outputDirEntry, settings, "output_dir")
+	applyEntrySetting(svc.lengthEntry, settings, "segment_length")
+	applyEntrySetting(svc.sizeEntry, settings, "segment_size")
+}
@@ -0,0 +1,156 @@
+package components
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+	"github.com/Developpeur-du-dimanche/MediaTools/internal/services"
+)
+
+// StatisticsComponent displays the statistics of the scanned library
+type StatisticsComponent struct {
+	widget.BaseWidget
+
+	window fyne.Window
+	stats  *services.LibraryStats
+
+	content *fyne.Container
+}
+
+// NewStatisticsComponent creates a new component displaying library statistics
+func NewStatisticsComponent(window fyne.Window, stats *services.LibraryStats) *StatisticsComponent {
+	sc := &StatisticsComponent{
+		window: window,
+		stats:  stats,
+	}
+
+	sc.initUI()
+	sc.ExtendBaseWidget(sc)
+	return sc
+}
+
+func (sc *StatisticsComponent) initUI() {
+	stats := sc.stats
+
+	overview := container.NewGridWithColumns(2,
+		widget.NewLabelWithStyle("Files:", fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
+		widget.NewLabel(strconv.Itoa(stats.FileCount)),
+		widget.NewLabelWithStyle("Total size:", fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
+		widget.NewLabel(formatSizeString(strconv.FormatInt(stats.TotalSize, 10))),
+		widget.NewLabelWithStyle("Total duration:", fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
+		widget.NewLabel(stats.TotalDuration.Round(time.Second).String()),
+		widget.NewLabelWithStyle("Total bitrate:", fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
+		widget.NewLabel(formatBitrateString(strconv.FormatInt(stats.TotalBitrate, 10))),
+		widget.NewLabelWithStyle("Average bitrate:", fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
+		widget.NewLabel(formatBitrateString(strconv.FormatInt(stats.AverageBitrate, 10))),
+	)
+
+	wasted := container.NewVBox()
+	if len(stats.MostWastedSpace) == 0 {
+		wasted.Add(widget.NewLabel("No file above the reference bitrate"))
+	}
+	for _, w := range stats.MostWastedSpace {
+		wasted.Add(widget.NewLabel(fmt.Sprintf("%s - %s %s, %s (reference %s), ~%s wasted",
+			filepath.Base(w.Path),
+			w.Resolution,
+			w.VideoCodec,
+			formatBitrateString(strconv.FormatInt(w.Bitrate, 10)),
+			formatBitrateString(strconv.FormatInt(w.ReferenceBitrate, 10)),
+			formatSizeString(strconv.FormatInt(w.WastedBytes, 10)),
+		)))
+	}
+
+	copyButton := widget.NewButtonWithIcon("Copy JSON Summary", theme.ContentCopyIcon(), func() {
+		data, err := json.MarshalIndent(sc.stats, "", "  ")
+		if err != nil {
+			return
+		}
+		sc.window.Clipboard().SetContent(string(data))
+	})
+
+	sc.content = container.NewVBox(
+		overview,
+		widget.NewSeparator(),
+		container.NewGridWithColumns(3,
+			distributionCard("Video codecs", stats.VideoCodecs, stats.FileCount),
+			distributionCard("Resolutions", stats.Resolutions, stats.FileCount),
+			distributionCard("Audio codecs", stats.AudioCodecs, 0),
+		),
+		widget.NewSeparator(),
+		container.NewGridWithColumns(2,
+			coverageCard("Audio languages", stats.AudioLanguages, stats.FileCount),
+			coverageCard("Subtitle languages", stats.SubtitleLanguages, stats.FileCount),
+		),
+		widget.NewSeparator(),
+		widget.NewLabelWithStyle("Most wasted space", fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
+		wasted,
+		widget.NewSeparator(),
+		copyButton,
+	)
+}
+
+func (sc *StatisticsComponent) CreateRenderer() fyne.WidgetRenderer {
+	header := widget.NewLabelWithStyle(
+		fmt.Sprintf("Library Statistics - %d Files", sc.stats.FileCount),
+		fyne.TextAlignCenter,
+		fyne.TextStyle{Bold: true},
+	)
+
+	content := container.NewBorder(
+		container.NewVBox(header, widget.NewSeparator()),
+		nil,
+		nil,
+		nil,
+		container.NewVScroll(sc.content),
+	)
+
+	return widget.NewSimpleRenderer(content)
+}
+
+// distributionCard lists the counts of a distribution, largest first. When
+// total is positive, each count is followed by its percentage.
+func distributionCard(title string, counts map[string]int, total int) fyne.CanvasObject {
+	box := container.NewVBox(widget.NewLabelWithStyle(title, fyne.TextAlignLeading, fyne.TextStyle{Bold: true}))
+	for _, key := range sortedKeys(counts) {
+		text := fmt.Sprintf("%s: %d", key, counts[key])
+		if total > 0 {
+			text = fmt.Sprintf("%s (%.1f%%)", text, float64(counts[key])*100/float64(total))
+		}
+		box.Add(widget.NewLabel(text))
+	}
+	return box
+}
+
+// coverageCard shows the share of files having each language
+func coverageCard(title string, coverage services.LanguageCoverage, total int) fyne.CanvasObject {
+	named := make(map[string]int, len(coverage.Languages))
+	for code, count := range coverage.Languages {
+		named[displayLanguage(code)] += count
+	}
+	box := distributionCard(title, named, total).(*fyne.Container)
+	box.Add(widget.NewLabel(fmt.Sprintf("none: %d", coverage.FilesWithout)))
+	return box
+}
+
+// sortedKeys returns the keys of counts by decreasing count, then by name
+func sortedKeys(counts map[string]int) []string {
+	keys := make([]string, 0, len(counts))
+	for key := range counts {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if counts[keys[i]] != counts[keys[j]] {
+			return counts[keys[i]] > counts[keys[j]]
+		}
+		return keys[i] < keys[j]
+	})
+	return keys
+}
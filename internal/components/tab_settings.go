@@ -0,0 +1,17 @@
+package components
+
+import "fyne.io/fyne/v2/widget"
+
+// TabSettings is implemented by the operation components whose output
+// settings are saved in sessions
+type TabSettings interface {
+	GetSettings() map[string]string
+	ApplySettings(settings map[string]string)
+}
+
+// applyEntrySetting sets the text of entry when settings has a non-empty value for key
+func applyEntrySetting(entry *widget.Entry, settings map[string]string, key string) {
+	if value := settings[key]; value != "" {
+		entry.SetText(value)
+	}
+}
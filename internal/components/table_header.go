@@ -0,0 +1,66 @@
+package components
+
+import (
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+// tableHeader is a media table header cell. Tapping it sorts by its column,
+// dragging it horizontally resizes the column.
+type tableHeader struct {
+	widget.BaseWidget
+
+	label    *widget.Label
+	sortIcon *widget.Icon
+
+	OnTapped  func()
+	OnDragged func(dx float32)
+	OnDragEnd func()
+}
+
+func newTableHeader() *tableHeader {
+	th := &tableHeader{
+		label:    widget.NewLabelWithStyle("", fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
+		sortIcon: widget.NewIcon(nil),
+	}
+	th.label.Truncation = fyne.TextTruncateEllipsis
+	th.ExtendBaseWidget(th)
+	return th
+}
+
+func (th *tableHeader) CreateRenderer() fyne.WidgetRenderer {
+	return widget.NewSimpleRenderer(container.NewBorder(nil, nil, nil, th.sortIcon, th.label))
+}
+
+// SetHeader sets the title and the sort indicator (0: none, 1: ascending, -1: descending)
+func (th *tableHeader) SetHeader(title string, sortDirection int) {
+	th.label.SetText(title)
+	switch sortDirection {
+	case 1:
+		th.sortIcon.SetResource(theme.MenuDropUpIcon())
+	case -1:
+		th.sortIcon.SetResource(theme.MenuDropDownIcon())
+	default:
+		th.sortIcon.SetResource(nil)
+	}
+}
+
+func (th *tableHeader) Tapped(*fyne.PointEvent) {
+	if th.OnTapped != nil {
+		th.OnTapped()
+	}
+}
+
+func (th *tableHeader) Dragged(e *fyne.DragEvent) {
+	if th.OnDragged != nil {
+		th.OnDragged(e.Dragged.DX)
+	}
+}
+
+func (th *tableHeader) DragEnd() {
+	if th.OnDragEnd != nil {
+		th.OnDragEnd()
+	}
+}
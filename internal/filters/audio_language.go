@@ -1,6 +1,9 @@
 package filters
 
-import "github.com/Developpeur-du-dimanche/MediaTools/pkg/medias"
+import (
+	"github.com/Developpeur-du-dimanche/MediaTools/pkg/languages"
+	"github.com/Developpeur-du-dimanche/MediaTools/pkg/medias"
+)
 
 type AudioLanguageFilter struct{}
 
@@ -12,7 +15,7 @@ func (f AudioLanguageFilter) Apply(data *medias.FfprobeResult, operator string,
 
 	// Loop through all audio streams and check if any matches the language
 	for _, audio := range data.Audios {
-		if compareString(audio.Language, operator, value) {
+		if compareLanguage(audio.Language, operator, value) {
 			return true
 		}
 	}
@@ -25,6 +28,6 @@ func (f AudioLanguageFilter) GetFieldConfig() FilterFieldConfig {
 		Key:              "AUDIO_LANGUAGE",
 		DisplayName:      "Audio Language",
 		Type:             FieldTypeString,
-		PredefinedValues: []string{"fre", "eng", "spa", "deu", "ita", "jpn", "kor", "chi", "por", "rus", "ara", "hin"},
+		PredefinedValues: languages.CommonNames(),
 	}
 }
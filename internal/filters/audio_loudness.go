@@ -0,0 +1,34 @@
+package filters
+
+import (
+	"strconv"
+
+	"github.com/Developpeur-du-dimanche/MediaTools/pkg/medias"
+)
+
+type AudioLoudnessFilter struct{}
+
+func (f AudioLoudnessFilter) Apply(data *medias.FfprobeResult, operator string, value string) bool {
+	// Parse the target loudness in LUFS
+	targetLoudness, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return false
+	}
+
+	// Only analyzed streams have a loudness
+	for _, audio := range data.Audios {
+		if audio.Loudness != nil && compareFloat(audio.Loudness.Integrated, operator, targetLoudness) {
+			return true
+		}
+	}
+	return false
+}
+
+func (f AudioLoudnessFilter) GetFieldConfig() FilterFieldConfig {
+	return FilterFieldConfig{
+		Key:         "AUDIO_LOUDNESS",
+		DisplayName: "Audio Loudness (LUFS)",
+		Type:        FieldTypeNumeric,
+		Placeholder: "e.g., -18 (analyzed files only)",
+	}
+}
@@ -0,0 +1,34 @@
+package filters
+
+import (
+	"strconv"
+
+	"github.com/Developpeur-du-dimanche/MediaTools/pkg/medias"
+)
+
+type AudioLoudnessRangeFilter struct{}
+
+func (f AudioLoudnessRangeFilter) Apply(data *medias.FfprobeResult, operator string, value string) bool {
+	// Parse the target loudness range in LU
+	targetRange, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return false
+	}
+
+	// Only analyzed streams have a loudness range
+	for _, audio := range data.Audios {
+		if audio.Loudness != nil && compareFloat(audio.Loudness.LRA, operator, targetRange) {
+			return true
+		}
+	}
+	return false
+}
+
+func (f AudioLoudnessRangeFilter) GetFieldConfig() FilterFieldConfig {
+	return FilterFieldConfig{
+		Key:         "AUDIO_LRA",
+		DisplayName: "Audio Loudness Range (LU)",
+		Type:        FieldTypeNumeric,
+		Placeholder: "e.g., 15 (analyzed files only)",
+	}
+}
@@ -0,0 +1,34 @@
+package filters
+
+import (
+	"strconv"
+
+	"github.com/Developpeur-du-dimanche/MediaTools/pkg/medias"
+)
+
+type AudioTruePeakFilter struct{}
+
+func (f AudioTruePeakFilter) Apply(data *medias.FfprobeResult, operator string, value string) bool {
+	// Parse the target true peak in dBTP
+	targetPeak, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return false
+	}
+
+	// Only analyzed streams have a true peak
+	for _, audio := range data.Audios {
+		if audio.Loudness != nil && compareFloat(audio.Loudness.TruePeak, operator, targetPeak) {
+			return true
+		}
+	}
+	return false
+}
+
+func (f AudioTruePeakFilter) GetFieldConfig() FilterFieldConfig {
+	return FilterFieldConfig{
+		Key:         "AUDIO_TRUE_PEAK",
+		DisplayName: "Audio True Peak (dBTP)",
+		Type:        FieldTypeNumeric,
+		Placeholder: "e.g., -1 (analyzed files only)",
+	}
+}
@@ -0,0 +1,29 @@
+package filters
+
+import (
+	"strconv"
+
+	"github.com/Developpeur-du-dimanche/MediaTools/pkg/medias"
+)
+
+type ChapterCountFilter struct{}
+
+func (f ChapterCountFilter) Apply(data *medias.FfprobeResult, operator string, value string) bool {
+	// Parse the target chapter count
+	targetCount, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return false
+	}
+
+	actualCount := int64(len(data.Chapters))
+	return compareNumeric(actualCount, operator, targetCount)
+}
+
+func (f ChapterCountFilter) GetFieldConfig() FilterFieldConfig {
+	return FilterFieldConfig{
+		Key:         "CHAPTER_COUNT",
+		DisplayName: "Chapter Count",
+		Type:        FieldTypeNumeric,
+		Placeholder: "e.g., 10",
+	}
+}
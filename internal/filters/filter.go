@@ -52,5 +52,11 @@ func GetAllFilters() []Filter {
 		HasVideoFilter{},
 		HasAudioFilter{},
 		HasSubtitlesFilter{},
+		HasChaptersFilter{},
+		ChapterCountFilter{},
+		QualityScoreFilter{},
+		AudioLoudnessFilter{},
+		AudioTruePeakFilter{},
+		AudioLoudnessRangeFilter{},
 	}
 }
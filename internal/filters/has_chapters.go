@@ -0,0 +1,20 @@
+package filters
+
+import "github.com/Developpeur-du-dimanche/MediaTools/pkg/medias"
+
+type HasChaptersFilter struct{}
+
+func (f HasChaptersFilter) Apply(data *medias.FfprobeResult, operator string, value string) bool {
+	// Check if there are any chapters
+	hasChapters := len(data.Chapters) > 0
+	return compareBool(hasChapters, operator, value)
+}
+
+func (f HasChaptersFilter) GetFieldConfig() FilterFieldConfig {
+	return FilterFieldConfig{
+		Key:              "HAS_CHAPTERS",
+		DisplayName:      "Has Chapters",
+		Type:             FieldTypeBoolean,
+		PredefinedValues: []string{"true", "false"},
+	}
+}
@@ -3,6 +3,8 @@ package filters
 import (
 	"strconv"
 	"strings"
+
+	"github.com/Developpeur-du-dimanche/MediaTools/pkg/languages"
 )
 
 // parseBitrateValue converts a bitrate string (e.g., "2000kbps", "2mbps") to bits per second
@@ -105,3 +107,20 @@ func compareBool(actual bool, operator string, valueStr string) bool {
 		return false
 	}
 }
+
+// compareLanguage compares two languages using the specified operator. Both
+// sides are normalized, so "fr", "fre", "fra" and "French" are the same language.
+func compareLanguage(actual string, operator string, target string) bool {
+	switch operator {
+	case "IS", "==":
+		return languages.Match(actual, target)
+	case "IS_NOT", "!=":
+		return !languages.Match(actual, target)
+	case "CONTAINS":
+		return languages.Contains(actual, target)
+	case "NOT_CONTAINS":
+		return !languages.Contains(actual, target)
+	default:
+		return false
+	}
+}
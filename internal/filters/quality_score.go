@@ -0,0 +1,31 @@
+package filters
+
+import (
+	"strconv"
+
+	"github.com/Developpeur-du-dimanche/MediaTools/internal/quality"
+	"github.com/Developpeur-du-dimanche/MediaTools/pkg/medias"
+)
+
+type QualityScoreFilter struct{}
+
+func (f QualityScoreFilter) Apply(data *medias.FfprobeResult, operator string, value string) bool {
+	// Parse the target score (0 to 100)
+	targetScore, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return false
+	}
+
+	// Score the file with the active quality profile
+	actualScore := quality.Score(data, quality.Active())
+	return compareFloat(actualScore, operator, targetScore)
+}
+
+func (f QualityScoreFilter) GetFieldConfig() FilterFieldConfig {
+	return FilterFieldConfig{
+		Key:         "QUALITY_SCORE",
+		DisplayName: "Quality Score",
+		Type:        FieldTypeNumeric,
+		Placeholder: "e.g., 60 (0 to 100)",
+	}
+}
@@ -1,6 +1,9 @@
 package filters
 
-import "github.com/Developpeur-du-dimanche/MediaTools/pkg/medias"
+import (
+	"github.com/Developpeur-du-dimanche/MediaTools/pkg/languages"
+	"github.com/Developpeur-du-dimanche/MediaTools/pkg/medias"
+)
 
 type SubtitleLanguageFilter struct{}
 
@@ -12,7 +15,7 @@ func (f SubtitleLanguageFilter) Apply(data *medias.FfprobeResult, operator strin
 
 	// Loop through all subtitle streams and check if any matches the language
 	for _, subtitle := range data.Subtitles {
-		if compareString(subtitle.Language, operator, value) {
+		if compareLanguage(subtitle.Language, operator, value) {
 			return true
 		}
 	}
@@ -25,6 +28,6 @@ func (f SubtitleLanguageFilter) GetFieldConfig() FilterFieldConfig {
 		Key:              "SUBTITLE_LANGUAGE",
 		DisplayName:      "Subtitle Language",
 		Type:             FieldTypeString,
-		PredefinedValues: []string{"fre", "eng", "spa", "deu", "ita", "jpn", "kor", "chi", "por", "rus", "ara", "hin"},
+		PredefinedValues: languages.CommonNames(),
 	}
 }
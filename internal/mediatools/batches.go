@@ -0,0 +1,119 @@
+package mediatools
+
+import (
+	"context"
+	"path/filepath"
+
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/lang"
+	"fyne.io/fyne/v2/widget"
+	"github.com/Developpeur-du-dimanche/MediaTools/internal/components"
+	"github.com/Developpeur-du-dimanche/MediaTools/internal/services"
+	"github.com/Developpeur-du-dimanche/MediaTools/pkg/logger"
+)
+
+// initJournals enregistre les traitements par lot et l'historique des vérifications dans le stockage de l'application
+func (mt *MediaTools) initJournals() {
+	root := mt.app.Storage().RootURI().Path()
+	mt.ffmpegService.SetJournalStore(services.NewJournalStore(filepath.Join(root, services.JournalsDirName)))
+	checkHistory := services.NewCheckHistory(filepath.Join(root, services.CheckHistoryFileName))
+	mt.ffmpegService.SetCheckHistory(checkHistory)
+	components.SetColumnCheckHistory(checkHistory)
+}
+
+// resumeBatches propose de reprendre les traitements interrompus lors de la session précédente
+func (mt *MediaTools) resumeBatches() {
+	journals, err := mt.ffmpegService.GetJournalStore().Unfinished()
+	if err != nil {
+		logger.Warnf("Could not read batch journals: %v", err)
+		return
+	}
+
+	for _, journal := range journals {
+		mt.askResumeBatch(journal)
+	}
+}
+
+// askResumeBatch demande s'il faut reprendre un traitement ; sinon il est abandonné
+func (mt *MediaTools) askResumeBatch(journal *services.BatchJournal) {
+	pending, _, _ := journal.Counts()
+	message := lang.L("ResumeBatchMessage", map[string]any{
+		"Operation": batchKindLabel(journal.Kind),
+		"StartedAt": journal.StartedAt.Format("2006-01-02 15:04"),
+		"Pending":   pending,
+		"Total":     len(journal.Entries),
+	})
+
+	dialog.ShowConfirm(lang.L("ResumeBatch"), message, func(confirmed bool) {
+		if !confirmed {
+			if err := mt.ffmpegService.GetJournalStore().Discard(journal); err != nil {
+				logger.Warnf("Could not discard batch journal: %v", err)
+			}
+			return
+		}
+		go mt.runResumedBatch(journal)
+	}, mt.window)
+}
+
+// runResumedBatch reprend un traitement en affichant sa progression
+func (mt *MediaTools) runResumedBatch(journal *services.BatchJournal) {
+	mt.runBatchWithProgress(func(progress services.ProgressCallback) (*services.BatchResult, error) {
+		return mt.ffmpegService.ResumeBatch(context.Background(), journal, progress)
+	})
+}
+
+// runBatchWithProgress lance un traitement dans une boîte de progression puis affiche son résultat
+func (mt *MediaTools) runBatchWithProgress(run func(progress services.ProgressCallback) (*services.BatchResult, error)) {
+	progressBar := widget.NewProgressBar()
+	progressLabel := widget.NewLabel("")
+	progressDialog := dialog.NewCustomWithoutButtons(lang.L("ResumingBatch"),
+		container.NewVBox(progressLabel, progressBar),
+		mt.window,
+	)
+	progressDialog.Show()
+
+	result, err := run(func(progress float64, message string) {
+		progressBar.SetValue(progress)
+		progressLabel.SetText(message)
+	})
+	progressDialog.Hide()
+
+	if err != nil {
+		dialog.ShowError(err, mt.window)
+		return
+	}
+
+	if result.Kind.RunsSteps() {
+		// Les suppressions de flux et normalisations affichent le détail par fichier, avec la relance des échecs
+		components.ShowBatchResultsDialog(mt.window, result, func() {
+			go mt.runBatchWithProgress(func(progress services.ProgressCallback) (*services.BatchResult, error) {
+				return mt.ffmpegService.RetryFailed(context.Background(), result, progress)
+			})
+		})
+		return
+	}
+
+	_, done, failed := result.Counts()
+	message := lang.L("BatchResumed", map[string]any{"Done": done, "Failed": failed})
+	corrupted := 0
+	for _, entry := range result.Files {
+		if entry.Check != nil && !entry.Check.IsValid {
+			corrupted++
+		}
+	}
+	message += "\n" + lang.L("BatchCorrupted", map[string]any{"Count": corrupted})
+	dialog.ShowInformation(lang.L("ResumeBatch"), message, mt.window)
+}
+
+// batchKindLabel retourne le nom traduit d'un type de traitement
+func batchKindLabel(kind services.BatchKind) string {
+	switch kind {
+	case services.BatchKindCheckVideos:
+		return lang.L("BatchVideoCheck")
+	case services.BatchKindNormalizeLoudness:
+		return lang.L("BatchLoudnessNormalization")
+	default:
+		return lang.L("BatchStreamRemoval")
+	}
+}
@@ -4,15 +4,19 @@ import (
 	"context"
 	"fmt"
 	"image/color"
+	"path/filepath"
+	"sync"
 	"time"
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/canvas"
 	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
 	"fyne.io/fyne/v2/lang"
 	"fyne.io/fyne/v2/theme"
 	"fyne.io/fyne/v2/widget"
 	"github.com/Developpeur-du-dimanche/MediaTools/internal/components"
+	"github.com/Developpeur-du-dimanche/MediaTools/internal/quality"
 	"github.com/Developpeur-du-dimanche/MediaTools/internal/services"
 	"github.com/Developpeur-du-dimanche/MediaTools/internal/utils"
 	"github.com/Developpeur-du-dimanche/MediaTools/pkg/logger"
@@ -29,37 +33,68 @@ type MediaTools struct {
 	// Services
 	mediaService   *services.MediaService
 	historyService *services.HistoryService
+	sessionService *services.SessionService
 	filterService  *services.FilterService
 	ffmpegService  *services.FFmpegService
 
+	thumbnailService *services.ThumbnailService
+	checksumService  *services.ChecksumService
+
+	// Quality scoring profiles
+	qualityStore    *quality.Store
+	qualityProfiles *quality.ProfileSet
+
 	// UI Components
-	openFolder     *components.OpenFolder
-	openFile       *components.OpenFile
-	history        *components.LastScanSelector
-	filterBar      *components.FilterBar
-	cleanButton    *widget.Button
-	selectAllBtn   *widget.Button
-	unselectAllBtn *widget.Button
-	settingsButton *widget.Button
-	settingsDialog *components.SettingsDialog
+	openFolder      *components.OpenFolder
+	openFile        *components.OpenFile
+	history         *components.LastScanSelector
+	filterBar       *components.FilterBar
+	cleanButton     *widget.Button
+	selectAllBtn    *widget.Button
+	unselectAllBtn  *widget.Button
+	columnsButton   *widget.Button
+	qualityButton   *widget.Button
+	checksumsButton *widget.Button
+	searchEntry     *widget.Entry
+	settingsButton  *widget.Button
+	settingsDialog  *components.SettingsDialog
+	saveSessionBtn  *widget.Button
+	openSessionBtn  *widget.Button
 
 	// Tabs for operations (below media list)
 	operationTabs    *container.AppTabs
 	filterTab        *container.TabItem
 	mergeTab         *container.TabItem
 	removeStreamsTab *container.TabItem
+	splitVideosTab   *container.TabItem
 	checkVideosTab   *container.TabItem
+	contactSheetsTab *container.TabItem
+	loudnessTab      *container.TabItem
+	statisticsTab    *container.TabItem
+	compareTab       *container.TabItem
 
 	// Components for tabs
 	filterResultsList      *widget.List
 	mergeComponent         *components.MergeVideosComponent
 	removeStreamsComponent *components.RemoveStreamsComponent
+	splitVideosComponent   *components.SplitVideosComponent
 	checkVideosComponent   *components.CheckVideosComponent
+	contactSheetsComponent *components.ContactSheetsComponent
+	loudnessComponent      *components.LoudnessComponent
+	statisticsComponent    *components.StatisticsComponent
+	compareComponent       *components.CompareMediaComponent
 
 	// Data
-	allMediaItems      []*medias.FfprobeResult
 	filteredMediaItems []*medias.FfprobeResult
 	currentFilter      string
+
+	// sessionMu protège les réglages des onglets et userLoaded, car la
+	// session précédente est restaurée en arrière-plan
+	sessionMu   sync.Mutex
+	tabSettings map[string]map[string]string
+	// userLoaded indique que l'utilisateur a rempli la liste lui-même : la
+	// session précédente n'est alors plus restaurée
+	userLoaded bool
 }
 
 // NewMediaTools crée une nouvelle instance de l'application
@@ -90,8 +125,14 @@ func (mt *MediaTools) initComponents() {
 	// Initialiser les services
 	mt.mediaService = services.NewMediaService(utils.GetValidExtensions(), 10*time.Second)
 	mt.historyService = services.NewHistoryService(mt.app)
+	mt.sessionService = services.NewSessionService(mt.app)
 	mt.filterService = services.NewFilterService()
 	mt.ffmpegService = services.NewFFmpegService()
+	mt.thumbnailService = services.NewThumbnailService(mt.ffmpegService, "")
+	mt.checksumService = services.NewChecksumService(mt.ffmpegService)
+	mt.initJournals()
+	mt.ffmpegService.SetParallelOptions(components.LoadParallelOptions(mt.app.Preferences()))
+	mt.loadQualityProfiles()
 
 	// Load custom FFmpeg path if saved
 	savedFFmpegPath := mt.app.Preferences().StringWithFallback("ffmpeg_path", "")
@@ -114,11 +155,12 @@ func (mt *MediaTools) initComponents() {
 	}
 
 	// Initialiser les données
-	mt.allMediaItems = make([]*medias.FfprobeResult, 0)
 	mt.filteredMediaItems = make([]*medias.FfprobeResult, 0)
+	mt.tabSettings = make(map[string]map[string]string)
 
 	// Initialiser les composants UI
 	mt.listView = components.NewListView(nil, mt.window, mt.ffmpegService)
+	mt.listView.SetThumbnailService(mt.thumbnailService)
 	mt.history = components.NewLastScanSelector(mt.historyService, mt.onHistoryFolderSelected)
 	mt.openFolder = components.NewOpenFolder(mt.window, mt.onFolderOpened, mt.onScanProgress)
 	mt.openFile = components.NewOpenFile(mt.window, mt.onFileOpened)
@@ -126,14 +168,26 @@ func (mt *MediaTools) initComponents() {
 	mt.cleanButton = widget.NewButtonWithIcon(lang.L("Clean"), theme.DeleteIcon(), mt.onCleanButtonClicked)
 	mt.selectAllBtn = widget.NewButtonWithIcon(lang.L("SelectAll"), theme.CheckButtonCheckedIcon(), mt.onSelectAllClicked)
 	mt.unselectAllBtn = widget.NewButtonWithIcon(lang.L("UnselectAll"), theme.CheckButtonIcon(), mt.onUnselectAllClicked)
+	mt.searchEntry = widget.NewEntry()
+	mt.searchEntry.SetPlaceHolder(lang.L("QuickSearchPlaceholder"))
+	mt.searchEntry.OnChanged = mt.onQuickSearchChanged
+	mt.columnsButton = widget.NewButtonWithIcon(lang.L("Columns"), theme.ListIcon(), mt.onColumnsClicked)
+	mt.qualityButton = widget.NewButtonWithIcon(lang.L("QualityProfiles"), theme.SettingsIcon(), mt.onQualityProfilesClicked)
+	mt.checksumsButton = widget.NewButtonWithIcon(lang.L("Checksums"), theme.ConfirmIcon(), mt.onChecksumsClicked)
 	mt.settingsButton = widget.NewButtonWithIcon(lang.L("Settings"), theme.SettingsIcon(), mt.onSettingsClicked)
-	mt.settingsDialog = components.NewSettingsDialog(mt.app, mt.window, mt.onFFmpegPathChanged)
+	mt.settingsDialog = components.NewSettingsDialog(mt.app, mt.window, mt.onFFmpegPathChanged, mt.onParallelOptionsChanged)
+	mt.saveSessionBtn = widget.NewButtonWithIcon(lang.L("SaveSession"), theme.DocumentSaveIcon(), mt.onSaveSessionClicked)
+	mt.openSessionBtn = widget.NewButtonWithIcon(lang.L("OpenSession"), theme.FolderOpenIcon(), mt.onOpenSessionClicked)
 
 	// Initialiser les composants pour les onglets (seront créés à la demande)
 	mt.filterResultsList = nil
 	mt.mergeComponent = nil
 	mt.removeStreamsComponent = nil
+	mt.splitVideosComponent = nil
 	mt.checkVideosComponent = nil
+	mt.contactSheetsComponent = nil
+	mt.statisticsComponent = nil
+	mt.compareComponent = nil
 }
 
 // setupLayout configure la disposition des éléments dans la fenêtre
@@ -144,9 +198,14 @@ func (mt *MediaTools) setupLayout() {
 		mt.openFolder,
 		mt.cleanButton,
 		mt.history,
+		mt.saveSessionBtn,
+		mt.openSessionBtn,
 		widget.NewSeparator(),
 		mt.selectAllBtn,
 		mt.unselectAllBtn,
+		mt.columnsButton,
+		mt.qualityButton,
+		mt.checksumsButton,
 		widget.NewSeparator(),
 		mt.settingsButton,
 	)
@@ -158,7 +217,7 @@ func (mt *MediaTools) setupLayout() {
 		container.NewVBox(
 			toolBar,
 			widget.NewSeparator(),
-			mediaListHeader,
+			container.NewBorder(nil, nil, mediaListHeader, nil, mt.searchEntry),
 		),
 		nil,
 		nil,
@@ -170,14 +229,24 @@ func (mt *MediaTools) setupLayout() {
 	mt.filterTab = mt.createFilterTab()
 	mt.mergeTab = mt.createMergeTab()
 	mt.removeStreamsTab = mt.createRemoveStreamsTab()
+	mt.splitVideosTab = mt.createSplitVideosTab()
 	mt.checkVideosTab = mt.createCheckVideosTab()
+	mt.contactSheetsTab = mt.createContactSheetsTab()
+	mt.loudnessTab = mt.createLoudnessTab()
+	mt.statisticsTab = mt.createStatisticsTab()
+	mt.compareTab = mt.createCompareTab()
 
 	// Onglets d'opérations en dessous
 	mt.operationTabs = container.NewAppTabs(
 		mt.filterTab,
 		mt.mergeTab,
 		mt.removeStreamsTab,
+		mt.splitVideosTab,
 		mt.checkVideosTab,
+		mt.contactSheetsTab,
+		mt.loudnessTab,
+		mt.statisticsTab,
+		mt.compareTab,
 	)
 
 	backgroud := canvas.NewRectangle(color.RGBA{
@@ -223,10 +292,11 @@ func (mt *MediaTools) createFilterTab() *container.TabItem {
 		filterStr := mt.filterBar.GetFilterText()
 		if filterStr == "" {
 			resultsLabel.SetText(lang.L("NoFilterAppliedShowingAll"))
-			mt.filteredMediaItems = mt.allMediaItems
+			mt.filteredMediaItems = mt.listView.GetItems()
 		} else {
 			// Apply filter without affecting the main list
-			filtered, err := mt.filterService.FilterMediaList(mt.allMediaItems, filterStr)
+			allMediaItems := mt.listView.GetItems()
+			filtered, err := mt.filterService.FilterMediaList(allMediaItems, filterStr)
 			if err != nil {
 				logger.Errorf("Filter error: %v", err)
 				resultsLabel.SetText(lang.L("FilterError", map[string]any{"Error": err.Error()}))
@@ -237,22 +307,55 @@ func (mt *MediaTools) createFilterTab() *container.TabItem {
 				"Filter": filterStr,
 				"Count":  len(mt.filteredMediaItems),
 			}))
-			logger.Infof("Filter applied: %d/%d items match", len(filtered), len(mt.allMediaItems))
+			logger.Infof("Filter applied: %d/%d items match", len(filtered), len(allMediaItems))
 		}
 		mt.filterResultsList.Refresh()
 	})
 	applyButton.Importance = widget.HighImportance
 
 	clearButton := widget.NewButtonWithIcon(lang.L("ClearFilter"), theme.ContentClearIcon(), func() {
-		mt.filteredMediaItems = mt.allMediaItems
+		mt.filteredMediaItems = mt.listView.GetItems()
 		resultsLabel.SetText(lang.L("FilterCleared"))
 		mt.filterResultsList.Refresh()
 	})
 
+	// Actions appliquant le filtre à la sélection de la liste principale
+	selectMatchingButton := widget.NewButtonWithIcon(lang.L("SelectMatching"), theme.CheckButtonCheckedIcon(), func() {
+		match, ok := mt.filterMatcher(resultsLabel)
+		if !ok {
+			return
+		}
+		count := mt.listView.SelectMatching(match)
+		resultsLabel.SetText(lang.L("MatchingSelected", map[string]any{"Count": count}))
+	})
+
+	addMatchingButton := widget.NewButtonWithIcon(lang.L("AddMatchingToSelection"), theme.ContentAddIcon(), func() {
+		match, ok := mt.filterMatcher(resultsLabel)
+		if !ok {
+			return
+		}
+		count := mt.listView.AddMatchingToSelection(match)
+		resultsLabel.SetText(lang.L("MatchingAddedToSelection", map[string]any{"Count": count}))
+	})
+
+	invertSelectionButton := widget.NewButtonWithIcon(lang.L("InvertSelection"), theme.ViewRefreshIcon(), func() {
+		mt.listView.InvertSelection()
+	})
+
+	removeNonMatchingButton := widget.NewButtonWithIcon(lang.L("RemoveNonMatching"), theme.DeleteIcon(), func() {
+		match, ok := mt.filterMatcher(resultsLabel)
+		if !ok {
+			return
+		}
+		removed := mt.listView.RemoveNonMatching(match)
+		resultsLabel.SetText(lang.L("NonMatchingRemoved", map[string]any{"Count": removed}))
+	})
+
 	// Header avec les contrôles
 	header := container.NewVBox(
 		mt.filterBar,
 		container.NewHBox(applyButton, clearButton),
+		container.NewHBox(selectMatchingButton, addMatchingButton, invertSelectionButton, removeNonMatchingButton),
 		widget.NewSeparator(),
 		resultsLabel,
 	)
@@ -269,6 +372,21 @@ func (mt *MediaTools) createFilterTab() *container.TabItem {
 	return container.NewTabItem(lang.L("Filter"), content)
 }
 
+// filterMatcher construit une fonction de correspondance à partir du filtre saisi.
+// Un filtre vide correspond à tous les fichiers.
+func (mt *MediaTools) filterMatcher(resultsLabel *widget.Label) (func(item *medias.FfprobeResult) bool, bool) {
+	expr, err := mt.filterService.ParseFilter(mt.filterBar.GetFilterText())
+	if err != nil {
+		logger.Errorf("Filter error: %v", err)
+		resultsLabel.SetText(lang.L("FilterError", map[string]any{"Error": err.Error()}))
+		return nil, false
+	}
+
+	return func(item *medias.FfprobeResult) bool {
+		return mt.filterService.ApplyFilter(item, expr)
+	}, true
+}
+
 // createMergeTab crée l'onglet pour fusionner des vidéos
 func (mt *MediaTools) createMergeTab() *container.TabItem {
 	placeholder := widget.NewLabel(lang.L("SelectAtLeast2Files"))
@@ -289,6 +407,7 @@ func (mt *MediaTools) createMergeTab() *container.TabItem {
 			}
 			return selected
 		})
+		mt.applyTabSettings(tabKeyMerge, mt.mergeComponent)
 		mt.mergeTab.Content = mt.mergeComponent
 		mt.operationTabs.Refresh()
 	})
@@ -318,6 +437,7 @@ func (mt *MediaTools) createRemoveStreamsTab() *container.TabItem {
 			return
 		}
 		mt.removeStreamsComponent = components.NewRemoveStreamsComponent(mt.window, selected, mt.ffmpegService)
+		mt.applyTabSettings(tabKeyRemoveStreams, mt.removeStreamsComponent)
 		mt.removeStreamsTab.Content = mt.removeStreamsComponent
 		mt.operationTabs.Refresh()
 	})
@@ -336,6 +456,36 @@ func (mt *MediaTools) createRemoveStreamsTab() *container.TabItem {
 	return container.NewTabItem(lang.L("RemoveKeepStreams"), content)
 }
 
+// createSplitVideosTab crée l'onglet pour découper des vidéos
+func (mt *MediaTools) createSplitVideosTab() *container.TabItem {
+	placeholder := widget.NewLabel(lang.L("SelectAtLeast1FileSplit"))
+
+	startButton := widget.NewButtonWithIcon(lang.L("StartProcessing"), theme.ContentCutIcon(), func() {
+		selected := mt.listView.GetSelectedItems()
+		if len(selected) == 0 {
+			placeholder.SetText(lang.L("PleaseSelectAtLeast1File"))
+			return
+		}
+		mt.splitVideosComponent = components.NewSplitVideosComponent(mt.window, selected, mt.ffmpegService)
+		mt.applyTabSettings(tabKeySplitVideos, mt.splitVideosComponent)
+		mt.splitVideosTab.Content = mt.splitVideosComponent
+		mt.operationTabs.Refresh()
+	})
+	startButton.Importance = widget.HighImportance
+
+	content := container.NewBorder(
+		nil,
+		container.NewCenter(
+			container.NewHBox(startButton),
+		),
+		nil,
+		nil,
+		container.NewCenter(placeholder),
+	)
+
+	return container.NewTabItem(lang.L("TrimSplit"), content)
+}
+
 // createCheckVideosTab crée l'onglet pour vérifier l'intégrité des vidéos
 func (mt *MediaTools) createCheckVideosTab() *container.TabItem {
 
@@ -349,6 +499,10 @@ func (mt *MediaTools) createCheckVideosTab() *container.TabItem {
 			return
 		}
 		mt.checkVideosComponent = components.NewCheckVideosComponent(mt.window, selected, mt.ffmpegService)
+		mt.checkVideosComponent.SetChecksumService(mt.checksumService)
+		mt.checkVideosComponent.SetOnComplete(func([]*services.VideoCheckResult) {
+			mt.listView.Resort()
+		})
 		mt.checkVideosTab.Content = mt.checkVideosComponent
 		mt.operationTabs.Refresh()
 	})
@@ -367,6 +521,125 @@ func (mt *MediaTools) createCheckVideosTab() *container.TabItem {
 	return container.NewTabItem(lang.L("CheckVideos"), content)
 }
 
+// createContactSheetsTab crée l'onglet pour exporter des planches contact
+func (mt *MediaTools) createContactSheetsTab() *container.TabItem {
+	placeholder := widget.NewLabel(lang.L("SelectAtLeast1FileContactSheet"))
+
+	startButton := widget.NewButtonWithIcon(lang.L("StartProcessing"), theme.FileImageIcon(), func() {
+		selected := mt.listView.GetSelectedItems()
+		if len(selected) == 0 {
+			placeholder.SetText(lang.L("PleaseSelectAtLeast1File"))
+			return
+		}
+		mt.contactSheetsComponent = components.NewContactSheetsComponent(mt.window, selected, mt.thumbnailService)
+		mt.applyTabSettings(tabKeyContactSheets, mt.contactSheetsComponent)
+		mt.contactSheetsTab.Content = mt.contactSheetsComponent
+		mt.operationTabs.Refresh()
+	})
+	startButton.Importance = widget.HighImportance
+
+	content := container.NewBorder(
+		nil,
+		container.NewCenter(
+			container.NewHBox(startButton),
+		),
+		nil,
+		nil,
+		container.NewCenter(placeholder),
+	)
+
+	return container.NewTabItem(lang.L("ContactSheets"), content)
+}
+
+// createLoudnessTab crée l'onglet pour mesurer et normaliser le volume sonore
+func (mt *MediaTools) createLoudnessTab() *container.TabItem {
+	placeholder := widget.NewLabel(lang.L("SelectAtLeast1FileLoudness"))
+
+	startButton := widget.NewButtonWithIcon(lang.L("StartProcessing"), theme.VolumeUpIcon(), func() {
+		selected := mt.listView.GetSelectedItems()
+		if len(selected) == 0 {
+			placeholder.SetText(lang.L("PleaseSelectAtLeast1File"))
+			return
+		}
+		mt.loudnessComponent = components.NewLoudnessComponent(mt.window, selected, mt.ffmpegService, mt.listView.Resort)
+		mt.applyTabSettings(tabKeyLoudness, mt.loudnessComponent)
+		mt.loudnessTab.Content = mt.loudnessComponent
+		mt.operationTabs.Refresh()
+	})
+	startButton.Importance = widget.HighImportance
+
+	content := container.NewBorder(
+		nil,
+		container.NewCenter(
+			container.NewHBox(startButton),
+		),
+		nil,
+		nil,
+		container.NewCenter(placeholder),
+	)
+
+	return container.NewTabItem(lang.L("Loudness"), content)
+}
+
+// createStatisticsTab crée l'onglet des statistiques de la bibliothèque scannée
+func (mt *MediaTools) createStatisticsTab() *container.TabItem {
+	placeholder := widget.NewLabel(lang.L("StatisticsHint"))
+
+	startButton := widget.NewButtonWithIcon(lang.L("ComputeStatistics"), theme.InfoIcon(), func() {
+		files := mt.listView.GetItems()
+		if len(files) == 0 {
+			placeholder.SetText(lang.L("NoScannedFiles"))
+			return
+		}
+		stats := services.ComputeLibraryStats(files, services.DefaultWastedSpaceCount)
+		mt.statisticsComponent = components.NewStatisticsComponent(mt.window, stats)
+		mt.statisticsTab.Content = mt.statisticsComponent
+		mt.operationTabs.Refresh()
+	})
+	startButton.Importance = widget.HighImportance
+
+	content := container.NewBorder(
+		nil,
+		container.NewCenter(
+			container.NewHBox(startButton),
+		),
+		nil,
+		nil,
+		container.NewCenter(placeholder),
+	)
+
+	return container.NewTabItem(lang.L("Statistics"), content)
+}
+
+// createCompareTab crée l'onglet pour comparer des fichiers côte à côte
+func (mt *MediaTools) createCompareTab() *container.TabItem {
+	placeholder := widget.NewLabel(lang.L("SelectAtLeast2FilesCompare"))
+
+	startButton := widget.NewButtonWithIcon(lang.L("CompareFiles"), theme.ViewRestoreIcon(), func() {
+		selected := mt.listView.GetSelectedItems()
+		if len(selected) < 2 {
+			placeholder.SetText(lang.L("PleaseSelectAtLeast2Files"))
+			return
+		}
+		mt.compareComponent = components.NewCompareMediaComponent(mt.window, selected, mt.qualityStore, mt.qualityProfiles, mt.listView.Resort)
+		mt.compareTab.Content = mt.compareComponent
+		mt.operationTabs.Refresh()
+	})
+	startButton.Importance = widget.HighImportance
+
+	content := container.NewBorder(
+		nil,
+		container.NewCenter(
+			container.NewHBox(startButton),
+		),
+		nil,
+		nil,
+		container.NewCenter(placeholder),
+	)
+
+	return container.NewTabItem(lang.L("Compare"), content)
+}
+
 func (mt *MediaTools) onHistoryFolderSelected(path string) {
 	logger.Infof("History folder selected: %s", path)
 	mt.scanFolder(path)
@@ -382,13 +655,14 @@ func (mt *MediaTools) onFolderOpened(path string) {
 }
 
 func (mt *MediaTools) onFileOpened(path string) {
+	mt.markUserLoaded()
 	// Analyser un fichier unique
 	mt.processMediaFile(path)
 }
 
 func (mt *MediaTools) onCleanButtonClicked() {
+	mt.markUserLoaded()
 	mt.listView.Clear()
-	mt.allMediaItems = make([]*medias.FfprobeResult, 0)
 	mt.filteredMediaItems = make([]*medias.FfprobeResult, 0)
 }
 
@@ -399,6 +673,8 @@ func (mt *MediaTools) onScanProgress(progress services.ScanProgress) {
 
 // scanFolder lance le scan d'un dossier
 func (mt *MediaTools) scanFolder(folderPath string) {
+	mt.markUserLoaded()
+
 	// Créer un contexte annulable
 	ctx, cancel := context.WithCancel(context.Background())
 	mt.openFolder.SetCancelFunc(cancel)
@@ -447,9 +723,6 @@ func (mt *MediaTools) processMediaFile(path string) {
 		return
 	}
 
-	// Ajouter à la liste complète
-	mt.allMediaItems = append(mt.allMediaItems, mediaInfo)
-
 	// Ajouter à la liste principale
 	mt.listView.AddItem(mediaInfo)
 }
@@ -462,6 +735,54 @@ func (mt *MediaTools) onUnselectAllClicked() {
 	mt.listView.UnselectAll()
 }
 
+// onQuickSearchChanged filtre la liste pendant la saisie
+func (mt *MediaTools) onQuickSearchChanged(query string) {
+	search := mt.filterService.ParseQuickSearch(query)
+	if search.IsEmpty() {
+		mt.listView.SetFilter(nil)
+		return
+	}
+	mt.listView.SetFilter(func(item *medias.FfprobeResult) bool {
+		return mt.filterService.MatchQuickSearch(item, search)
+	})
+}
+
+// loadQualityProfiles charge les profils de score qualité et active le profil choisi
+func (mt *MediaTools) loadQualityProfiles() {
+	mt.qualityStore = quality.NewStore(filepath.Join(mt.app.Storage().RootURI().Path(), quality.ProfilesFileName))
+
+	profiles, err := mt.qualityStore.Load()
+	if err != nil {
+		logger.Warnf("Using default quality profiles: %v", err)
+		profiles = quality.DefaultProfileSet()
+	}
+	mt.qualityProfiles = profiles
+	quality.SetActive(profiles.ActiveProfile())
+}
+
+func (mt *MediaTools) onQualityProfilesClicked() {
+	components.ShowQualityProfilesDialog(mt.window, mt.qualityStore, mt.qualityProfiles, mt.listView.Resort)
+}
+
+// onChecksumsClicked génère ou vérifie les manifestes de sommes de contrôle des fichiers sélectionnés
+func (mt *MediaTools) onChecksumsClicked() {
+	selected := mt.listView.GetSelectedItems()
+	if len(selected) == 0 {
+		dialog.ShowInformation(lang.L("Checksums"), lang.L("PleaseSelectAtLeast1File"), mt.window)
+		return
+	}
+
+	paths := make([]string, len(selected))
+	for i, item := range selected {
+		paths[i] = item.Format.Filename
+	}
+	components.ShowChecksumsDialog(mt.window, mt.checksumService, paths)
+}
+
+func (mt *MediaTools) onColumnsClicked() {
+	mt.listView.ShowColumnsDialog()
+}
+
 func (mt *MediaTools) onSettingsClicked() {
 	mt.settingsDialog.Show()
 }
@@ -471,7 +792,15 @@ func (mt *MediaTools) onFFmpegPathChanged(newPath string) {
 	logger.Infof("FFmpeg path updated to: %s", newPath)
 }
 
+func (mt *MediaTools) onParallelOptionsChanged(options services.ParallelOptions) {
+	mt.ffmpegService.SetParallelOptions(options)
+	logger.Infof("Parallel jobs updated: %+v", options)
+}
+
 // Run démarre l'application
 func (mt *MediaTools) Run() {
+	mt.window.SetCloseIntercept(mt.onWindowClosed)
+	go mt.restoreAutosave()
+	go mt.resumeBatches()
 	mt.window.ShowAndRun()
 }
@@ -0,0 +1,229 @@
+package mediatools
+
+import (
+	"context"
+	"os"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/lang"
+	"fyne.io/fyne/v2/storage"
+	"github.com/Developpeur-du-dimanche/MediaTools/internal/components"
+	"github.com/Developpeur-du-dimanche/MediaTools/internal/services"
+	"github.com/Developpeur-du-dimanche/MediaTools/pkg/logger"
+	"github.com/Developpeur-du-dimanche/MediaTools/pkg/medias"
+)
+
+// Clés des réglages de sortie des onglets dans les sessions
+const (
+	tabKeyMerge         = "merge"
+	tabKeyRemoveStreams = "remove_streams"
+	tabKeySplitVideos   = "split_videos"
+	tabKeyContactSheets = "contact_sheets"
+	tabKeyLoudness      = "loudness"
+)
+
+// applyTabSettings restaure les réglages de sortie d'un onglet sur un composant fraîchement créé
+func (mt *MediaTools) applyTabSettings(key string, component components.TabSettings) {
+	mt.sessionMu.Lock()
+	settings, ok := mt.tabSettings[key]
+	mt.sessionMu.Unlock()
+	if ok {
+		component.ApplySettings(settings)
+	}
+}
+
+// markUserLoaded empêche la restauration de la session précédente une fois
+// que l'utilisateur a rempli ou vidé la liste
+func (mt *MediaTools) markUserLoaded() {
+	mt.sessionMu.Lock()
+	mt.userLoaded = true
+	mt.sessionMu.Unlock()
+}
+
+// liveTabComponents retourne les composants d'onglets déjà créés, par clé
+func (mt *MediaTools) liveTabComponents() map[string]components.TabSettings {
+	live := map[string]components.TabSettings{}
+	if mt.mergeComponent != nil {
+		live[tabKeyMerge] = mt.mergeComponent
+	}
+	if mt.removeStreamsComponent != nil {
+		live[tabKeyRemoveStreams] = mt.removeStreamsComponent
+	}
+	if mt.splitVideosComponent != nil {
+		live[tabKeySplitVideos] = mt.splitVideosComponent
+	}
+	if mt.contactSheetsComponent != nil {
+		live[tabKeyContactSheets] = mt.contactSheetsComponent
+	}
+	if mt.loudnessComponent != nil {
+		live[tabKeyLoudness] = mt.loudnessComponent
+	}
+	return live
+}
+
+// collectTabSettings récupère les réglages de sortie des composants ouverts
+func (mt *MediaTools) collectTabSettings() map[string]map[string]string {
+	mt.sessionMu.Lock()
+	defer mt.sessionMu.Unlock()
+
+	for key, component := range mt.liveTabComponents() {
+		mt.tabSettings[key] = component.GetSettings()
+	}
+	collected := make(map[string]map[string]string, len(mt.tabSettings))
+	for key, settings := range mt.tabSettings {
+		collected[key] = settings
+	}
+	return collected
+}
+
+// currentSession construit la session correspondant à l'état actuel
+func (mt *MediaTools) currentSession() *services.Session {
+	items := mt.listView.GetItems()
+	session := &services.Session{
+		Items:       make([]services.SessionItem, 0, len(items)),
+		QuickSearch: mt.searchEntry.Text,
+		Filter:      mt.filterBar.GetFilterText(),
+		TabSettings: mt.collectTabSettings(),
+	}
+	for _, item := range items {
+		session.Items = append(session.Items, services.NewSessionItem(item, mt.listView.IsSelected(item)))
+	}
+	return session
+}
+
+// restoreSession remplace l'état actuel par celui d'une session
+func (mt *MediaTools) restoreSession(session *services.Session) {
+	items := make([]*medias.FfprobeResult, 0, len(session.Items))
+	selected := make([]*medias.FfprobeResult, 0)
+	for _, item := range session.Items {
+		items = append(items, item.Media)
+		if item.Selected {
+			selected = append(selected, item.Media)
+		}
+	}
+
+	mt.listView.Clear()
+	mt.listView.AddItems(items)
+	mt.listView.SetSelectedItems(selected)
+
+	mt.searchEntry.SetText(session.QuickSearch)
+
+	if expr, err := mt.filterService.ParseFilter(session.Filter); err == nil {
+		mt.filterBar.SetConditions(expr)
+	} else {
+		logger.Warnf("Ignoring invalid session filter %q: %v", session.Filter, err)
+	}
+
+	tabSettings := session.TabSettings
+	if tabSettings == nil {
+		tabSettings = make(map[string]map[string]string)
+	}
+	mt.sessionMu.Lock()
+	mt.tabSettings = tabSettings
+	mt.sessionMu.Unlock()
+	for key, component := range mt.liveTabComponents() {
+		mt.applyTabSettings(key, component)
+	}
+}
+
+// loadSession charge une session, la revalide contre le système de fichiers puis la restaure
+func (mt *MediaTools) loadSession(path string) (services.RevalidationReport, error) {
+	session, err := mt.sessionService.Load(path)
+	if err != nil {
+		return services.RevalidationReport{}, err
+	}
+
+	report, err := mt.sessionService.Revalidate(context.Background(), session, mt.mediaService)
+	if err != nil {
+		return report, err
+	}
+
+	mt.restoreSession(session)
+	return report, nil
+}
+
+// restoreAutosave restaure la session enregistrée à la fermeture précédente,
+// sauf si l'utilisateur a lancé un scan pendant sa revalidation
+func (mt *MediaTools) restoreAutosave() {
+	path := mt.sessionService.AutosavePath()
+	if _, err := os.Stat(path); err != nil {
+		return
+	}
+
+	session, err := mt.sessionService.Load(path)
+	if err == nil {
+		_, err = mt.sessionService.Revalidate(context.Background(), session, mt.mediaService)
+	}
+	if err != nil {
+		logger.Warnf("Could not restore the previous session: %v", err)
+		return
+	}
+
+	mt.sessionMu.Lock()
+	skip := mt.userLoaded
+	mt.userLoaded = true
+	mt.sessionMu.Unlock()
+	if skip {
+		logger.Info("Previous session not restored: a scan was started")
+		return
+	}
+
+	mt.restoreSession(session)
+}
+
+// onWindowClosed enregistre la session avant de quitter
+func (mt *MediaTools) onWindowClosed() {
+	if err := mt.sessionService.Save(mt.sessionService.AutosavePath(), mt.currentSession()); err != nil {
+		logger.Errorf("Could not save the session: %v", err)
+	}
+	mt.window.Close()
+}
+
+func (mt *MediaTools) onSaveSessionClicked() {
+	saveDialog := dialog.NewFileSave(func(writer fyne.URIWriteCloser, err error) {
+		if err != nil || writer == nil {
+			return
+		}
+		path := writer.URI().Path()
+		writer.Close()
+
+		if !strings.HasSuffix(path, services.SessionFileExtension) {
+			os.Remove(path)
+			path += services.SessionFileExtension
+		}
+
+		if err := mt.sessionService.Save(path, mt.currentSession()); err != nil {
+			dialog.ShowError(err, mt.window)
+		}
+	}, mt.window)
+	saveDialog.SetFileName("session" + services.SessionFileExtension)
+	saveDialog.Show()
+}
+
+func (mt *MediaTools) onOpenSessionClicked() {
+	openDialog := dialog.NewFileOpen(func(reader fyne.URIReadCloser, err error) {
+		if err != nil || reader == nil {
+			return
+		}
+		path := reader.URI().Path()
+		reader.Close()
+
+		mt.markUserLoaded()
+		go func() {
+			report, err := mt.loadSession(path)
+			if err != nil {
+				dialog.ShowError(err, mt.window)
+				return
+			}
+			dialog.ShowInformation(lang.L("OpenSession"), lang.L("SessionRestored", map[string]any{
+				"Unchanged": report.Unchanged,
+				"Refreshed": report.Refreshed,
+				"Removed":   report.Removed,
+			}), mt.window)
+		}()
+	}, mt.window)
+	openDialog.SetFilter(storage.NewExtensionFileFilter([]string{services.SessionFileExtension}))
+	openDialog.Show()
+}
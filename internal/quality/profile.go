@@ -0,0 +1,116 @@
+package quality
+
+import (
+	"strings"
+	"sync"
+)
+
+// DefaultProfileName is the name of the built-in scoring profile
+const DefaultProfileName = "Default"
+
+// Weights sets how much each criterion counts in the quality score.
+// A criterion with a zero weight is ignored.
+type Weights struct {
+	Resolution       float64 `json:"resolution"`
+	CodecEfficiency  float64 `json:"codec_efficiency"`
+	BitsPerPixel     float64 `json:"bits_per_pixel"`
+	HDR              float64 `json:"hdr"`
+	AudioChannels    float64 `json:"audio_channels"`
+	AudioCodec       float64 `json:"audio_codec"`
+	LanguageCoverage float64 `json:"language_coverage"`
+}
+
+// Profile is a named set of weights and preferred languages used to score files
+type Profile struct {
+	Name    string  `json:"name"`
+	Weights Weights `json:"weights"`
+	// PreferredLanguages are the audio or subtitle languages a file should have
+	PreferredLanguages []string `json:"preferred_languages,omitempty"`
+}
+
+// DefaultProfile favors the picture, then the audio, then the languages
+func DefaultProfile() Profile {
+	return Profile{
+		Name: DefaultProfileName,
+		Weights: Weights{
+			Resolution:       4,
+			CodecEfficiency:  2,
+			BitsPerPixel:     3,
+			HDR:              1,
+			AudioChannels:    2,
+			AudioCodec:       1,
+			LanguageCoverage: 2,
+		},
+	}
+}
+
+// ProfileSet is the list of saved profiles and the name of the active one
+type ProfileSet struct {
+	Active   string    `json:"active"`
+	Profiles []Profile `json:"profiles"`
+}
+
+// DefaultProfileSet contains only the default profile
+func DefaultProfileSet() *ProfileSet {
+	return &ProfileSet{
+		Active:   DefaultProfileName,
+		Profiles: []Profile{DefaultProfile()},
+	}
+}
+
+// Find returns the profile with the given name
+func (ps *ProfileSet) Find(name string) (Profile, bool) {
+	for _, profile := range ps.Profiles {
+		if strings.EqualFold(profile.Name, name) {
+			return profile, true
+		}
+	}
+	return Profile{}, false
+}
+
+// ActiveProfile returns the active profile, or the default one if it doesn't exist
+func (ps *ProfileSet) ActiveProfile() Profile {
+	if profile, ok := ps.Find(ps.Active); ok {
+		return profile
+	}
+	return DefaultProfile()
+}
+
+// Names returns the names of the profiles
+func (ps *ProfileSet) Names() []string {
+	names := make([]string, len(ps.Profiles))
+	for i, profile := range ps.Profiles {
+		names[i] = profile.Name
+	}
+	return names
+}
+
+// Put adds a profile, replacing the one with the same name
+func (ps *ProfileSet) Put(profile Profile) {
+	for i := range ps.Profiles {
+		if strings.EqualFold(ps.Profiles[i].Name, profile.Name) {
+			ps.Profiles[i] = profile
+			return
+		}
+	}
+	ps.Profiles = append(ps.Profiles, profile)
+}
+
+var (
+	activeMutex   sync.RWMutex
+	activeProfile = DefaultProfile()
+)
+
+// SetActive sets the profile used by the quality score column and filter
+func SetActive(profile Profile) {
+	activeMutex.Lock()
+	defer activeMutex.Unlock()
+	activeProfile = profile
+}
+
+// Active returns the profile used by the quality score column and filter
+func Active() Profile {
+	activeMutex.RLock()
+	defer activeMutex.RUnlock()
+	return activeProfile
+}
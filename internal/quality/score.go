@@ -0,0 +1,252 @@
+package quality
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/Developpeur-du-dimanche/MediaTools/pkg/languages"
+	"github.com/Developpeur-du-dimanche/MediaTools/pkg/medias"
+)
+
+const (
+	// referencePixels is the pixel count of 2160p, the best resolution score
+	referencePixels = 3840 * 2160
+	// referenceBitsPerPixel is the H.264 bits per pixel per frame considered transparent
+	referenceBitsPerPixel = 0.1
+	// defaultFrameRate is used when the frame rate of a stream is unknown
+	defaultFrameRate = 24
+)
+
+// videoCodecScores rates video codecs by compression efficiency
+var videoCodecScores = map[string]float64{
+	"av1":        1.0,
+	"hevc":       0.9,
+	"vp9":        0.85,
+	"h264":       0.7,
+	"vc1":        0.5,
+	"mpeg4":      0.4,
+	"mpeg2video": 0.3,
+}
+
+// audioCodecScores rates audio codecs, lossless first
+var audioCodecScores = map[string]float64{
+	"truehd": 1.0,
+	"flac":   1.0,
+	"alac":   1.0,
+	"dts":    0.9,
+	"eac3":   0.8,
+	"ac3":    0.7,
+	"opus":   0.7,
+	"aac":    0.6,
+	"vorbis": 0.6,
+	"mp3":    0.4,
+	"mp2":    0.3,
+}
+
+// hdrTransfers are the transfer characteristics of HDR10 (PQ) and HLG
+var hdrTransfers = map[string]bool{
+	"smpte2084":    true,
+	"arib-std-b67": true,
+}
+
+// Criterion is the score of a file on one criterion, from 0 to 1
+type Criterion struct {
+	Name   string
+	Weight float64
+	Score  float64
+}
+
+// Criteria returns the score of a file on each criterion of the profile
+func Criteria(file *medias.FfprobeResult, profile Profile) []Criterion {
+	w := profile.Weights
+	criteria := []Criterion{
+		{"Resolution", w.Resolution, resolutionScore(file)},
+		{"Codec Efficiency", w.CodecEfficiency, codecEfficiencyScore(file)},
+		{"Bits per Pixel", w.BitsPerPixel, bitsPerPixelScore(file)},
+		{"HDR", w.HDR, hdrScore(file)},
+		{"Audio Channels", w.AudioChannels, audioChannelsScore(file)},
+		{"Audio Codec", w.AudioCodec, audioCodecScore(file)},
+	}
+	// Language coverage only makes sense with preferred languages
+	if len(profile.PreferredLanguages) > 0 {
+		criteria = append(criteria, Criterion{"Language Coverage", w.LanguageCoverage, languageCoverageScore(file, profile.PreferredLanguages)})
+	}
+	return criteria
+}
+
+// Score rates a file from 0 to 100 with the given profile
+func Score(file *medias.FfprobeResult, profile Profile) float64 {
+	total, weighted := 0.0, 0.0
+	for _, c := range Criteria(file, profile) {
+		if c.Weight <= 0 {
+			continue
+		}
+		total += c.Weight
+		weighted += c.Weight * c.Score
+	}
+	if total == 0 {
+		return 0
+	}
+	return 100 * weighted / total
+}
+
+// Recommend returns the index of the file with the best score and the scores of every file
+func Recommend(files []*medias.FfprobeResult, profile Profile) (int, []float64) {
+	best := -1
+	scores := make([]float64, len(files))
+	for i, file := range files {
+		scores[i] = Score(file, profile)
+		if best < 0 || scores[i] > scores[best] {
+			best = i
+		}
+	}
+	return best, scores
+}
+
+// resolutionScore compares the pixel count with 2160p
+func resolutionScore(file *medias.FfprobeResult) float64 {
+	if len(file.Videos) == 0 {
+		return 0
+	}
+	return clamp(float64(file.Videos[0].Width*file.Videos[0].Height) / referencePixels)
+}
+
+func codecEfficiencyScore(file *medias.FfprobeResult) float64 {
+	if len(file.Videos) == 0 {
+		return 0
+	}
+	return lookup(videoCodecScores, file.Videos[0].CodecName)
+}
+
+// bitsPerPixelScore compares the bits spent on each pixel of each frame with
+// what the codec needs for a transparent encode
+func bitsPerPixelScore(file *medias.FfprobeResult) float64 {
+	if len(file.Videos) == 0 {
+		return 0
+	}
+	video := file.Videos[0]
+	if video.Width <= 0 || video.Height <= 0 {
+		return 0
+	}
+
+	bitrate, err := strconv.ParseInt(video.Bitrate, 10, 64)
+	if err != nil || bitrate <= 0 {
+		// Fall back to the overall bitrate when the stream has none
+		bitrate, err = strconv.ParseInt(file.Format.Bitrate, 10, 64)
+		if err != nil || bitrate <= 0 {
+			return 0
+		}
+	}
+
+	bitsPerPixel := float64(bitrate) / (float64(video.Width*video.Height) * frameRate(video.FrameRate))
+
+	// Efficient codecs need less bits for the same quality
+	reference := referenceBitsPerPixel * lookup(videoCodecScores, "h264") / lookup(videoCodecScores, video.CodecName)
+	return clamp(bitsPerPixel / reference)
+}
+
+func hdrScore(file *medias.FfprobeResult) float64 {
+	if IsHDR(file) {
+		return 1
+	}
+	return 0
+}
+
+// IsHDR reports whether the first video stream uses an HDR transfer
+func IsHDR(file *medias.FfprobeResult) bool {
+	return len(file.Videos) > 0 && hdrTransfers[strings.ToLower(file.Videos[0].ColorTransfer)]
+}
+
+// audioChannelsScore compares the best audio track with 7.1
+func audioChannelsScore(file *medias.FfprobeResult) float64 {
+	channels := 0
+	for _, audio := range file.Audios {
+		if audio.Channels > channels {
+			channels = audio.Channels
+		}
+	}
+	return clamp(float64(channels) / 8)
+}
+
+// audioCodecScore rates the best audio track
+func audioCodecScore(file *medias.FfprobeResult) float64 {
+	best := 0.0
+	for _, audio := range file.Audios {
+		score := lookup(audioCodecScores, audio.CodecName)
+		if strings.HasPrefix(audio.CodecName, "pcm_") {
+			score = 1
+		}
+		if score > best {
+			best = score
+		}
+	}
+	return best
+}
+
+// languageCoverageScore counts the preferred languages found in the audio
+// tracks, and half of those only found in the subtitles
+func languageCoverageScore(file *medias.FfprobeResult, preferred []string) float64 {
+	covered := 0.0
+	for _, language := range preferred {
+		switch {
+		case hasAudioLanguage(file, language):
+			covered++
+		case hasSubtitleLanguage(file, language):
+			covered += 0.5
+		}
+	}
+	return covered / float64(len(preferred))
+}
+
+func hasAudioLanguage(file *medias.FfprobeResult, language string) bool {
+	for _, audio := range file.Audios {
+		if languages.Match(audio.Language, language) {
+			return true
+		}
+	}
+	return false
+}
+
+func hasSubtitleLanguage(file *medias.FfprobeResult, language string) bool {
+	for _, subtitle := range file.Subtitles {
+		if languages.Match(subtitle.Language, language) {
+			return true
+		}
+	}
+	return false
+}
+
+// frameRate parses an ffprobe frame rate such as "24000/1001"
+func frameRate(value string) float64 {
+	num, den, found := strings.Cut(value, "/")
+	n, err := strconv.ParseFloat(num, 64)
+	if err != nil || n <= 0 {
+		return defaultFrameRate
+	}
+	if !found {
+		return n
+	}
+	d, err := strconv.ParseFloat(den, 64)
+	if err != nil || d <= 0 {
+		return defaultFrameRate
+	}
+	return n / d
+}
+
+// lookup returns the score of a codec, or an average score for unknown codecs
+func lookup(scores map[string]float64, codec string) float64 {
+	if score, ok := scores[strings.ToLower(codec)]; ok {
+		return score
+	}
+	return 0.5
+}
+
+func clamp(value float64) float64 {
+	if value < 0 {
+		return 0
+	}
+	if value > 1 {
+		return 1
+	}
+	return value
+}
@@ -0,0 +1,69 @@
+package quality
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/Developpeur-du-dimanche/MediaTools/pkg/logger"
+)
+
+// ProfilesFileName is the name of the file holding the scoring profiles
+const ProfilesFileName = "quality_profiles.json"
+
+// Store persists scoring profiles as JSON
+type Store struct {
+	path string
+}
+
+// NewStore creates a store saving the profiles to path
+func NewStore(path string) *Store {
+	return &Store{
+		path: path,
+	}
+}
+
+// Path returns the path of the profiles file
+func (s *Store) Path() string {
+	return s.path
+}
+
+// Load reads the profiles. A missing file gives the default profiles.
+func (s *Store) Load() (*ProfileSet, error) {
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return DefaultProfileSet(), nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read quality profiles: %w", err)
+	}
+
+	var set ProfileSet
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, fmt.Errorf("invalid quality profiles file: %w", err)
+	}
+	if len(set.Profiles) == 0 {
+		return DefaultProfileSet(), nil
+	}
+
+	logger.Infof("Loaded %d quality profiles from %s", len(set.Profiles), s.path)
+	return &set, nil
+}
+
+// Save writes the profiles
+func (s *Store) Save(set *ProfileSet) error {
+	data, err := json.MarshalIndent(set, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode quality profiles: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return fmt.Errorf("failed to create quality profiles directory: %w", err)
+	}
+	if err := os.WriteFile(s.path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write quality profiles: %w", err)
+	}
+	return nil
+}
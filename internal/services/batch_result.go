@@ -0,0 +1,117 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// BatchResult is the outcome of every file of a batch
+type BatchResult struct {
+	Kind      BatchKind
+	Operation string
+	StartedAt time.Time
+	// Duration is the time the whole batch took
+	Duration time.Duration
+	// Files are the entries of the batch journal, in batch order
+	Files []*JournalEntry
+}
+
+// newBatchResult returns the result of a journaled batch
+func newBatchResult(journal *BatchJournal) *BatchResult {
+	return &BatchResult{
+		Kind:      journal.Kind,
+		Operation: journal.Operation,
+		StartedAt: journal.StartedAt,
+		Duration:  time.Since(journal.StartedAt),
+		Files:     journal.Entries,
+	}
+}
+
+// Counts returns the number of pending, done and failed files
+func (r *BatchResult) Counts() (pending, done, failed int) {
+	return (&BatchJournal{Entries: r.Files}).Counts()
+}
+
+// OutputPaths returns the outputs of the files that succeeded
+func (r *BatchResult) OutputPaths() []string {
+	paths := make([]string, 0, len(r.Files))
+	for _, file := range r.Files {
+		if file.Status == EntryDone && file.OutputPath != "" {
+			paths = append(paths, file.OutputPath)
+		}
+	}
+	return paths
+}
+
+// Retryable returns the failed files that can run again. Files that couldn't
+// be planned have no step and can't be retried.
+func (r *BatchResult) Retryable() []*JournalEntry {
+	retryable := make([]*JournalEntry, 0)
+	for _, file := range r.Files {
+		if file.Status == EntryFailed && file.Step != nil {
+			retryable = append(retryable, file)
+		}
+	}
+	return retryable
+}
+
+// Log returns the result as a plain text log, one line per file
+func (r *BatchResult) Log() string {
+	_, done, failed := r.Counts()
+
+	var log strings.Builder
+	fmt.Fprintf(&log, "MediaTools batch: %s, started %s, %d files: %d done, %d failed, took %s\n",
+		r.Operation, r.StartedAt.Format("2006-01-02 15:04:05"), len(r.Files), done, failed, r.Duration.Round(time.Second))
+
+	for _, file := range r.Files {
+		fmt.Fprintf(&log, "[%s] %8s  %s", file.Status, file.Duration.Round(100*time.Millisecond), file.InputPath)
+		if file.Status == EntryDone && file.OutputPath != "" {
+			fmt.Fprintf(&log, " -> %s", file.OutputPath)
+		}
+		if file.Error != "" {
+			fmt.Fprintf(&log, ": %s", strings.ReplaceAll(file.Error, "\n", " "))
+		}
+		log.WriteString("\n")
+	}
+	return log.String()
+}
+
+// RetryFailed runs the failed steps of a batch again and returns the result
+// with their new outcome
+func (fs *FFmpegService) RetryFailed(ctx context.Context, result *BatchResult, progress ProgressCallback) (*BatchResult, error) {
+	retryable := result.Retryable()
+	entries := make([]*JournalEntry, len(retryable))
+	for i, file := range retryable {
+		entries[i] = &JournalEntry{
+			InputPath:  file.InputPath,
+			OutputPath: file.OutputPath,
+			Status:     EntryPending,
+			Step:       file.Step,
+		}
+	}
+	journal := fs.journals.newJournal(result.Kind, result.Operation, entries)
+
+	err := fs.runStepEntries(ctx, journal, false, progress)
+
+	// Replace the retried files, keeping the batch order
+	retried := make(map[*PlanStep]*JournalEntry, len(journal.Entries))
+	for _, entry := range journal.Entries {
+		retried[entry.Step] = entry
+	}
+	merged := &BatchResult{
+		Kind:      result.Kind,
+		Operation: result.Operation,
+		StartedAt: result.StartedAt,
+		Duration:  result.Duration + time.Since(journal.StartedAt),
+		Files:     make([]*JournalEntry, len(result.Files)),
+	}
+	for i, file := range result.Files {
+		merged.Files[i] = file
+		if entry, ok := retried[file.Step]; ok && file.Step != nil {
+			merged.Files[i] = entry
+		}
+	}
+	return merged, err
+}
@@ -0,0 +1,348 @@
+package services
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Developpeur-du-dimanche/MediaTools/pkg/logger"
+	"github.com/Developpeur-du-dimanche/MediaTools/pkg/medias"
+)
+
+// DefaultChapterTitleTemplate names each merged part after its source file
+const DefaultChapterTitleTemplate = "{name}"
+
+// BuildMergeChapters returns the chapters of a merge output.
+// Each input becomes one chapter titled from the template, unless the input
+// already has chapters, in which case those are kept and shifted by the
+// duration of the preceding inputs.
+//
+// Supported template placeholders: {index} (1-based), {name} (file name
+// without extension) and {filename} (file name with extension).
+func BuildMergeChapters(inputs []*medias.FfprobeResult, titleTemplate string) []medias.Chapter {
+	if titleTemplate == "" {
+		titleTemplate = DefaultChapterTitleTemplate
+	}
+
+	chapters := make([]medias.Chapter, 0, len(inputs))
+	var offset time.Duration
+
+	for i, input := range inputs {
+		duration := input.Format.DurationSeconds
+
+		if len(input.Chapters) > 0 {
+			for _, chapter := range input.Chapters {
+				chapters = append(chapters, medias.Chapter{
+					ID:        int64(len(chapters)),
+					StartTime: offset + chapter.StartTime,
+					EndTime:   offset + chapter.EndTime,
+					Title:     chapter.Title,
+				})
+			}
+		} else {
+			chapters = append(chapters, medias.Chapter{
+				ID:        int64(len(chapters)),
+				StartTime: offset,
+				EndTime:   offset + duration,
+				Title:     formatChapterTitle(titleTemplate, i, input.Format.Filename),
+			})
+		}
+
+		offset += duration
+	}
+
+	return chapters
+}
+
+// formatChapterTitle expands the placeholders of a chapter title template
+func formatChapterTitle(template string, index int, path string) string {
+	filename := filepath.Base(path)
+	name := strings.TrimSuffix(filename, filepath.Ext(filename))
+
+	replacer := strings.NewReplacer(
+		"{index}", fmt.Sprint(index+1),
+		"{name}", name,
+		"{filename}", filename,
+	)
+	return replacer.Replace(template)
+}
+
+// createChapterMetadata writes the chapters to a temporary FFMETADATA file
+func createChapterMetadata(chapters []medias.Chapter) (string, error) {
+	tmpFile, err := os.CreateTemp("", "ffmpeg_chapters_*.txt")
+	if err != nil {
+		return "", err
+	}
+	defer tmpFile.Close()
+
+	if _, err := tmpFile.WriteString(chapterMetadataContent(chapters)); err != nil {
+		return "", err
+	}
+
+	return tmpFile.Name(), nil
+}
+
+// chapterMetadataContent returns the chapters in the FFMETADATA format
+func chapterMetadataContent(chapters []medias.Chapter) string {
+	var content strings.Builder
+	content.WriteString(";FFMETADATA1\n")
+
+	for _, chapter := range chapters {
+		fmt.Fprintf(&content, "[CHAPTER]\nTIMEBASE=1/1000\nSTART=%d\nEND=%d\ntitle=%s\n",
+			chapter.StartTime.Milliseconds(),
+			chapter.EndTime.Milliseconds(),
+			escapeMetadataValue(chapter.Title),
+		)
+	}
+
+	return content.String()
+}
+
+// escapeMetadataValue escapes the characters that have a meaning in FFMETADATA files
+func escapeMetadataValue(value string) string {
+	replacer := strings.NewReplacer(
+		`\`, `\\`,
+		"=", `\=`,
+		";", `\;`,
+		"#", `\#`,
+		"\n", "\\\n",
+	)
+	return replacer.Replace(value)
+}
+
+// RemoveChapters copies a video without its chapters
+func (fs *FFmpegService) RemoveChapters(ctx context.Context, inputFile, outputPath string, progress ProgressCallback) error {
+	logger.Infof("Removing chapters from %s", inputFile)
+
+	probeResult, err := fs.probeFile(ctx, inputFile)
+	if err != nil {
+		return err
+	}
+
+	if err := fs.runVerified(ctx, buildRemoveChaptersArgs(inputFile, outputPath), outputPath, expectSameStreams(probeResult)); err != nil {
+		return fmt.Errorf("ffmpeg chapter removal failed: %w", err)
+	}
+
+	if progress != nil {
+		progress(1.0, "Removed chapters")
+	}
+
+	logger.Infof("Successfully removed chapters from %s", inputFile)
+	return nil
+}
+
+// buildRemoveChaptersArgs builds FFmpeg arguments for copying a video without its chapters
+func buildRemoveChaptersArgs(inputFile, outputPath string) []string {
+	return []string{
+		"-i", inputFile,
+		"-map", "0",
+		"-map_chapters", "-1", // Remove all chapters
+		"-c", "copy",
+		outputPath,
+		"-y",
+	}
+}
+
+// ImportChapters replaces the chapters of a video with the ones of an OGM
+// (CHAPTER01=...) or Matroska XML chapter file
+func (fs *FFmpegService) ImportChapters(ctx context.Context, inputFile, chapterFile, outputPath string, progress ProgressCallback) error {
+	logger.Infof("Importing chapters from %s into %s", chapterFile, inputFile)
+
+	chapters, err := ParseChapterFile(chapterFile)
+	if err != nil {
+		return err
+	}
+
+	probeResult, err := fs.probeFile(ctx, inputFile)
+	if err != nil {
+		return err
+	}
+
+	// Chapter files don't always carry end times, use the next chapter or the file duration
+	fillChapterEnds(chapters, probeResult.Format.DurationSeconds)
+
+	metadataFile, err := createChapterMetadata(chapters)
+	if err != nil {
+		return fmt.Errorf("failed to create chapter metadata: %w", err)
+	}
+	defer os.Remove(metadataFile)
+
+	args := []string{
+		"-i", inputFile,
+		"-i", metadataFile,
+		"-map", "0",
+		"-map_chapters", "1",
+		"-c", "copy",
+		outputPath,
+		"-y",
+	}
+	if err := fs.runVerified(ctx, args, outputPath, expectSameStreams(probeResult)); err != nil {
+		return fmt.Errorf("ffmpeg chapter import failed: %w", err)
+	}
+
+	if progress != nil {
+		progress(1.0, fmt.Sprintf("Imported %d chapters", len(chapters)))
+	}
+
+	logger.Infof("Successfully imported %d chapters into %s", len(chapters), outputPath)
+	return nil
+}
+
+// ParseChapterFile reads an OGM or Matroska XML chapter file
+func ParseChapterFile(path string) ([]medias.Chapter, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read chapter file: %w", err)
+	}
+
+	var chapters []medias.Chapter
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte("<")) {
+		chapters, err = parseXMLChapters(data)
+	} else {
+		chapters, err = parseOGMChapters(data)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(chapters) == 0 {
+		return nil, fmt.Errorf("no chapters found in %s", filepath.Base(path))
+	}
+
+	sort.Slice(chapters, func(i, j int) bool { return chapters[i].StartTime < chapters[j].StartTime })
+	for i := range chapters {
+		chapters[i].ID = int64(i)
+	}
+	return chapters, nil
+}
+
+// parseOGMChapters parses the "CHAPTER01=00:00:00.000" / "CHAPTER01NAME=Title" format
+func parseOGMChapters(data []byte) ([]medias.Chapter, error) {
+	starts := make(map[string]time.Duration)
+	names := make(map[string]string)
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		key, value, found := strings.Cut(strings.TrimSpace(scanner.Text()), "=")
+		if !found || !strings.HasPrefix(strings.ToUpper(key), "CHAPTER") {
+			continue
+		}
+		key = strings.ToUpper(key)
+
+		if id, isName := strings.CutSuffix(key, "NAME"); isName {
+			names[id] = value
+			continue
+		}
+
+		start, err := parseChapterTimestamp(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid chapter time %q: %w", value, err)
+		}
+		starts[key] = start
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	chapters := make([]medias.Chapter, 0, len(starts))
+	for id, start := range starts {
+		chapters = append(chapters, medias.Chapter{
+			StartTime: start,
+			Title:     names[id],
+		})
+	}
+	return chapters, nil
+}
+
+// xmlChapters mirrors the Matroska XML chapter format
+type xmlChapters struct {
+	Editions []struct {
+		Atoms []struct {
+			Start    string `xml:"ChapterTimeStart"`
+			End      string `xml:"ChapterTimeEnd"`
+			Displays []struct {
+				Title string `xml:"ChapterString"`
+			} `xml:"ChapterDisplay"`
+		} `xml:"ChapterAtom"`
+	} `xml:"EditionEntry"`
+}
+
+// parseXMLChapters parses the first edition of a Matroska XML chapter file
+func parseXMLChapters(data []byte) ([]medias.Chapter, error) {
+	var parsed xmlChapters
+	if err := xml.Unmarshal(data, &parsed); err != nil {
+		return nil, fmt.Errorf("invalid XML chapter file: %w", err)
+	}
+	if len(parsed.Editions) == 0 {
+		return nil, nil
+	}
+
+	chapters := make([]medias.Chapter, 0, len(parsed.Editions[0].Atoms))
+	for _, atom := range parsed.Editions[0].Atoms {
+		start, err := parseChapterTimestamp(atom.Start)
+		if err != nil {
+			return nil, fmt.Errorf("invalid chapter start %q: %w", atom.Start, err)
+		}
+
+		chapter := medias.Chapter{StartTime: start}
+		if atom.End != "" {
+			if chapter.EndTime, err = parseChapterTimestamp(atom.End); err != nil {
+				return nil, fmt.Errorf("invalid chapter end %q: %w", atom.End, err)
+			}
+		}
+		if len(atom.Displays) > 0 {
+			chapter.Title = atom.Displays[0].Title
+		}
+		chapters = append(chapters, chapter)
+	}
+	return chapters, nil
+}
+
+// parseChapterTimestamp parses "HH:MM:SS.fff" timestamps (any sub-second precision)
+func parseChapterTimestamp(value string) (time.Duration, error) {
+	parts := strings.Split(strings.TrimSpace(value), ":")
+	if len(parts) != 3 {
+		return 0, fmt.Errorf("expected HH:MM:SS.fff")
+	}
+
+	hours, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return 0, err
+	}
+	minutes, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return 0, err
+	}
+	seconds, err := strconv.ParseFloat(parts[2], 64)
+	if err != nil {
+		return 0, err
+	}
+
+	return time.Duration(hours)*time.Hour +
+		time.Duration(minutes)*time.Minute +
+		time.Duration(seconds*float64(time.Second)), nil
+}
+
+// fillChapterEnds sets missing end times to the start of the next chapter,
+// or to the total duration for the last one
+func fillChapterEnds(chapters []medias.Chapter, duration time.Duration) {
+	for i := range chapters {
+		if chapters[i].EndTime > chapters[i].StartTime {
+			continue
+		}
+		if i+1 < len(chapters) {
+			chapters[i].EndTime = chapters[i+1].StartTime
+		} else if duration > chapters[i].StartTime {
+			chapters[i].EndTime = duration
+		} else {
+			chapters[i].EndTime = chapters[i].StartTime
+		}
+	}
+}
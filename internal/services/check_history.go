@@ -0,0 +1,293 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/Developpeur-du-dimanche/MediaTools/pkg/logger"
+)
+
+const (
+	// CheckHistoryVersion is the version of the check history format
+	CheckHistoryVersion = 1
+	// CheckHistoryFileName is the file of the check history in the app storage
+	CheckHistoryFileName = "check_history.json"
+	// maxCheckRecords is the number of checks kept per file
+	maxCheckRecords = 20
+	// checkHistorySaveInterval is how often a running batch saves the history
+	checkHistorySaveInterval = 30 * time.Second
+)
+
+// CheckRecord is an integrity check of a file
+type CheckRecord struct {
+	CheckedAt time.Time    `json:"checked_at"`
+	IsValid   bool         `json:"is_valid"`
+	Error     string       `json:"error,omitempty"`
+	Options   CheckOptions `json:"options"`
+}
+
+// String describes a check, e.g. "OK, 2024-05-01 10:00 (full)"
+func (r CheckRecord) String() string {
+	status := "OK"
+	if !r.IsValid {
+		status = "corrupted"
+	}
+	return fmt.Sprintf("%s, %s (%s)", status, r.CheckedAt.Format("2006-01-02 15:04"), r.Options)
+}
+
+// FileCheckHistory is the checks of a file, oldest first. The checks are
+// kept as long as the size and modification time of the file don't change:
+// a file that was written again starts a new history.
+type FileCheckHistory struct {
+	Path    string        `json:"path"`
+	Size    int64         `json:"size"`
+	ModTime time.Time     `json:"mod_time"`
+	Records []CheckRecord `json:"records"`
+}
+
+// Last returns the latest check of the file
+func (h *FileCheckHistory) Last() CheckRecord {
+	return h.Records[len(h.Records)-1]
+}
+
+// Regression is a file that passed a check and failed a later, no more
+// thorough one while its size and modification time stayed the same,
+// usually because of a failing disk
+type Regression struct {
+	Path string
+	// Passed is the last check the file passed
+	Passed CheckRecord
+	// Failed is the first check it failed afterwards
+	Failed CheckRecord
+}
+
+// checkHistoryFile is the saved check history
+type checkHistoryFile struct {
+	Version int                 `json:"version"`
+	Files   []*FileCheckHistory `json:"files"`
+}
+
+// CheckHistory keeps the integrity checks of every file so that unchanged
+// files that passed can be skipped and regressions reported. A nil history
+// records nothing.
+type CheckHistory struct {
+	path string
+
+	mu      sync.Mutex
+	files   map[string]*FileCheckHistory
+	dirty   bool
+	savedAt time.Time
+}
+
+// NewCheckHistory loads the check history saved at path. An unreadable
+// history is logged and replaced by an empty one.
+func NewCheckHistory(path string) *CheckHistory {
+	ch := &CheckHistory{
+		path:  path,
+		files: make(map[string]*FileCheckHistory),
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			logger.Warnf("Failed to read check history: %v", err)
+		}
+		return ch
+	}
+
+	var saved checkHistoryFile
+	if err := json.Unmarshal(data, &saved); err != nil || saved.Version > CheckHistoryVersion {
+		logger.Warnf("Ignoring invalid check history %s", path)
+		return ch
+	}
+	for _, file := range saved.Files {
+		if len(file.Records) > 0 {
+			ch.files[file.Path] = file
+		}
+	}
+	logger.Infof("Loaded check history of %d files", len(ch.files))
+	return ch
+}
+
+// Lookup returns the checks of a file, or nil when it was never checked or
+// changed since its last check
+func (ch *CheckHistory) Lookup(path string) *FileCheckHistory {
+	if ch == nil {
+		return nil
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil
+	}
+
+	ch.mu.Lock()
+	defer ch.mu.Unlock()
+	history, ok := ch.files[path]
+	if !ok || history.Size != info.Size() || !history.ModTime.Equal(info.ModTime()) {
+		return nil
+	}
+
+	// A copy, as running checks add records
+	found := *history
+	found.Records = append([]CheckRecord(nil), history.Records...)
+	return &found
+}
+
+// Passed returns the last check of a file when it passed with options at
+// least as thorough, and the file didn't change since
+func (ch *CheckHistory) Passed(path string, options CheckOptions) (CheckRecord, bool) {
+	history := ch.Lookup(path)
+	if history == nil {
+		return CheckRecord{}, false
+	}
+	last := history.Last()
+	return last, last.IsValid && covers(last.Options, options)
+}
+
+// covers reports whether a check with done finds everything a check with
+// wanted would
+func covers(done, wanted CheckOptions) bool {
+	rank := func(mode CheckMode) int {
+		for i, m := range CheckModes {
+			if m == mode {
+				return i
+			}
+		}
+		return -1
+	}
+	if rank(done.Mode) < rank(wanted.Mode) {
+		return false
+	}
+	if done.Mode == CheckModeSampled && wanted.Mode == CheckModeSampled &&
+		time.Duration(done.Samples)*done.SampleDuration < time.Duration(wanted.Samples)*wanted.SampleDuration {
+		return false
+	}
+	if done.Streams != CheckStreamsAll && done.Streams != wanted.Streams {
+		return false
+	}
+	// Stricter error detection may find errors the last check ignored
+	return wanted.ErrDetect == "" || done.ErrDetect == wanted.ErrDetect
+}
+
+// record adds a check of a file. The history is saved at most every
+// checkHistorySaveInterval; Flush saves the rest.
+func (ch *CheckHistory) record(result *VideoCheckResult) {
+	if ch == nil {
+		return
+	}
+	info, err := os.Stat(result.FilePath)
+	if err != nil {
+		return
+	}
+
+	ch.mu.Lock()
+	defer ch.mu.Unlock()
+
+	history, ok := ch.files[result.FilePath]
+	if !ok || history.Size != info.Size() || !history.ModTime.Equal(info.ModTime()) {
+		history = &FileCheckHistory{
+			Path:    result.FilePath,
+			Size:    info.Size(),
+			ModTime: info.ModTime(),
+		}
+		ch.files[result.FilePath] = history
+	}
+	history.Records = append(history.Records, CheckRecord{
+		CheckedAt: time.Now(),
+		IsValid:   result.IsValid,
+		Error:     result.Error,
+		Options:   result.Options,
+	})
+	if len(history.Records) > maxCheckRecords {
+		history.Records = history.Records[len(history.Records)-maxCheckRecords:]
+	}
+
+	ch.dirty = true
+	if time.Since(ch.savedAt) > checkHistorySaveInterval {
+		ch.save()
+	}
+}
+
+// Flush saves the checks recorded since the last save
+func (ch *CheckHistory) Flush() {
+	if ch == nil {
+		return
+	}
+	ch.mu.Lock()
+	defer ch.mu.Unlock()
+	if ch.dirty {
+		ch.save()
+	}
+}
+
+// save writes the history; the caller holds the lock. Failures are logged:
+// a check isn't failed because its history can't be written.
+func (ch *CheckHistory) save() {
+	saved := checkHistoryFile{
+		Version: CheckHistoryVersion,
+		Files:   make([]*FileCheckHistory, 0, len(ch.files)),
+	}
+	for _, file := range ch.files {
+		saved.Files = append(saved.Files, file)
+	}
+	sort.Slice(saved.Files, func(i, j int) bool { return saved.Files[i].Path < saved.Files[j].Path })
+
+	data, err := json.Marshal(saved)
+	if err != nil {
+		logger.Warnf("Failed to encode check history: %v", err)
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(ch.path), 0755); err != nil {
+		logger.Warnf("Failed to create check history directory: %v", err)
+		return
+	}
+
+	// Write then rename so a crash never leaves a truncated history
+	tmpPath := ch.path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0644); err != nil {
+		logger.Warnf("Failed to write check history: %v", err)
+		return
+	}
+	if err := os.Rename(tmpPath, ch.path); err != nil {
+		logger.Warnf("Failed to write check history: %v", err)
+		return
+	}
+	ch.dirty = false
+	ch.savedAt = time.Now()
+}
+
+// Regressions returns the files whose status went from OK to corrupted
+// between two checks where the passed one was at least as thorough, most
+// recent failure first
+func (ch *CheckHistory) Regressions() []Regression {
+	if ch == nil {
+		return nil
+	}
+	ch.mu.Lock()
+	defer ch.mu.Unlock()
+
+	regressions := make([]Regression, 0)
+	for _, history := range ch.files {
+		for i := 1; i < len(history.Records); i++ {
+			passed, failed := history.Records[i-1], history.Records[i]
+			// A less thorough check that passed may just have missed damage
+			// that was always there
+			if passed.IsValid && !failed.IsValid && covers(passed.Options, failed.Options) {
+				regressions = append(regressions, Regression{
+					Path:   history.Path,
+					Passed: passed,
+					Failed: failed,
+				})
+			}
+		}
+	}
+	sort.Slice(regressions, func(i, j int) bool {
+		return regressions[i].Failed.CheckedAt.After(regressions[j].Failed.CheckedAt)
+	})
+	return regressions
+}
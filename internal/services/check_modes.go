@@ -0,0 +1,328 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CheckMode is how thoroughly an integrity check reads a file
+type CheckMode string
+
+const (
+	// CheckModeQuick checks the container structure and index, and compares
+	// the probed duration with the packet timestamps. Nothing is decoded.
+	CheckModeQuick CheckMode = "quick"
+	// CheckModeSampled decodes evenly spaced segments of the file
+	CheckModeSampled CheckMode = "sampled"
+	// CheckModeFull decodes the whole file
+	CheckModeFull CheckMode = "full"
+)
+
+// CheckModes lists the check modes from the fastest to the most thorough
+var CheckModes = []CheckMode{CheckModeQuick, CheckModeSampled, CheckModeFull}
+
+// CheckStreams selects the streams an integrity check reads
+type CheckStreams string
+
+const (
+	CheckStreamsAll   CheckStreams = "all"
+	CheckStreamsVideo CheckStreams = "video"
+	CheckStreamsAudio CheckStreams = "audio"
+)
+
+// ErrDetectLevels lists the -err_detect flags of the ffmpeg decoders, from
+// the most lenient to the strictest
+var ErrDetectLevels = []string{"crccheck", "bitstream", "buffer", "explode", "careful", "compliant", "aggressive"}
+
+const (
+	// defaultCheckSamples is the number of segments decoded by sampled checks
+	defaultCheckSamples = 10
+	// defaultSampleDuration is the length of each decoded segment
+	defaultSampleDuration = 5 * time.Second
+	// quickCheckTail is how far from the end quick checks read the packets.
+	// Seeking there exercises the index.
+	quickCheckTail = 30 * time.Second
+)
+
+// CheckOptions configures an integrity check
+type CheckOptions struct {
+	Mode CheckMode `json:"mode"`
+	// Samples is the number of segments decoded in sampled mode
+	Samples int `json:"samples,omitempty"`
+	// SampleDuration is the length of each segment decoded in sampled mode
+	SampleDuration time.Duration `json:"sample_duration,omitempty"`
+	// ErrDetect is the -err_detect flag of the decoders; empty keeps the
+	// decoder default. Quick checks don't decode and ignore it.
+	ErrDetect string `json:"err_detect,omitempty"`
+	// Streams are the streams decoded, or whose packets are read by quick checks
+	Streams CheckStreams `json:"streams,omitempty"`
+	// StopAtFirstError stops a check at the first error instead of
+	// collecting every error of the file
+	StopAtFirstError bool `json:"stop_at_first_error,omitempty"`
+	// SkipPassed makes batches skip the files that passed an equally
+	// thorough check and didn't change since
+	SkipPassed bool `json:"skip_passed,omitempty"`
+}
+
+// DefaultCheckOptions fully decodes files
+func DefaultCheckOptions() CheckOptions {
+	return CheckOptions{
+		Mode:           CheckModeFull,
+		Samples:        defaultCheckSamples,
+		SampleDuration: defaultSampleDuration,
+		Streams:        CheckStreamsAll,
+	}
+}
+
+// normalized returns the options with a known mode, streams and error
+// detection, and at least one sample
+func (co CheckOptions) normalized() CheckOptions {
+	switch co.Mode {
+	case CheckModeQuick, CheckModeSampled, CheckModeFull:
+	default:
+		co.Mode = CheckModeFull
+	}
+	if co.Samples <= 0 {
+		co.Samples = defaultCheckSamples
+	}
+	if co.SampleDuration <= 0 {
+		co.SampleDuration = defaultSampleDuration
+	}
+	switch co.Streams {
+	case CheckStreamsAll, CheckStreamsVideo, CheckStreamsAudio:
+	default:
+		co.Streams = CheckStreamsAll
+	}
+	if !slices.Contains(ErrDetectLevels, co.ErrDetect) {
+		co.ErrDetect = ""
+	}
+	return co
+}
+
+// String describes the options, e.g. "sampled (10 × 5s), video streams, err_detect=careful"
+func (co CheckOptions) String() string {
+	parts := []string{string(co.Mode)}
+	if co.Mode == CheckModeSampled {
+		parts[0] = fmt.Sprintf("%s (%d × %s)", co.Mode, co.Samples, co.SampleDuration)
+	}
+	if co.Streams != "" && co.Streams != CheckStreamsAll {
+		parts = append(parts, fmt.Sprintf("%s streams", co.Streams))
+	}
+	if co.ErrDetect != "" && co.Mode != CheckModeQuick {
+		parts = append(parts, "err_detect="+co.ErrDetect)
+	}
+	if co.StopAtFirstError {
+		parts = append(parts, "stop at first error")
+	}
+	return strings.Join(parts, ", ")
+}
+
+// decodeArgs returns the ffmpeg arguments decoding a file, or length from
+// start when length isn't zero, without writing anything. Decoding always
+// runs in software so that results don't depend on the GPU of the machine.
+func (co CheckOptions) decodeArgs(inputFile string, start, length time.Duration) []string {
+	args := []string{"-v", "error", "-hwaccel", "none"}
+	if co.ErrDetect != "" {
+		args = append(args, "-err_detect", co.ErrDetect)
+	}
+	if co.StopAtFirstError {
+		args = append(args, "-xerror")
+	}
+	if length > 0 {
+		args = append(args, "-ss", formatSeconds(start))
+	}
+	args = append(args, "-i", inputFile)
+	if length > 0 {
+		args = append(args, "-t", formatSeconds(length))
+	}
+
+	switch co.Streams {
+	case CheckStreamsVideo:
+		args = append(args, "-map", "0:v")
+	case CheckStreamsAudio:
+		args = append(args, "-map", "0:a")
+	default:
+		args = append(args, "-map", "0:v?", "-map", "0:a?")
+	}
+
+	return append(args, "-f", "null", "-")
+}
+
+// quickCheck reads the container headers, then the first packets and the
+// packets of the last seconds. It fails when ffprobe reports errors, when the
+// end can't be reached through the index, or when the packets end far from
+// the probed duration.
+func (fs *FFmpegService) quickCheck(ctx context.Context, inputFile string, options CheckOptions, progress ProgressCallback) ([]string, error) {
+	if progress != nil {
+		progress(0, "Checking container...")
+	}
+
+	output, messages, err := fs.runFFprobe(ctx,
+		"-v", "error",
+		"-show_entries", "format=duration,start_time",
+		"-of", "default=noprint_wrappers=1",
+		inputFile,
+	)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return []string{fmt.Sprintf("container can't be read: %s", strings.TrimSpace(messages+" "+err.Error()))}, nil
+	}
+
+	problems := ffprobeProblems("container", messages)
+
+	var start, duration time.Duration
+	for _, line := range strings.Split(output, "\n") {
+		key, value, _ := strings.Cut(strings.TrimSpace(line), "=")
+		seconds, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			continue
+		}
+		switch key {
+		case "start_time":
+			start = time.Duration(seconds * float64(time.Second))
+		case "duration":
+			duration = time.Duration(seconds * float64(time.Second))
+		}
+	}
+	if duration <= 0 {
+		return append(problems, "container has no duration"), nil
+	}
+	if options.StopAtFirstError && len(problems) > 0 {
+		return problems[:1], nil
+	}
+
+	if progress != nil {
+		progress(0.5, "Checking index...")
+	}
+
+	// The first packets, then everything from quickCheckTail before the end
+	tailStart := max(start, start+duration-quickCheckTail)
+	args := []string{"-v", "error", "-read_intervals", "%+#5," + formatSeconds(tailStart) + "%"}
+	switch options.Streams {
+	case CheckStreamsVideo:
+		args = append(args, "-select_streams", "v")
+	case CheckStreamsAudio:
+		args = append(args, "-select_streams", "a")
+	}
+	args = append(args, "-show_entries", "packet=pts_time,duration_time", "-of", "csv=print_section=0", inputFile)
+	output, messages, err = fs.runFFprobe(ctx, args...)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return append(problems, fmt.Sprintf("packets can't be read: %s", strings.TrimSpace(messages+" "+err.Error()))), nil
+	}
+	problems = append(problems, ffprobeProblems("packets", messages)...)
+
+	end, found := time.Duration(0), false
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Split(strings.TrimSpace(line), ",")
+		pts, err := strconv.ParseFloat(fields[0], 64)
+		if err != nil {
+			continue
+		}
+		packetEnd := pts
+		if len(fields) > 1 {
+			if packetDuration, err := strconv.ParseFloat(fields[1], 64); err == nil {
+				packetEnd += packetDuration
+			}
+		}
+		end = max(end, time.Duration(packetEnd*float64(time.Second)))
+		found = true
+	}
+
+	if !found {
+		return append(problems, "no packets found: the index is broken or the file is truncated"), nil
+	}
+
+	tolerance := max(durationTolerance, time.Duration(float64(duration)*durationToleranceRatio))
+	if gap := start + duration - end; gap > tolerance || gap < -tolerance {
+		problems = append(problems, fmt.Sprintf("packets end at %s but the duration is %s: the file may be truncated",
+			formatTimestamp(end-start), formatTimestamp(duration)))
+	}
+
+	return problems, nil
+}
+
+// sampledCheck decodes options.Samples segments evenly spread over the file.
+// Short files are decoded fully.
+func (fs *FFmpegService) sampledCheck(ctx context.Context, inputFile string, duration time.Duration, options CheckOptions, progress ProgressCallback) ([]string, error) {
+	if duration <= 0 || time.Duration(options.Samples)*options.SampleDuration >= duration {
+		return fs.fullCheck(ctx, inputFile, duration.Seconds(), options, progress)
+	}
+
+	problems := make([]string, 0)
+	interval := duration / time.Duration(options.Samples)
+	for i := 0; i < options.Samples; i++ {
+		// Each segment sits in the middle of its share of the file
+		start := time.Duration(i)*interval + (interval-options.SampleDuration)/2
+
+		if progress != nil {
+			progress(float64(i)/float64(options.Samples), fmt.Sprintf("Decoding sample %d/%d at %s", i+1, options.Samples, formatTimestamp(start)))
+		}
+
+		cmd := exec.CommandContext(ctx, fs.ffmpegPath, options.decodeArgs(inputFile, start, options.SampleDuration)...)
+		output, err := cmd.CombinedOutput()
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+
+		messages := strings.TrimSpace(string(output))
+		if err != nil && messages == "" {
+			messages = err.Error()
+		}
+		if messages != "" {
+			problems = append(problems, fmt.Sprintf("at %s:\n%s", formatTimestamp(start), messages))
+			if options.StopAtFirstError {
+				break
+			}
+		}
+	}
+
+	return problems, nil
+}
+
+// hasStreams reports whether a file has a stream of the given type. An
+// unreadable file returns an error, and is left to the check to report.
+func (fs *FFmpegService) hasStreams(ctx context.Context, inputFile string, streams CheckStreams) (bool, error) {
+	output, _, err := fs.runFFprobe(ctx,
+		"-v", "error",
+		"-select_streams", string(streams)[:1],
+		"-show_entries", "stream=index",
+		"-of", "csv=p=0",
+		inputFile,
+	)
+	if err != nil {
+		return false, err
+	}
+	return strings.TrimSpace(output) != "", nil
+}
+
+// runFFprobe runs ffprobe and returns its output and its error messages
+func (fs *FFmpegService) runFFprobe(ctx context.Context, args ...string) (string, string, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "ffprobe", args...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	err := cmd.Run()
+	return stdout.String(), stderr.String(), err
+}
+
+// ffprobeProblems turns the error messages of ffprobe into problems
+func ffprobeProblems(prefix, messages string) []string {
+	problems := make([]string, 0)
+	for _, line := range strings.Split(messages, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			problems = append(problems, prefix+": "+line)
+		}
+	}
+	return problems
+}
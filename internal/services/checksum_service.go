@@ -0,0 +1,513 @@
+package services
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/Developpeur-du-dimanche/MediaTools/pkg/logger"
+	"github.com/Developpeur-du-dimanche/MediaTools/pkg/xxhash"
+)
+
+// HashAlgorithm is the checksum of a manifest
+type HashAlgorithm string
+
+const (
+	HashSHA256 HashAlgorithm = "sha256"
+	// HashXXH64 is much faster than SHA-256 and detects bit rot as well,
+	// but not deliberate tampering
+	HashXXH64 HashAlgorithm = "xxh64"
+)
+
+// HashAlgorithms lists the supported checksums
+var HashAlgorithms = []HashAlgorithm{HashSHA256, HashXXH64}
+
+// ManifestFormat is how a manifest is written
+type ManifestFormat string
+
+const (
+	// ManifestText is the "<hash>  <name>" format of sha256sum and xxhsum,
+	// written as checksums.sha256 or checksums.xxh64
+	ManifestText ManifestFormat = "text"
+	// ManifestJSON is checksums.json, which also records the size and
+	// modification time of every file
+	ManifestJSON ManifestFormat = "json"
+)
+
+const (
+	// ManifestVersion is the version of the JSON manifest format
+	ManifestVersion = 1
+	// manifestBaseName is the name of manifests without extension
+	manifestBaseName = "checksums"
+	// hashBufferSize is the size of the reads while hashing
+	hashBufferSize = 1 << 20
+)
+
+// ChecksumStatus is the outcome of a file in a manifest operation
+type ChecksumStatus string
+
+const (
+	// ChecksumAdded is a file written to a manifest
+	ChecksumAdded ChecksumStatus = "added"
+	// ChecksumOK is a file matching its manifest
+	ChecksumOK ChecksumStatus = "ok"
+	// ChecksumModified is a file whose hash changed along with its
+	// modification time: it was most likely edited
+	ChecksumModified ChecksumStatus = "modified"
+	// ChecksumCorrupted is a file of a JSON manifest whose hash changed while
+	// its size and modification time did not: its data rotted on disk
+	ChecksumCorrupted ChecksumStatus = "corrupted"
+	// ChecksumUnlisted is a file missing from the manifest of its folder
+	ChecksumUnlisted ChecksumStatus = "unlisted"
+	// ChecksumFailed is a file that couldn't be hashed
+	ChecksumFailed ChecksumStatus = "failed"
+)
+
+// ChecksumResult is the outcome of a file in a manifest operation
+type ChecksumResult struct {
+	Path         string
+	ManifestPath string
+	Algorithm    HashAlgorithm
+	Hash         string
+	// Expected is the hash in the manifest
+	Expected string
+	Status   ChecksumStatus
+	Error    string
+}
+
+// ManifestEntry is a file of a manifest
+type ManifestEntry struct {
+	Hash string `json:"hash"`
+	// Size and ModTime are only known for JSON manifests
+	Size    int64     `json:"size,omitempty"`
+	ModTime time.Time `json:"mod_time,omitempty"`
+}
+
+// Manifest holds the checksums of the files of a folder, by file name
+type Manifest struct {
+	Version   int                      `json:"version"`
+	Algorithm HashAlgorithm            `json:"algorithm"`
+	UpdatedAt time.Time                `json:"updated_at"`
+	Files     map[string]ManifestEntry `json:"files"`
+
+	path   string
+	format ManifestFormat
+}
+
+// ChecksumService generates and verifies checksum manifests
+type ChecksumService struct {
+	ffmpegService *FFmpegService
+}
+
+// NewChecksumService creates a checksum service hashing as many files at
+// once as the parallel options of ffmpegService allow for stream copies
+func NewChecksumService(ffmpegService *FFmpegService) *ChecksumService {
+	return &ChecksumService{
+		ffmpegService: ffmpegService,
+	}
+}
+
+// ManifestPath returns the manifest of a folder in a format
+func ManifestPath(folder string, format ManifestFormat, algorithm HashAlgorithm) string {
+	if format == ManifestJSON {
+		return filepath.Join(folder, manifestBaseName+".json")
+	}
+	return filepath.Join(folder, manifestBaseName+"."+string(algorithm))
+}
+
+// GenerateManifests hashes files and writes them to the manifest of their
+// folder, keeping the other files of existing manifests. When cancelled, the
+// files hashed so far are written.
+func (cs *ChecksumService) GenerateManifests(ctx context.Context, paths []string, algorithm HashAlgorithm, format ManifestFormat, progress ProgressCallback) ([]*ChecksumResult, error) {
+	if !slices.Contains(HashAlgorithms, algorithm) {
+		return nil, fmt.Errorf("unknown hash algorithm: %s", algorithm)
+	}
+
+	algorithms := make([]HashAlgorithm, len(paths))
+	for i := range paths {
+		algorithms[i] = algorithm
+	}
+	hashes, hashErr := cs.hashFiles(ctx, paths, algorithms, progress)
+
+	manifests := make(map[string]*Manifest)
+	results := make([]*ChecksumResult, 0, len(paths))
+	for i, path := range paths {
+		folder := filepath.Dir(path)
+		manifest, ok := manifests[folder]
+		if !ok {
+			manifest = openManifestForUpdate(ManifestPath(folder, format, algorithm), format, algorithm)
+			manifests[folder] = manifest
+		}
+
+		result := &ChecksumResult{Path: path, ManifestPath: manifest.path, Algorithm: algorithm}
+		fileHash := hashes[i]
+		switch {
+		case fileHash.err != nil && ctx.Err() != nil:
+			// Not hashed before the cancellation
+			continue
+		case fileHash.err != nil:
+			result.Status = ChecksumFailed
+			result.Error = fileHash.err.Error()
+		case fileHash.hash == "":
+			// Never write an empty checksum to a manifest
+			result.Status = ChecksumFailed
+			result.Error = "file was not hashed"
+		default:
+			result.Status = ChecksumAdded
+			result.Hash = fileHash.hash
+			manifest.Files[filepath.Base(path)] = ManifestEntry{
+				Hash:    fileHash.hash,
+				Size:    fileHash.size,
+				ModTime: fileHash.modTime,
+			}
+		}
+		results = append(results, result)
+	}
+
+	for _, manifest := range manifests {
+		if len(manifest.Files) == 0 {
+			continue
+		}
+		if err := manifest.save(); err != nil {
+			return results, err
+		}
+		logger.Infof("Wrote %d checksums to %s", len(manifest.Files), manifest.path)
+	}
+	return results, hashErr
+}
+
+// VerifyManifests hashes files and compares them with the manifest of their
+// folder. Files without a manifest are reported as unlisted.
+func (cs *ChecksumService) VerifyManifests(ctx context.Context, paths []string, progress ProgressCallback) ([]*ChecksumResult, error) {
+	manifests := make(map[string]*Manifest)
+	results := make([]*ChecksumResult, len(paths))
+	toHash := make([]int, 0, len(paths))
+
+	for i, path := range paths {
+		folder := filepath.Dir(path)
+		manifest, ok := manifests[folder]
+		if !ok {
+			manifest = findManifest(folder)
+			manifests[folder] = manifest
+		}
+
+		results[i] = &ChecksumResult{Path: path, Status: ChecksumUnlisted}
+		if manifest == nil {
+			results[i].Error = "no manifest in folder"
+			continue
+		}
+		results[i].ManifestPath = manifest.path
+		results[i].Algorithm = manifest.Algorithm
+		if _, listed := manifest.Files[filepath.Base(path)]; listed {
+			toHash = append(toHash, i)
+		}
+	}
+
+	hashPaths := make([]string, len(toHash))
+	algorithms := make([]HashAlgorithm, len(toHash))
+	for j, i := range toHash {
+		hashPaths[j] = paths[i]
+		algorithms[j] = results[i].Algorithm
+	}
+	hashes, hashErr := cs.hashFiles(ctx, hashPaths, algorithms, progress)
+
+	verified := make([]*ChecksumResult, 0, len(paths))
+	hashed := make(map[int]fileHash, len(toHash))
+	for j, i := range toHash {
+		hashed[i] = hashes[j]
+	}
+	for i, result := range results {
+		fileHash, listed := hashed[i]
+		if !listed {
+			verified = append(verified, result)
+			continue
+		}
+		if fileHash.err != nil && ctx.Err() != nil {
+			continue
+		}
+
+		manifest := manifests[filepath.Dir(result.Path)]
+		entry := manifest.Files[filepath.Base(result.Path)]
+		result.Expected = entry.Hash
+		result.Hash = fileHash.hash
+
+		switch {
+		case fileHash.err != nil:
+			result.Status = ChecksumFailed
+			result.Error = fileHash.err.Error()
+		case strings.EqualFold(fileHash.hash, entry.Hash):
+			result.Status = ChecksumOK
+		// Only the JSON manifests record the size and modification time of each file.
+		// A text manifest can't tell corruption from an edit.
+		case manifest.format == ManifestJSON && fileHash.size == entry.Size && fileHash.modTime.Equal(entry.ModTime):
+			result.Status = ChecksumCorrupted
+			result.Error = "content changed but the modification time did not"
+		default:
+			result.Status = ChecksumModified
+			result.Error = "file was modified after the manifest was written"
+		}
+		verified = append(verified, result)
+	}
+
+	return verified, hashErr
+}
+
+// fileHash is the checksum of a file with its state when it was read
+type fileHash struct {
+	hash    string
+	size    int64
+	modTime time.Time
+	err     error
+}
+
+// hashFiles hashes files in parallel, reporting the overall progress in
+// bytes. The files not hashed when ctx is cancelled get the context error.
+func (cs *ChecksumService) hashFiles(ctx context.Context, paths []string, algorithms []HashAlgorithm, progress ProgressCallback) ([]fileHash, error) {
+	parallel := cs.ffmpegService.GetParallelOptions()
+	hashes := make([]fileHash, len(paths))
+
+	var total int64
+	for _, path := range paths {
+		if info, err := os.Stat(path); err == nil {
+			total += info.Size()
+		}
+	}
+	var read atomic.Int64
+	var done atomic.Int32
+	report := func(message string) {
+		if progress != nil && total > 0 {
+			progress(min(1, float64(read.Load())/float64(total)), message)
+		}
+	}
+
+	slots := make(chan struct{}, parallel.StreamJobs)
+	disks := newDiskLimiter(parallel.PerDiskJobs)
+	var wg sync.WaitGroup
+
+	// Every file counts as not hashed until its job replaces the entry
+	for i := range hashes {
+		hashes[i].err = context.Canceled
+	}
+
+	for i, path := range paths {
+		select {
+		case <-ctx.Done():
+		case slots <- struct{}{}:
+		}
+		if ctx.Err() != nil {
+			break
+		}
+
+		wg.Add(1)
+		go func(index int, path string) {
+			defer wg.Done()
+			defer func() { <-slots }()
+
+			release, ok := disks.acquire(ctx, []string{path})
+			if !ok {
+				return
+			}
+			defer release()
+
+			name := filepath.Base(path)
+			hashes[index] = hashFile(ctx, path, algorithms[index], func(n int) {
+				read.Add(int64(n))
+				report(fmt.Sprintf("Hashing %s", name))
+			})
+			report(fmt.Sprintf("Hashed %d/%d files", done.Add(1), len(paths)))
+		}(i, path)
+	}
+
+	wg.Wait()
+	return hashes, ctx.Err()
+}
+
+// hashFile computes the checksum of a file; onRead receives the number of
+// bytes read after every read
+func hashFile(ctx context.Context, path string, algorithm HashAlgorithm, onRead func(n int)) fileHash {
+	info, err := os.Stat(path)
+	if err != nil {
+		return fileHash{err: err}
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return fileHash{err: err}
+	}
+	defer file.Close()
+
+	var h hash.Hash
+	switch algorithm {
+	case HashXXH64:
+		h = xxhash.New()
+	case HashSHA256:
+		h = sha256.New()
+	default:
+		return fileHash{err: fmt.Errorf("unknown hash algorithm: %s", algorithm)}
+	}
+
+	reader := &contextReader{ctx: ctx, reader: file, onRead: onRead}
+	if _, err := io.CopyBuffer(h, reader, make([]byte, hashBufferSize)); err != nil {
+		return fileHash{err: err}
+	}
+
+	return fileHash{
+		hash:    hex.EncodeToString(h.Sum(nil)),
+		size:    info.Size(),
+		modTime: info.ModTime(),
+	}
+}
+
+// contextReader stops reading once its context is cancelled
+type contextReader struct {
+	ctx    context.Context
+	reader io.Reader
+	onRead func(n int)
+}
+
+func (cr *contextReader) Read(p []byte) (int, error) {
+	if err := cr.ctx.Err(); err != nil {
+		return 0, err
+	}
+	n, err := cr.reader.Read(p)
+	if n > 0 {
+		cr.onRead(n)
+	}
+	return n, err
+}
+
+// findManifest returns the manifest of a folder, preferring JSON manifests,
+// or nil when the folder has none
+func findManifest(folder string) *Manifest {
+	candidates := []string{ManifestPath(folder, ManifestJSON, "")}
+	for _, algorithm := range HashAlgorithms {
+		candidates = append(candidates, ManifestPath(folder, ManifestText, algorithm))
+	}
+
+	for _, path := range candidates {
+		manifest, err := loadManifest(path)
+		if err == nil {
+			return manifest
+		}
+		if !os.IsNotExist(err) {
+			logger.Warnf("Ignoring manifest %s: %v", path, err)
+		}
+	}
+	return nil
+}
+
+// openManifestForUpdate loads a manifest to add files to it. An unreadable
+// manifest, or one using another algorithm, is replaced.
+func openManifestForUpdate(path string, format ManifestFormat, algorithm HashAlgorithm) *Manifest {
+	manifest, err := loadManifest(path)
+	if err == nil && manifest.Algorithm == algorithm {
+		return manifest
+	}
+	if err == nil || !os.IsNotExist(err) {
+		logger.Warnf("Replacing manifest %s", path)
+	}
+	return &Manifest{
+		Version:   ManifestVersion,
+		Algorithm: algorithm,
+		Files:     make(map[string]ManifestEntry),
+		path:      path,
+		format:    format,
+	}
+}
+
+// loadManifest reads a JSON or text manifest
+func loadManifest(path string) (*Manifest, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	manifest := &Manifest{
+		Files: make(map[string]ManifestEntry),
+		path:  path,
+	}
+
+	if filepath.Ext(path) == ".json" {
+		manifest.format = ManifestJSON
+		if err := json.Unmarshal(data, manifest); err != nil {
+			return nil, fmt.Errorf("invalid manifest: %w", err)
+		}
+		if manifest.Version > ManifestVersion {
+			return nil, fmt.Errorf("unsupported manifest version %d", manifest.Version)
+		}
+		if manifest.Files == nil {
+			manifest.Files = make(map[string]ManifestEntry)
+		}
+		return manifest, nil
+	}
+
+	manifest.format = ManifestText
+	manifest.Algorithm = HashAlgorithm(strings.TrimPrefix(filepath.Ext(path), "."))
+	scanner := bufio.NewScanner(strings.NewReader(string(data)))
+	for scanner.Scan() {
+		// "<hash>  <name>", or "<hash> *<name>" in binary mode
+		sum, name, found := strings.Cut(strings.TrimRight(scanner.Text(), "\r"), " ")
+		if !found || len(name) < 2 || strings.HasPrefix(sum, "#") {
+			continue
+		}
+		// Files of subfolders, listed by other tools, never match a file name
+		manifest.Files[strings.TrimPrefix(name[1:], "./")] = ManifestEntry{Hash: strings.ToLower(sum)}
+	}
+	return manifest, scanner.Err()
+}
+
+// save writes the manifest, sorted by file name
+func (m *Manifest) save() error {
+	m.UpdatedAt = time.Now()
+
+	// Entries without a checksum can't be verified; they come from an interrupted run
+	for name, entry := range m.Files {
+		if entry.Hash == "" {
+			delete(m.Files, name)
+		}
+	}
+
+	var data []byte
+	if m.format == ManifestJSON {
+		encoded, err := json.MarshalIndent(m, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to encode manifest: %w", err)
+		}
+		data = encoded
+	} else {
+		names := make([]string, 0, len(m.Files))
+		for name := range m.Files {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		var text strings.Builder
+		for _, name := range names {
+			fmt.Fprintf(&text, "%s  %s\n", m.Files[name].Hash, name)
+		}
+		data = []byte(text.String())
+	}
+
+	// Write then rename so a crash never leaves a truncated manifest
+	tmpPath := m.path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write manifest: %w", err)
+	}
+	if err := os.Rename(tmpPath, m.path); err != nil {
+		return fmt.Errorf("failed to write manifest: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,33 @@
+//go:build !windows
+// +build !windows
+
+package services
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"syscall"
+)
+
+// diskID identifies the device holding a path. Paths that don't exist yet,
+// like outputs, are looked up through their closest existing parent.
+func diskID(path string) string {
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return ""
+	}
+	for {
+		if info, err := os.Stat(path); err == nil {
+			if stat, ok := info.Sys().(*syscall.Stat_t); ok {
+				return fmt.Sprint(stat.Dev)
+			}
+			return ""
+		}
+		parent := filepath.Dir(path)
+		if parent == path {
+			return ""
+		}
+		path = parent
+	}
+}
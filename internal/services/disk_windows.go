@@ -0,0 +1,18 @@
+//go:build windows
+// +build windows
+
+package services
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// diskID identifies the volume holding a path, such as "C:" or a UNC share
+func diskID(path string) string {
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return ""
+	}
+	return strings.ToUpper(filepath.VolumeName(path))
+}
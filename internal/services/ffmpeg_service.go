@@ -1,15 +1,19 @@
 package services
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"slices"
 	"strconv"
 	"strings"
+	"time"
 
+	"github.com/Developpeur-du-dimanche/MediaTools/pkg/languages"
 	"github.com/Developpeur-du-dimanche/MediaTools/pkg/logger"
 	"github.com/Developpeur-du-dimanche/MediaTools/pkg/medias"
 )
@@ -17,6 +21,11 @@ import (
 // FFmpegService handles FFmpeg operations
 type FFmpegService struct {
 	ffmpegPath string
+	// journals records batches so they can be resumed; nil disables it
+	journals *JournalStore
+	parallel ParallelOptions
+	// checkHistory records integrity checks; nil disables it
+	checkHistory *CheckHistory
 }
 
 func (fs *FFmpegService) LocateFFmpeg() (string, error) {
@@ -31,6 +40,7 @@ func (fs *FFmpegService) LocateFFmpeg() (string, error) {
 func NewFFmpegService() *FFmpegService {
 	return &FFmpegService{
 		ffmpegPath: "ffmpeg", // Assume ffmpeg is in PATH
+		parallel:   DefaultParallelOptions(),
 	}
 }
 
@@ -41,6 +51,36 @@ func (fs *FFmpegService) SetFFmpegPath(path string) {
 	}
 }
 
+// SetJournalStore sets where batch journals are saved
+func (fs *FFmpegService) SetJournalStore(store *JournalStore) {
+	fs.journals = store
+}
+
+// SetParallelOptions sets how many files batches process at once
+func (fs *FFmpegService) SetParallelOptions(options ParallelOptions) {
+	fs.parallel = options.normalized()
+}
+
+// GetParallelOptions returns how many files batches process at once
+func (fs *FFmpegService) GetParallelOptions() ParallelOptions {
+	return fs.parallel
+}
+
+// SetCheckHistory sets where integrity checks are recorded
+func (fs *FFmpegService) SetCheckHistory(history *CheckHistory) {
+	fs.checkHistory = history
+}
+
+// GetCheckHistory returns the history of integrity checks
+func (fs *FFmpegService) GetCheckHistory() *CheckHistory {
+	return fs.checkHistory
+}
+
+// GetJournalStore returns the store of batch journals
+func (fs *FFmpegService) GetJournalStore() *JournalStore {
+	return fs.journals
+}
+
 // GetFFmpegPath returns the current ffmpeg path
 func (fs *FFmpegService) GetFFmpegPath() string {
 	return fs.ffmpegPath
@@ -49,51 +89,256 @@ func (fs *FFmpegService) GetFFmpegPath() string {
 // ProgressCallback is called during FFmpeg operations
 type ProgressCallback func(progress float64, message string)
 
-// MergeVideos concatenates multiple video files into one
-func (fs *FFmpegService) MergeVideos(ctx context.Context, inputFiles []string, outputPath string, progress ProgressCallback) error {
-	if len(inputFiles) == 0 {
-		return fmt.Errorf("no input files provided")
-	}
+// MergeOptions configures how MergeVideos joins its inputs
+type MergeOptions struct {
+	Mode MergeMode
 
-	logger.Infof("Merging %d videos into %s", len(inputFiles), outputPath)
+	// WriteChapters adds one chapter per input to the output
+	WriteChapters bool
+	// ChapterTitleTemplate is used to name generated chapters (see BuildMergeChapters)
+	ChapterTitleTemplate string
+}
 
-	// Create a temporary file list for FFmpeg concat
-	listFile, err := fs.createConcatList(inputFiles)
+// MergeVideos concatenates multiple video files into one
+func (fs *FFmpegService) MergeVideos(ctx context.Context, inputFiles []string, outputPath string, options MergeOptions, progress ProgressCallback) error {
+	plan, err := fs.PlanMerge(ctx, inputFiles, outputPath, options)
 	if err != nil {
-		return fmt.Errorf("failed to create concat list: %w", err)
+		return err
 	}
-	defer os.Remove(listFile)
 
-	// Build FFmpeg command
-	args := []string{
-		"-f", "concat",
-		"-safe", "0",
-		"-i", listFile,
-		"-c", "copy", // Copy streams without re-encoding
-		outputPath,
-		"-y", // Overwrite output file
-	}
+	logger.Infof("Merging %d videos into %s (mode: %s)", len(inputFiles), outputPath, options.Mode)
+	return fs.ExecuteMerge(ctx, plan, progress)
+}
 
-	cmd := exec.CommandContext(ctx, fs.ffmpegPath, args...)
+// ExecuteMerge runs a plan made by PlanMerge
+func (fs *FFmpegService) ExecuteMerge(ctx context.Context, plan *Plan, progress ProgressCallback) error {
+	if len(plan.Steps) != 1 {
+		return fmt.Errorf("invalid merge plan: %d steps", len(plan.Steps))
+	}
+	step := plan.Steps[0]
 
-	// Capture output for progress tracking
-	output, err := cmd.CombinedOutput()
-	if err != nil {
-		return fmt.Errorf("ffmpeg merge failed: %w\nOutput: %s", err, string(output))
+	if err := fs.runStep(ctx, step); err != nil {
+		return fmt.Errorf("ffmpeg merge failed: %w", err)
 	}
 
 	if progress != nil {
 		progress(1.0, "Merge complete")
 	}
 
-	logger.Infof("Successfully merged videos to %s", outputPath)
+	logger.Infof("Successfully merged videos to %s", step.OutputPath)
 	return nil
 }
 
+// PlanMerge resolves the ffmpeg run of MergeVideos without running it. The
+// plan has a single step whose input path is the first file.
+func (fs *FFmpegService) PlanMerge(ctx context.Context, inputFiles []string, outputPath string, options MergeOptions) (*Plan, error) {
+	if len(inputFiles) == 0 {
+		return nil, fmt.Errorf("no input files provided")
+	}
+
+	probes := make([]*medias.FfprobeResult, len(inputFiles))
+	for i, inputFile := range inputFiles {
+		probeResult, err := fs.probeFile(ctx, inputFile)
+		if err != nil {
+			return nil, err
+		}
+		probes[i] = probeResult
+	}
+
+	var args []string
+	var streams []PlannedStream
+	var files []PlanFile
+	switch options.Mode {
+	case MergeModeReencode:
+		// The concat filter graph takes the first video stream of every input
+		if report := ValidateMergeInputs(probes); !report.CanMerge() {
+			return nil, fmt.Errorf("no video stream in %s", strings.Join(report.MissingVideo, ", "))
+		}
+		args = fs.buildConcatFilterArgs(inputFiles, probes, outputPath)
+		streams = append(streams, PlannedStream{Type: "video", Codec: "libx264"})
+		if slices.Contains(args, "[outa]") {
+			streams = append(streams, PlannedStream{Type: "audio", Codec: "aac"})
+		}
+	default:
+		// The file list for FFmpeg concat is written when the step runs
+		files = append(files, PlanFile{Name: "concat_list.txt", Content: concatListContent(inputFiles)})
+
+		args = []string{
+			"-f", "concat",
+			"-safe", "0",
+			"-i", planWorkDir + "/concat_list.txt",
+			"-c", "copy", // Copy streams without re-encoding
+			outputPath,
+			"-y", // Overwrite output file
+		}
+		// The concat demuxer takes its streams from the first file
+		streams = predictStreams(probes[0], args)
+	}
+
+	if options.WriteChapters {
+		files = append(files, PlanFile{Name: "chapters.txt", Content: chapterMetadataContent(BuildMergeChapters(probes, options.ChapterTitleTemplate))})
+
+		// The metadata file is added as the last input, right before the output options
+		metadataIndex := 1
+		if options.Mode == MergeModeReencode {
+			metadataIndex = len(inputFiles)
+		}
+		args = insertMetadataInput(args, planWorkDir+"/chapters.txt", metadataIndex)
+	}
+
+	// The output lasts as long as all the inputs together
+	var duration time.Duration
+	for _, probe := range probes {
+		duration += probe.Format.DurationSeconds
+	}
+
+	step := &PlanStep{
+		InputPath:  inputFiles[0],
+		OutputPath: outputPath,
+		Args:       args,
+		Files:      files,
+		Streams:    streams,
+		Expect:     expectPlannedStreams(duration, streams),
+		Note:       fmt.Sprintf("Merge of %d files (mode: %s)", len(inputFiles), options.Mode),
+	}
+	return &Plan{Operation: "merge", Steps: []*PlanStep{step}}, nil
+}
+
+// insertMetadataInput adds an FFMETADATA input after the last "-i" argument
+// and maps its chapters to the output
+func insertMetadataInput(args []string, metadataFile string, metadataIndex int) []string {
+	lastInput := 0
+	for i, arg := range args {
+		if arg == "-i" {
+			lastInput = i
+		}
+	}
+
+	result := make([]string, 0, len(args)+4)
+	result = append(result, args[:lastInput+2]...)
+	result = append(result,
+		"-i", metadataFile,
+		"-map_chapters", strconv.Itoa(metadataIndex),
+	)
+	result = append(result, args[lastInput+2:]...)
+	return result
+}
+
+// buildConcatFilterArgs builds FFmpeg arguments that normalize every input to the
+// format of the first one and join them with the concat filter
+func (fs *FFmpegService) buildConcatFilterArgs(inputFiles []string, probes []*medias.FfprobeResult, outputPath string) []string {
+	reference := probes[0]
+
+	width, height := 1280, 720
+	frameRate := ""
+	if len(reference.Videos) > 0 {
+		width, height = reference.Videos[0].Width, reference.Videos[0].Height
+		frameRate = reference.Videos[0].FrameRate
+	}
+
+	sampleRate := "48000"
+	channelLayout := "stereo"
+	if len(reference.Audios) > 0 {
+		audio := reference.Audios[0]
+		if audio.SampleRate != "" {
+			sampleRate = audio.SampleRate
+		}
+		// The reference layout is kept, so a 5.1 merge stays 5.1
+		switch {
+		case audio.ChannelLayout != "":
+			channelLayout = audio.ChannelLayout
+		case audio.Channels > 0:
+			channelLayout = fmt.Sprintf("%dc", audio.Channels)
+		}
+	}
+
+	// Audio is only kept when every input has at least one audio stream,
+	// otherwise the concat filter can't pair the segments
+	withAudio := true
+	for _, probe := range probes {
+		if len(probe.Audios) == 0 {
+			withAudio = false
+			break
+		}
+	}
+
+	args := make([]string, 0, len(inputFiles)*2+16)
+	for _, inputFile := range inputFiles {
+		args = append(args, "-i", inputFile)
+	}
+
+	var filter strings.Builder
+	for i := range inputFiles {
+		fmt.Fprintf(&filter, "[%d:v:0]scale=%d:%d:force_original_aspect_ratio=decrease,pad=%d:%d:(ow-iw)/2:(oh-ih)/2,setsar=1", i, width, height, width, height)
+		if frameRate != "" && frameRate != "0/0" {
+			fmt.Fprintf(&filter, ",fps=%s", frameRate)
+		}
+		fmt.Fprintf(&filter, ",format=yuv420p[v%d];", i)
+		if withAudio {
+			fmt.Fprintf(&filter, "[%d:a:0]aresample=%s,aformat=channel_layouts=%s[a%d];", i, sampleRate, channelLayout, i)
+		}
+	}
+	for i := range inputFiles {
+		fmt.Fprintf(&filter, "[v%d]", i)
+		if withAudio {
+			fmt.Fprintf(&filter, "[a%d]", i)
+		}
+	}
+	audioCount := 0
+	if withAudio {
+		audioCount = 1
+	}
+	fmt.Fprintf(&filter, "concat=n=%d:v=1:a=%d[outv]", len(inputFiles), audioCount)
+	if withAudio {
+		filter.WriteString("[outa]")
+	}
+
+	args = append(args,
+		"-filter_complex", filter.String(),
+		"-map", "[outv]",
+	)
+	if withAudio {
+		args = append(args, "-map", "[outa]", "-c:a", "aac", "-b:a", "192k")
+	}
+	args = append(args,
+		"-c:v", "libx264",
+		"-crf", "20",
+		"-preset", "medium",
+		outputPath,
+		"-y",
+	)
+
+	return args
+}
+
 // RemoveStreamsByType removes all streams of a specific type from a video
 func (fs *FFmpegService) RemoveStreamsByType(ctx context.Context, inputFile, outputPath, streamType string, progress ProgressCallback) error {
 	logger.Infof("Removing %s streams from %s", streamType, inputFile)
 
+	probeResult, err := fs.probeFile(ctx, inputFile)
+	if err != nil {
+		return err
+	}
+
+	args, err := buildRemoveByTypeArgs(inputFile, outputPath, streamType)
+	if err != nil {
+		return err
+	}
+
+	if err := fs.runStep(ctx, newPlanStep(inputFile, outputPath, args, probeResult, "")); err != nil {
+		return fmt.Errorf("ffmpeg stream removal failed: %w", err)
+	}
+
+	if progress != nil {
+		progress(1.0, fmt.Sprintf("Removed %s streams", streamType))
+	}
+
+	logger.Infof("Successfully removed %s streams", streamType)
+	return nil
+}
+
+// buildRemoveByTypeArgs builds FFmpeg arguments for removing all streams of a type
+func buildRemoveByTypeArgs(inputFile, outputPath, streamType string) ([]string, error) {
 	var args []string
 	switch strings.ToLower(streamType) {
 	case "audio":
@@ -140,21 +385,10 @@ func (fs *FFmpegService) RemoveStreamsByType(ctx context.Context, inputFile, out
 			"-y",
 		}
 	default:
-		return fmt.Errorf("unsupported stream type: %s", streamType)
-	}
-
-	cmd := exec.CommandContext(ctx, fs.ffmpegPath, args...)
-	output, err := cmd.CombinedOutput()
-	if err != nil {
-		return fmt.Errorf("ffmpeg stream removal failed: %w\nOutput: %s", err, string(output))
-	}
-
-	if progress != nil {
-		progress(1.0, fmt.Sprintf("Removed %s streams", streamType))
+		return nil, fmt.Errorf("unsupported stream type: %s", streamType)
 	}
 
-	logger.Infof("Successfully removed %s streams", streamType)
-	return nil
+	return args, nil
 }
 
 // RemoveStreamsByLanguage removes streams matching a specific language
@@ -166,13 +400,8 @@ func (fs *FFmpegService) RemoveStreamsByLanguage(ctx context.Context, inputFile,
 		return err
 	}
 
-	if !fs.hasStreamWithLanguage(probeResult, streamType, language) {
-		return fs.copyFile(ctx, inputFile, outputPath, progress, "No streams removed; file copied")
-	}
-
-	args := fs.buildRemoveByLanguageArgs(inputFile, outputPath, streamType, language, probeResult)
-
-	if err := fs.runFFmpeg(ctx, args); err != nil {
+	step := fs.removeByLanguageStep(inputFile, outputPath, streamType, language, probeResult)
+	if err := fs.runStep(ctx, step); err != nil {
 		return fmt.Errorf("ffmpeg language removal failed: %w", err)
 	}
 
@@ -184,6 +413,16 @@ func (fs *FFmpegService) RemoveStreamsByLanguage(ctx context.Context, inputFile,
 	return nil
 }
 
+// removeByLanguageStep plans the removal of the streams of a language. Files
+// without such streams are only copied.
+func (fs *FFmpegService) removeByLanguageStep(inputFile, outputPath, streamType, language string, probeResult *medias.FfprobeResult) *PlanStep {
+	if !fs.hasStreamWithLanguage(probeResult, streamType, language) {
+		return copyStep(inputFile, outputPath, probeResult, "No streams removed; file copied")
+	}
+	args := fs.buildRemoveByLanguageArgs(inputFile, outputPath, streamType, language, probeResult)
+	return newPlanStep(inputFile, outputPath, args, probeResult, "")
+}
+
 // probeFile probes a video file and returns the result
 func (fs *FFmpegService) probeFile(ctx context.Context, inputFile string) (*medias.FfprobeResult, error) {
 	ffprobeData := medias.NewFfprobe(inputFile,
@@ -191,6 +430,7 @@ func (fs *FFmpegService) probeFile(ctx context.Context, inputFile string) (*medi
 		medias.PRINT_FORMAT_JSON,
 		medias.SHOW_FORMAT,
 		medias.SHOW_STREAMS,
+		medias.SHOW_CHAPTERS,
 		medias.EXPERIMENTAL,
 	)
 	probeResult, err := ffprobeData.Probe(ctx)
@@ -205,13 +445,13 @@ func (fs *FFmpegService) hasStreamWithLanguage(probeResult *medias.FfprobeResult
 	switch strings.ToLower(streamType) {
 	case "audio":
 		for _, stream := range probeResult.Audios {
-			if strings.EqualFold(stream.Language, language) {
+			if languages.Match(stream.Language, language) {
 				return true
 			}
 		}
 	case "subtitle":
 		for _, stream := range probeResult.Subtitles {
-			if strings.EqualFold(stream.Language, language) {
+			if languages.Match(stream.Language, language) {
 				return true
 			}
 		}
@@ -219,23 +459,6 @@ func (fs *FFmpegService) hasStreamWithLanguage(probeResult *medias.FfprobeResult
 	return false
 }
 
-// copyFile copies a file without modification
-func (fs *FFmpegService) copyFile(ctx context.Context, inputFile, outputPath string, progress ProgressCallback, message string) error {
-	args := []string{
-		"-i", inputFile,
-		"-c", "copy",
-		outputPath,
-		"-y",
-	}
-	if err := fs.runFFmpeg(ctx, args); err != nil {
-		return fmt.Errorf("ffmpeg copy failed: %w", err)
-	}
-	if progress != nil {
-		progress(1.0, message)
-	}
-	return nil
-}
-
 // buildRemoveByLanguageArgs builds FFmpeg arguments for removing streams by language
 func (fs *FFmpegService) buildRemoveByLanguageArgs(inputFile, outputPath, streamType, language string, probeResult *medias.FfprobeResult) []string {
 	args := []string{
@@ -246,13 +469,13 @@ func (fs *FFmpegService) buildRemoveByLanguageArgs(inputFile, outputPath, stream
 	switch strings.ToLower(streamType) {
 	case "audio":
 		for i, stream := range probeResult.Audios {
-			if !strings.EqualFold(stream.Language, language) {
+			if !languages.Match(stream.Language, language) {
 				args = append(args, "-map", fmt.Sprintf("0:a:%d", i))
 			}
 		}
 	case "subtitle":
 		for i, stream := range probeResult.Subtitles {
-			if !strings.EqualFold(stream.Language, language) {
+			if !languages.Match(stream.Language, language) {
 				args = append(args, "-map", fmt.Sprintf("0:s:%d", i))
 			}
 		}
@@ -287,14 +510,8 @@ func (fs *FFmpegService) RemoveStreamsByCodec(ctx context.Context, inputFile, ou
 		return err
 	}
 
-	if !fs.hasStreamWithCodec(probeResult, streamType, codec) {
-		logger.Infof("No %s streams with codec %s found in %s; skipping removal", streamType, codec, inputFile)
-		return fs.copyFile(ctx, inputFile, outputPath, progress, "No streams removed; file copied")
-	}
-
-	args := fs.buildRemoveByCodecArgs(inputFile, outputPath, streamType, codec, probeResult)
-
-	if err := fs.runFFmpeg(ctx, args); err != nil {
+	step := fs.removeByCodecStep(inputFile, outputPath, streamType, codec, probeResult)
+	if err := fs.runStep(ctx, step); err != nil {
 		return fmt.Errorf("ffmpeg codec removal failed: %w", err)
 	}
 
@@ -306,6 +523,17 @@ func (fs *FFmpegService) RemoveStreamsByCodec(ctx context.Context, inputFile, ou
 	return nil
 }
 
+// removeByCodecStep plans the removal of the streams of a codec. Files
+// without such streams are only copied.
+func (fs *FFmpegService) removeByCodecStep(inputFile, outputPath, streamType, codec string, probeResult *medias.FfprobeResult) *PlanStep {
+	if !fs.hasStreamWithCodec(probeResult, streamType, codec) {
+		logger.Infof("No %s streams with codec %s found in %s; skipping removal", streamType, codec, inputFile)
+		return copyStep(inputFile, outputPath, probeResult, "No streams removed; file copied")
+	}
+	args := fs.buildRemoveByCodecArgs(inputFile, outputPath, streamType, codec, probeResult)
+	return newPlanStep(inputFile, outputPath, args, probeResult, "")
+}
+
 // hasStreamWithCodec checks if the file has streams of the specified type and codec
 func (fs *FFmpegService) hasStreamWithCodec(probeResult *medias.FfprobeResult, streamType, codec string) bool {
 	switch strings.ToLower(streamType) {
@@ -379,52 +607,25 @@ func (fs *FFmpegService) buildRemoveByCodecArgs(inputFile, outputPath, streamTyp
 	return args
 }
 
-// KeepOnlyStreamsByLanguage keeps only streams matching a specific language
-func (fs *FFmpegService) KeepOnlyStreamsByLanguage(ctx context.Context, inputFile, outputPath, streamType, language string, progress ProgressCallback) error {
-	logger.Infof("Keeping only %s streams with language %s from %s", streamType, language, inputFile)
-
-	var streamSelector string
-	switch strings.ToLower(streamType) {
-	case "audio":
-		streamSelector = "a"
-	case "subtitle":
-		streamSelector = "s"
-	default:
-		return fmt.Errorf("unsupported stream type for language keeping: %s", streamType)
-	}
-
-	args := []string{
-		"-i", inputFile,
-		"-map", "0:v", // Keep all video
-		"-map", fmt.Sprintf("0:%s:m:language:%s", streamSelector, language), // Keep only streams matching language
-		"-map_metadata", "0",
-		"-c", "copy",
-		outputPath,
-		"-y",
-	}
-
-	cmd := exec.CommandContext(ctx, fs.ffmpegPath, args...)
-	output, err := cmd.CombinedOutput()
+// BatchRemoveStreams applies stream removal to multiple files and returns
+// the outcome of every file
+func (fs *FFmpegService) BatchRemoveStreams(ctx context.Context, files []*medias.FfprobeResult, operation string, criteria map[string]string, outputDir string, progress ProgressCallback) (*BatchResult, error) {
+	plan, err := fs.PlanRemoveStreams(ctx, files, operation, criteria, outputDir)
 	if err != nil {
-		return fmt.Errorf("ffmpeg language keeping failed: %w\nOutput: %s", err, string(output))
-	}
-
-	if progress != nil {
-		progress(1.0, fmt.Sprintf("Kept only %s streams with language %s", streamType, language))
+		return nil, err
 	}
-
-	logger.Infof("Successfully kept only %s streams with language %s", streamType, language)
-	return nil
+	return fs.ExecutePlan(ctx, plan, progress)
 }
 
-// BatchRemoveStreams applies stream removal to multiple files
-func (fs *FFmpegService) BatchRemoveStreams(ctx context.Context, files []*medias.FfprobeResult, operation string, criteria map[string]string, outputDir string, progress ProgressCallback) ([]string, error) {
-	results := make([]string, 0, len(files))
+// PlanRemoveStreams resolves the ffmpeg run of a stream removal for every
+// file without running it. Files that can't be probed are listed as issues.
+func (fs *FFmpegService) PlanRemoveStreams(ctx context.Context, files []*medias.FfprobeResult, operation string, criteria map[string]string, outputDir string) (*Plan, error) {
+	plan := &Plan{Operation: operation}
 
-	for i, file := range files {
+	for _, file := range files {
 		select {
 		case <-ctx.Done():
-			return results, ctx.Err()
+			return plan, ctx.Err()
 		default:
 		}
 
@@ -432,51 +633,76 @@ func (fs *FFmpegService) BatchRemoveStreams(ctx context.Context, files []*medias
 		inputPath := file.Format.Filename
 		outputPath := filepath.Join(outputDir, fmt.Sprintf("processed_%s", filepath.Base(inputPath)))
 
-		var err error
-		switch operation {
-		case "remove_by_type":
-			err = fs.RemoveStreamsByType(ctx, inputPath, outputPath, criteria["type"], nil)
-		case "remove_by_language":
-			err = fs.RemoveStreamsByLanguage(ctx, inputPath, outputPath, criteria["type"], criteria["language"], nil)
-		case "remove_by_codec":
-			err = fs.RemoveStreamsByCodec(ctx, inputPath, outputPath, criteria["type"], criteria["codec"], nil)
-		case "keep_language":
-			err = fs.KeepOnlyStreamsByLanguage(ctx, inputPath, outputPath, criteria["type"], criteria["language"], nil)
-		default:
-			err = fmt.Errorf("unknown operation: %s", operation)
+		probeResult, err := fs.probeFile(ctx, inputPath)
+		var step *PlanStep
+		if err == nil {
+			step, err = fs.planRemoveStep(operation, criteria, inputPath, outputPath, probeResult)
 		}
-
 		if err != nil {
-			logger.Warnf("Failed to process %s: %v", inputPath, err)
+			logger.Warnf("Failed to plan %s: %v", inputPath, err)
+			plan.Issues = append(plan.Issues, PlanIssue{InputPath: inputPath, Err: err})
 			continue
 		}
 
-		results = append(results, outputPath)
+		plan.Steps = append(plan.Steps, step)
+	}
+
+	return plan, nil
+}
 
-		if progress != nil {
-			progress(float64(i+1)/float64(len(files)), fmt.Sprintf("Processed %d/%d files", i+1, len(files)))
+// planRemoveStep plans a stream removal operation on one file
+func (fs *FFmpegService) planRemoveStep(operation string, criteria map[string]string, inputPath, outputPath string, probeResult *medias.FfprobeResult) (*PlanStep, error) {
+	switch operation {
+	case "remove_by_type":
+		args, err := buildRemoveByTypeArgs(inputPath, outputPath, criteria["type"])
+		if err != nil {
+			return nil, err
 		}
+		return newPlanStep(inputPath, outputPath, args, probeResult, ""), nil
+	case "remove_by_language":
+		return fs.removeByLanguageStep(inputPath, outputPath, criteria["type"], criteria["language"], probeResult), nil
+	case "remove_by_codec":
+		return fs.removeByCodecStep(inputPath, outputPath, criteria["type"], criteria["codec"], probeResult), nil
+	case "keep_language":
+		step, _, err := keepLanguagesStep(inputPath, outputPath, criteria["type"], ParseLanguageList(criteria["languages"]), probeResult)
+		return step, err
+	case "remove_chapters":
+		return newPlanStep(inputPath, outputPath, buildRemoveChaptersArgs(inputPath, outputPath), probeResult, ""), nil
+	default:
+		return nil, fmt.Errorf("unknown operation: %s", operation)
 	}
-
-	return results, nil
 }
 
 // VideoCheckResult contains the result of a video integrity check
 type VideoCheckResult struct {
-	FilePath  string
-	IsValid   bool
-	Error     string
-	Duration  float64
-	HasErrors bool
+	FilePath  string  `json:"file_path"`
+	IsValid   bool    `json:"is_valid"`
+	Error     string  `json:"error,omitempty"`
+	Duration  float64 `json:"duration"`
+	HasErrors bool    `json:"has_errors"`
+	// Options are the settings the file was checked with
+	Options   CheckOptions `json:"options"`
+	CheckedAt time.Time    `json:"checked_at"`
+	// Skipped is set when the file wasn't checked again because it passed
+	// an equally thorough check and didn't change since; the result is that
+	// of the earlier check
+	Skipped bool `json:"skipped,omitempty"`
+	// NoStreams is set when the file has none of the streams the options
+	// check, e.g. an audio-only file checked in video mode. Nothing was
+	// checked: the file isn't reported as corrupted, nor recorded in the history.
+	NoStreams bool `json:"no_streams,omitempty"`
 }
 
-// CheckVideoIntegrity checks if a video file is corrupted
-func (fs *FFmpegService) CheckVideoIntegrity(ctx context.Context, inputFile string, progress ProgressCallback) (*VideoCheckResult, error) {
-	logger.Infof("Checking video integrity for %s", inputFile)
+// CheckVideoIntegrity checks if a video file is corrupted, as thoroughly as options.Mode asks
+func (fs *FFmpegService) CheckVideoIntegrity(ctx context.Context, inputFile string, options CheckOptions, progress ProgressCallback) (*VideoCheckResult, error) {
+	options = options.normalized()
+	logger.Infof("Checking video integrity for %s (%s)", inputFile, options)
 
 	result := &VideoCheckResult{
-		FilePath: inputFile,
-		IsValid:  true,
+		FilePath:  inputFile,
+		IsValid:   true,
+		Options:   options,
+		CheckedAt: time.Now(),
 	}
 
 	// First, get video duration using ffprobe for progress calculation
@@ -487,42 +713,37 @@ func (fs *FFmpegService) CheckVideoIntegrity(ctx context.Context, inputFile stri
 	}
 	result.Duration = duration
 
-	// Use ffmpeg to decode the entire video and check for errors with progress
-	args := []string{
-		"-progress", "pipe:2", // Send progress to stderr
-		"-i", inputFile,
-		"-f", "null", // No output file
-		"-",
-	}
-
-	cmd := exec.CommandContext(ctx, fs.ffmpegPath, args...)
-
-	// Capture stderr for progress and errors
-	stderr, err := cmd.StderrPipe()
-	if err != nil {
-		return nil, fmt.Errorf("failed to get stderr pipe: %w", err)
+	if options.Streams != CheckStreamsAll {
+		if found, err := fs.hasStreams(ctx, inputFile, options.Streams); err == nil && !found {
+			logger.Infof("Skipping %s: no %s stream", inputFile, options.Streams)
+			result.NoStreams = true
+			result.Error = fmt.Sprintf("no %s stream to check", options.Streams)
+			if progress != nil {
+				progress(1.0, result.Error)
+			}
+			return result, nil
+		} else if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
 	}
 
-	errorOutput := fs.captureProgress(stderr, duration, progress)
-
-	if err := cmd.Start(); err != nil {
-		return nil, fmt.Errorf("failed to start ffmpeg: %w", err)
+	var problems []string
+	switch options.Mode {
+	case CheckModeQuick:
+		problems, err = fs.quickCheck(ctx, inputFile, options, progress)
+	case CheckModeSampled:
+		problems, err = fs.sampledCheck(ctx, inputFile, time.Duration(duration*float64(time.Second)), options, progress)
+	default:
+		problems, err = fs.fullCheck(ctx, inputFile, duration, options, progress)
 	}
-
-	if err := cmd.Wait(); err != nil {
-		// Check if there were actual errors in the output
-		if strings.Contains(errorOutput, "error") || strings.Contains(errorOutput, "Error") {
-			result.IsValid = false
-			result.HasErrors = true
-			result.Error = errorOutput
-		}
+	if err != nil {
+		return nil, err
 	}
 
-	// Check for errors in output
-	if strings.Contains(errorOutput, "error") || strings.Contains(errorOutput, "Error") {
+	if len(problems) > 0 {
 		result.IsValid = false
 		result.HasErrors = true
-		result.Error = errorOutput
+		result.Error = strings.Join(problems, "\n")
 	}
 
 	if progress != nil {
@@ -537,6 +758,42 @@ func (fs *FFmpegService) CheckVideoIntegrity(ctx context.Context, inputFile stri
 	return result, nil
 }
 
+// fullCheck decodes the entire video and returns the errors ffmpeg reported
+func (fs *FFmpegService) fullCheck(ctx context.Context, inputFile string, duration float64, options CheckOptions, progress ProgressCallback) ([]string, error) {
+	args := append([]string{"-progress", "pipe:1"}, options.decodeArgs(inputFile, 0, 0)...)
+
+	cmd := exec.CommandContext(ctx, fs.ffmpegPath, args...)
+
+	// Progress reports go to stdout, so every stderr line is an error
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get stdout pipe: %w", err)
+	}
+
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("failed to start ffmpeg: %w", err)
+	}
+
+	fs.captureProgress(stdout, duration, progress)
+	waitErr := cmd.Wait()
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+
+	problems := make([]string, 0)
+	for _, line := range strings.Split(stderr.String(), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			problems = append(problems, line)
+		}
+	}
+	if waitErr != nil && len(problems) == 0 {
+		problems = append(problems, fmt.Sprintf("ffmpeg failed: %v", waitErr))
+	}
+	return problems, nil
+}
+
 func (fs *FFmpegService) captureProgress(stderrPipe io.ReadCloser, duration float64, progress ProgressCallback) string {
 	// Parse progress from stderr
 	errorOutput := ""
@@ -600,51 +857,114 @@ func (fs *FFmpegService) getVideoDuration(inputFile string) (float64, error) {
 	return duration, nil
 }
 
-// BatchCheckVideos checks multiple video files for corruption
-func (fs *FFmpegService) BatchCheckVideos(ctx context.Context, files []*medias.FfprobeResult, progress ProgressCallback) ([]*VideoCheckResult, error) {
-	results := make([]*VideoCheckResult, 0, len(files))
-
+// BatchCheckVideos checks multiple video files for corruption. The batch is
+// journaled so it can be resumed with ResumeBatch. Every check is recorded in
+// the check history; with options.SkipPassed, the files that passed before
+// and didn't change since are not checked again.
+func (fs *FFmpegService) BatchCheckVideos(ctx context.Context, files []*medias.FfprobeResult, options CheckOptions, progress ProgressCallback) ([]*VideoCheckResult, error) {
+	options = options.normalized()
+	entries := make([]*JournalEntry, len(files))
 	for i, file := range files {
-		select {
-		case <-ctx.Done():
-			return results, ctx.Err()
-		default:
+		entries[i] = &JournalEntry{InputPath: file.Format.Filename, Status: EntryPending}
+		if !options.SkipPassed {
+			continue
 		}
+		if record, ok := fs.checkHistory.Passed(file.Format.Filename, options); ok {
+			logger.Infof("Skipping %s: unchanged since it passed on %s", file.Format.Filename, record.CheckedAt.Format(time.RFC3339))
+			entries[i].Status = EntryDone
+			entries[i].Check = &VideoCheckResult{
+				FilePath:  file.Format.Filename,
+				IsValid:   true,
+				Duration:  file.Format.DurationSeconds.Seconds(),
+				Options:   record.Options,
+				CheckedAt: record.CheckedAt,
+				Skipped:   true,
+			}
+		}
+	}
+	journal := fs.journals.newJournal(BatchKindCheckVideos, options.String(), entries)
+	fs.journals.record(journal, func() {
+		journal.CheckOptions = &options
+	})
 
-		inputPath := file.Format.Filename
+	err := fs.runCheckEntries(ctx, journal, progress)
+	return checkResults(journal), err
+}
+
+// runCheckEntries checks the pending files of a journal in parallel, with the
+// check options of the journal
+func (fs *FFmpegService) runCheckEntries(ctx context.Context, journal *BatchJournal, progress ProgressCallback) error {
+	total := len(journal.Entries)
+	options := DefaultCheckOptions()
+	if journal.CheckOptions != nil {
+		options = *journal.CheckOptions
+	}
+	tracker := newProgressTracker(journal, progress)
+
+	paths := func(entry *JournalEntry) []string {
+		return []string{entry.InputPath}
+	}
+
+	defer fs.checkHistory.Flush()
+
+	return fs.runEntries(ctx, journal, fs.parallel.CheckJobs, paths, func(ctx context.Context, index int, entry *JournalEntry) {
+		inputPath := entry.InputPath
 
 		// Create a progress callback for individual file
 		fileProgress := func(fileProgressPercent float64, message string) {
-			if progress != nil {
-				// Calculate overall progress: (completed files + current file progress) / total files
-				overallProgress := (float64(i) + fileProgressPercent) / float64(len(files))
-				progress(overallProgress, fmt.Sprintf("[%d/%d] %s: %s", i+1, len(files), filepath.Base(inputPath), message))
-			}
+			tracker.update(index, fileProgressPercent, fmt.Sprintf("[%d/%d] %s: %s", index+1, total, filepath.Base(inputPath), message))
 		}
 
-		result, err := fs.CheckVideoIntegrity(ctx, inputPath, fileProgress)
-		if err != nil {
-			logger.Warnf("Failed to check %s: %v", inputPath, err)
-			result = &VideoCheckResult{
-				FilePath:  inputPath,
-				IsValid:   false,
-				HasErrors: true,
-				Error:     err.Error(),
-			}
+		started := time.Now()
+		result, err := fs.CheckVideoIntegrity(ctx, inputPath, options, fileProgress)
+		if err != nil && ctx.Err() != nil {
+			// Cancelled: the file stays pending and is checked again on resume
+			return
 		}
 
-		results = append(results, result)
-
-		if progress != nil {
-			status := "✓ OK"
-			if !result.IsValid {
-				status = "✗ CORRUPTED"
+		fs.journals.record(journal, func() {
+			entry.Duration = time.Since(started)
+			if err != nil {
+				logger.Warnf("Failed to check %s: %v", inputPath, err)
+				result = &VideoCheckResult{
+					FilePath:  inputPath,
+					IsValid:   false,
+					HasErrors: true,
+					Error:     err.Error(),
+					Options:   options,
+				}
+				entry.Status = EntryFailed
+				entry.Error = err.Error()
+			} else {
+				entry.Status = EntryDone
+				if !result.NoStreams {
+					fs.checkHistory.record(result)
+				}
 			}
-			progress(float64(i+1)/float64(len(files)), fmt.Sprintf("Checked %d/%d files - %s: %s", i+1, len(files), filepath.Base(inputPath), status))
+			entry.Check = result
+		})
+
+		status := "✓ OK"
+		if result.NoStreams {
+			status = "– " + result.Error
+		} else if !result.IsValid {
+			status = "✗ CORRUPTED"
 		}
-	}
+		tracker.complete(index, func(done, total int) string {
+			return fmt.Sprintf("Checked %d/%d files - %s: %s", done, total, filepath.Base(inputPath), status)
+		})
+	})
+}
 
-	return results, nil
+// checkResults returns the results of the checked files of a journal
+func checkResults(journal *BatchJournal) []*VideoCheckResult {
+	results := make([]*VideoCheckResult, 0, len(journal.Entries))
+	for _, entry := range journal.Entries {
+		if entry.Check != nil {
+			results = append(results, entry.Check)
+		}
+	}
+	return results
 }
 
 // createConcatList creates a temporary file list for FFmpeg concat demuxer
@@ -655,14 +975,20 @@ func (fs *FFmpegService) createConcatList(files []string) (string, error) {
 	}
 	defer tmpFile.Close()
 
+	if _, err := tmpFile.WriteString(concatListContent(files)); err != nil {
+		return "", err
+	}
+
+	return tmpFile.Name(), nil
+}
+
+// concatListContent returns the file list read by the FFmpeg concat demuxer
+func concatListContent(files []string) string {
+	var content strings.Builder
 	for _, file := range files {
 		// Escape single quotes and wrap in quotes
 		escapedPath := strings.ReplaceAll(file, "'", "'\\''")
-		_, err := fmt.Fprintf(tmpFile, "file '%s'\n", escapedPath)
-		if err != nil {
-			return "", err
-		}
+		fmt.Fprintf(&content, "file '%s'\n", escapedPath)
 	}
-
-	return tmpFile.Name(), nil
+	return content.String()
 }
@@ -50,6 +50,12 @@ const (
 	FieldHasVideo       FilterField = "HAS_VIDEO"
 	FieldHasAudio       FilterField = "HAS_AUDIO"
 	FieldHasSubtitles   FilterField = "HAS_SUBTITLES"
+	FieldHasChapters    FilterField = "HAS_CHAPTERS"
+	FieldChapterCount   FilterField = "CHAPTER_COUNT"
+	FieldQualityScore   FilterField = "QUALITY_SCORE"
+	FieldAudioLoudness  FilterField = "AUDIO_LOUDNESS"
+	FieldAudioTruePeak  FilterField = "AUDIO_TRUE_PEAK"
+	FieldAudioLRA       FilterField = "AUDIO_LRA"
 )
 
 // FilterCondition represents a single filter condition
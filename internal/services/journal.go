@@ -0,0 +1,248 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Developpeur-du-dimanche/MediaTools/pkg/logger"
+)
+
+const (
+	// JournalVersion is the version of the batch journal format
+	JournalVersion = 1
+	// JournalsDirName is the directory of the batch journals in the app storage
+	JournalsDirName = "journals"
+	// journalFileExtension is the extension of batch journal files
+	journalFileExtension = ".journal.json"
+)
+
+// BatchKind is the operation a batch journal records
+type BatchKind string
+
+const (
+	// BatchKindRemoveStreams records a plan of stream removals
+	BatchKindRemoveStreams BatchKind = "remove_streams"
+	// BatchKindCheckVideos records integrity checks
+	BatchKindCheckVideos BatchKind = "check_videos"
+	// BatchKindNormalizeLoudness records a plan of loudness normalizations
+	BatchKindNormalizeLoudness BatchKind = "normalize_loudness"
+	// BatchKindContactSheets is a contact sheet export; it only writes new
+	// images and isn't journaled
+	BatchKindContactSheets BatchKind = "contact_sheets"
+)
+
+// RunsSteps reports whether the batch runs the planned ffmpeg steps of a
+// Plan, whose outcome is shown file by file and can be retried
+func (k BatchKind) RunsSteps() bool {
+	return k == BatchKindRemoveStreams || k == BatchKindNormalizeLoudness
+}
+
+// EntryStatus is the state of a file in a batch journal
+type EntryStatus string
+
+const (
+	EntryPending EntryStatus = "pending"
+	EntryDone    EntryStatus = "done"
+	EntryFailed  EntryStatus = "failed"
+)
+
+// JournalEntry is a file of a batch
+type JournalEntry struct {
+	InputPath  string      `json:"input_path"`
+	OutputPath string      `json:"output_path,omitempty"`
+	Status     EntryStatus `json:"status"`
+	Error      string      `json:"error,omitempty"`
+	// Duration is the time the file took
+	Duration time.Duration `json:"duration,omitempty"`
+	// Step is the planned ffmpeg run of stream removals
+	Step *PlanStep `json:"step,omitempty"`
+	// Check is the result of integrity checks
+	Check *VideoCheckResult `json:"check,omitempty"`
+}
+
+// BatchJournal records the progress of a batch so that it can be resumed
+// after a crash or when the app is closed halfway through
+type BatchJournal struct {
+	Version   int             `json:"version"`
+	Kind      BatchKind       `json:"kind"`
+	Operation string          `json:"operation,omitempty"`
+	StartedAt time.Time       `json:"started_at"`
+	UpdatedAt time.Time       `json:"updated_at"`
+	Entries   []*JournalEntry `json:"entries"`
+	// CheckOptions are the options of integrity checks; journals without
+	// them were fully decoded
+	CheckOptions *CheckOptions `json:"check_options,omitempty"`
+
+	path string
+}
+
+// Counts returns the number of pending, done and failed files
+func (j *BatchJournal) Counts() (pending, done, failed int) {
+	for _, entry := range j.Entries {
+		switch entry.Status {
+		case EntryPending:
+			pending++
+		case EntryDone:
+			done++
+		case EntryFailed:
+			failed++
+		}
+	}
+	return pending, done, failed
+}
+
+// JournalStore persists batch journals in a directory. A nil store keeps
+// journals in memory only.
+type JournalStore struct {
+	dir string
+	mu  sync.Mutex
+}
+
+// NewJournalStore creates a store writing journals in dir
+func NewJournalStore(dir string) *JournalStore {
+	return &JournalStore{dir: dir}
+}
+
+// newJournal creates and saves the journal of a new batch
+func (js *JournalStore) newJournal(kind BatchKind, operation string, entries []*JournalEntry) *BatchJournal {
+	journal := &BatchJournal{
+		Version:   JournalVersion,
+		Kind:      kind,
+		Operation: operation,
+		StartedAt: time.Now(),
+		Entries:   entries,
+	}
+	if js != nil {
+		journal.path = filepath.Join(js.dir, fmt.Sprintf("%s_%d%s", kind, journal.StartedAt.UnixNano(), journalFileExtension))
+	}
+	js.update(journal)
+	return journal
+}
+
+// update saves a journal, or deletes it once no file is pending. Failures are
+// logged: a batch isn't stopped because its journal can't be written.
+func (js *JournalStore) update(journal *BatchJournal) {
+	if js == nil {
+		return
+	}
+	js.mu.Lock()
+	defer js.mu.Unlock()
+	js.save(journal)
+}
+
+// record applies a change to the entries of a running batch and saves the
+// journal. Files processed in parallel must change their entry through it.
+func (js *JournalStore) record(journal *BatchJournal, change func()) {
+	if js == nil {
+		change()
+		return
+	}
+	js.mu.Lock()
+	defer js.mu.Unlock()
+	change()
+	js.save(journal)
+}
+
+// save writes a journal; the caller holds the lock
+func (js *JournalStore) save(journal *BatchJournal) {
+	if journal.path == "" {
+		return
+	}
+
+	if pending, _, _ := journal.Counts(); pending == 0 {
+		if err := os.Remove(journal.path); err != nil && !os.IsNotExist(err) {
+			logger.Warnf("Failed to remove journal %s: %v", journal.path, err)
+		}
+		return
+	}
+
+	journal.UpdatedAt = time.Now()
+	data, err := json.MarshalIndent(journal, "", "  ")
+	if err != nil {
+		logger.Warnf("Failed to encode journal: %v", err)
+		return
+	}
+
+	if err := os.MkdirAll(js.dir, 0755); err != nil {
+		logger.Warnf("Failed to create journal directory: %v", err)
+		return
+	}
+
+	// Write then rename so a crash never leaves a truncated journal
+	tmpPath := journal.path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0644); err != nil {
+		logger.Warnf("Failed to write journal: %v", err)
+		return
+	}
+	if err := os.Rename(tmpPath, journal.path); err != nil {
+		logger.Warnf("Failed to write journal: %v", err)
+	}
+}
+
+// Unfinished returns the journals of the batches that still have pending files
+func (js *JournalStore) Unfinished() ([]*BatchJournal, error) {
+	entries, err := os.ReadDir(js.dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read journals: %w", err)
+	}
+
+	journals := make([]*BatchJournal, 0)
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), journalFileExtension) {
+			continue
+		}
+		path := filepath.Join(js.dir, entry.Name())
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			logger.Warnf("Failed to read journal %s: %v", path, err)
+			continue
+		}
+		var journal BatchJournal
+		if err := json.Unmarshal(data, &journal); err != nil || journal.Version > JournalVersion {
+			logger.Warnf("Ignoring invalid journal %s", path)
+			continue
+		}
+		journal.path = path
+
+		if pending, _, _ := journal.Counts(); pending > 0 {
+			journals = append(journals, &journal)
+		}
+	}
+	return journals, nil
+}
+
+// Discard abandons a batch: the half-written outputs of its pending files
+// and its journal are deleted
+func (js *JournalStore) Discard(journal *BatchJournal) error {
+	cleanPartialOutputs(journal)
+
+	js.mu.Lock()
+	defer js.mu.Unlock()
+	if err := os.Remove(journal.path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove journal: %w", err)
+	}
+	logger.Infof("Discarded %s batch journal %s", journal.Kind, journal.path)
+	return nil
+}
+
+// cleanPartialOutputs deletes the half-written outputs of the pending files
+func cleanPartialOutputs(journal *BatchJournal) {
+	for _, entry := range journal.Entries {
+		if entry.Status != EntryPending || entry.OutputPath == "" {
+			continue
+		}
+		partialPath := partialOutputPath(entry.OutputPath)
+		if err := os.Remove(partialPath); err == nil {
+			logger.Infof("Removed half-written output %s", partialPath)
+		}
+	}
+}
@@ -0,0 +1,238 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/Developpeur-du-dimanche/MediaTools/pkg/languages"
+	"github.com/Developpeur-du-dimanche/MediaTools/pkg/logger"
+	"github.com/Developpeur-du-dimanche/MediaTools/pkg/medias"
+)
+
+// KeepRule tells which rule chose the tracks kept by KeepOnlyStreamsByLanguage
+type KeepRule string
+
+const (
+	// KeepRulePreferred means tracks in the preferred languages were found
+	KeepRulePreferred KeepRule = "preferred"
+	// KeepRuleOriginal means no preferred language was found; the original language tracks were kept
+	KeepRuleOriginal KeepRule = "original"
+	// KeepRuleDefault means no preferred or original track was found; the default tracks were kept
+	KeepRuleDefault KeepRule = "default"
+	// KeepRuleFirst means no track was flagged; the first audio track was kept so the file isn't silent
+	KeepRuleFirst KeepRule = "first"
+	// KeepRuleNone means the file has no track of the processed type, or no subtitle matched
+	KeepRuleNone KeepRule = "none"
+)
+
+// KeepLanguagesReport describes what KeepOnlyStreamsByLanguage kept in a file
+type KeepLanguagesReport struct {
+	InputPath  string
+	OutputPath string
+	Rule       KeepRule
+	// KeptLanguages are the canonical languages of the kept tracks, in output order
+	KeptLanguages []string
+	// ForcedSubtitles is the number of forced subtitles kept for the audio languages
+	ForcedSubtitles int
+}
+
+// String returns a one line summary of the report
+func (r *KeepLanguagesReport) String() string {
+	summary := fmt.Sprintf("%s: %s", filepath.Base(r.InputPath), r.Rule)
+	if len(r.KeptLanguages) > 0 {
+		summary += fmt.Sprintf(" (%s)", strings.Join(r.KeptLanguages, ", "))
+	}
+	if r.ForcedSubtitles > 0 {
+		summary += fmt.Sprintf(", %d forced subtitles", r.ForcedSubtitles)
+	}
+	return summary
+}
+
+// ParseLanguageList splits a comma separated list of languages and
+// normalizes them to canonical codes, keeping their order
+func ParseLanguageList(value string) []string {
+	list := make([]string, 0)
+	seen := make(map[string]bool)
+	for _, language := range strings.Split(value, ",") {
+		if strings.TrimSpace(language) == "" {
+			continue
+		}
+		code := languages.Canonical(language)
+		if !seen[code] {
+			seen[code] = true
+			list = append(list, code)
+		}
+	}
+	return list
+}
+
+// keepTrack is a track of the processed type, with the flags used by the fallback rules
+type keepTrack struct {
+	language  string
+	original  bool
+	isDefault bool
+}
+
+// selectTracks returns the indexes of the tracks to keep, by order of
+// preference, and the rule that chose them. Tracks in the preferred
+// languages win; otherwise the original, then the default tracks are kept.
+// When firstFallback is set, the first track is kept as a last resort.
+func selectTracks(tracks []keepTrack, preferred []string, firstFallback bool) ([]int, KeepRule) {
+	if len(tracks) == 0 {
+		return nil, KeepRuleNone
+	}
+
+	kept := make([]int, 0)
+	taken := make(map[int]bool)
+	for _, language := range preferred {
+		for i, track := range tracks {
+			if !taken[i] && languages.Match(track.language, language) {
+				kept = append(kept, i)
+				taken[i] = true
+			}
+		}
+	}
+	if len(kept) > 0 {
+		return kept, KeepRulePreferred
+	}
+
+	for i, track := range tracks {
+		if track.original {
+			kept = append(kept, i)
+		}
+	}
+	if len(kept) > 0 {
+		return kept, KeepRuleOriginal
+	}
+
+	for i, track := range tracks {
+		if track.isDefault {
+			kept = append(kept, i)
+		}
+	}
+	if len(kept) > 0 {
+		return kept, KeepRuleDefault
+	}
+
+	if firstFallback {
+		return []int{0}, KeepRuleFirst
+	}
+	return nil, KeepRuleNone
+}
+
+// KeepOnlyStreamsByLanguage keeps the audio or subtitle streams in the
+// preferred languages, in order of preference. Video and the streams of the
+// other type are kept.
+//
+// When no audio stream is in a preferred language, the original, then the
+// default, then the first audio stream is kept so that the output is never
+// silent. Forced subtitles in the language of a kept audio stream are always kept.
+func (fs *FFmpegService) KeepOnlyStreamsByLanguage(ctx context.Context, inputFile, outputPath, streamType string, preferred []string, progress ProgressCallback) (*KeepLanguagesReport, error) {
+	logger.Infof("Keeping only %s streams with languages %v from %s", streamType, preferred, inputFile)
+
+	probeResult, err := fs.probeFile(ctx, inputFile)
+	if err != nil {
+		return nil, err
+	}
+
+	step, report, err := keepLanguagesStep(inputFile, outputPath, streamType, preferred, probeResult)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := fs.runStep(ctx, step); err != nil {
+		return nil, fmt.Errorf("ffmpeg language keeping failed: %w", err)
+	}
+
+	if progress != nil {
+		progress(1.0, fmt.Sprintf("Kept %s streams: %s", streamType, report))
+	}
+
+	logger.Infof("Kept %s streams of %s with rule %s: %v", streamType, inputFile, report.Rule, report.KeptLanguages)
+	return report, nil
+}
+
+// keepLanguagesStep plans KeepOnlyStreamsByLanguage on a probed file. The
+// note of the step is the report summary.
+func keepLanguagesStep(inputFile, outputPath, streamType string, preferred []string, probeResult *medias.FfprobeResult) (*PlanStep, *KeepLanguagesReport, error) {
+	streamType = strings.ToLower(streamType)
+	if streamType != "audio" && streamType != "subtitle" {
+		return nil, nil, fmt.Errorf("unsupported stream type for language keeping: %s", streamType)
+	}
+
+	report := &KeepLanguagesReport{
+		InputPath:  inputFile,
+		OutputPath: outputPath,
+	}
+
+	// Audio streams to keep, all of them when processing subtitles
+	audioIndexes := make([]int, len(probeResult.Audios))
+	for i := range probeResult.Audios {
+		audioIndexes[i] = i
+	}
+	if streamType == "audio" {
+		tracks := make([]keepTrack, len(probeResult.Audios))
+		for i, audio := range probeResult.Audios {
+			tracks[i] = keepTrack{language: audio.Language, original: audio.Original, isDefault: audio.Default}
+		}
+		audioIndexes, report.Rule = selectTracks(tracks, preferred, true)
+		for _, i := range audioIndexes {
+			report.KeptLanguages = append(report.KeptLanguages, languages.Canonical(probeResult.Audios[i].Language))
+		}
+	}
+
+	// Subtitle streams to keep, all of them when processing audio
+	subtitleIndexes := make([]int, len(probeResult.Subtitles))
+	for i := range probeResult.Subtitles {
+		subtitleIndexes[i] = i
+	}
+	if streamType == "subtitle" {
+		tracks := make([]keepTrack, len(probeResult.Subtitles))
+		for i, subtitle := range probeResult.Subtitles {
+			tracks[i] = keepTrack{language: subtitle.Language, isDefault: subtitle.Default}
+		}
+		subtitleIndexes, report.Rule = selectTracks(tracks, preferred, false)
+		for _, i := range subtitleIndexes {
+			report.KeptLanguages = append(report.KeptLanguages, languages.Canonical(probeResult.Subtitles[i].Language))
+		}
+
+		// Always keep the forced subtitles of the kept audio languages
+		kept := make(map[int]bool)
+		for _, i := range subtitleIndexes {
+			kept[i] = true
+		}
+		for i, subtitle := range probeResult.Subtitles {
+			if !subtitle.Forced || kept[i] {
+				continue
+			}
+			for _, a := range audioIndexes {
+				if languages.Match(subtitle.Language, probeResult.Audios[a].Language) {
+					subtitleIndexes = append(subtitleIndexes, i)
+					report.ForcedSubtitles++
+					break
+				}
+			}
+		}
+	}
+
+	args := []string{
+		"-i", inputFile,
+		"-map", "0:v?", // Keep all video
+	}
+	for _, i := range audioIndexes {
+		args = append(args, "-map", fmt.Sprintf("0:a:%d", i))
+	}
+	for _, i := range subtitleIndexes {
+		args = append(args, "-map", fmt.Sprintf("0:s:%d", i))
+	}
+	args = append(args,
+		"-map_metadata", "0",
+		"-c", "copy",
+		outputPath,
+		"-y",
+	)
+
+	return newPlanStep(inputFile, outputPath, args, probeResult, report.String()), report, nil
+}
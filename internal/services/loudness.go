@@ -0,0 +1,398 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/Developpeur-du-dimanche/MediaTools/pkg/logger"
+	"github.com/Developpeur-du-dimanche/MediaTools/pkg/medias"
+)
+
+const (
+	// minLoudness is the lowest value loudnorm accepts as a measurement;
+	// silence measures -inf and is stored as minLoudness
+	minLoudness = -99.0
+	// silentLoudness is the EBU R128 absolute gate: quieter streams are
+	// silent and never normalized
+	silentLoudness = -70.0
+	// loudnessTolerance is how far from the target a stream may be and
+	// still be copied instead of normalized, in LU
+	loudnessTolerance = 1.0
+	// defaultAudioSampleRate is used when the sample rate of a stream is unknown
+	defaultAudioSampleRate = "48000"
+)
+
+// loudnessEncoders are the encoders normalized streams are encoded back to,
+// by codec name. Other codecs, such as DTS or TrueHD, become AAC.
+var loudnessEncoders = map[string]string{
+	"aac":    "aac",
+	"ac3":    "ac3",
+	"eac3":   "eac3",
+	"mp3":    "libmp3lame",
+	"opus":   "libopus",
+	"vorbis": "libvorbis",
+	"flac":   "flac",
+	"alac":   "alac",
+}
+
+// losslessEncoders are the encoders without a bitrate
+var losslessEncoders = map[string]bool{
+	"flac": true,
+	"alac": true,
+}
+
+// LoudnessTarget is the loudness normalized audio streams get
+type LoudnessTarget struct {
+	// Integrated is the integrated loudness in LUFS
+	Integrated float64
+	// TruePeak is the maximum true peak in dBTP
+	TruePeak float64
+	// LRA is the maximum loudness range in LU. Streams with a wider range are
+	// compressed; the others only get a gain.
+	LRA float64
+}
+
+// DefaultLoudnessTarget returns the EBU R128 broadcast target
+func DefaultLoudnessTarget() LoudnessTarget {
+	return LoudnessTarget{
+		Integrated: -23,
+		TruePeak:   -1,
+		LRA:        11,
+	}
+}
+
+// validate checks the target is within the ranges of loudnorm
+func (t LoudnessTarget) validate() error {
+	switch {
+	case t.Integrated < -70 || t.Integrated > -5:
+		return fmt.Errorf("target loudness must be between -70 and -5 LUFS")
+	case t.TruePeak < -9 || t.TruePeak > 0:
+		return fmt.Errorf("target true peak must be between -9 and 0 dBTP")
+	case t.LRA < 1 || t.LRA > 20:
+		return fmt.Errorf("target loudness range must be between 1 and 20 LU")
+	}
+	return nil
+}
+
+// reached reports whether a stream already has the target loudness
+func (t LoudnessTarget) reached(loudness medias.Loudness) bool {
+	return math.Abs(loudness.Integrated-t.Integrated) <= loudnessTolerance && loudness.TruePeak <= t.TruePeak
+}
+
+// filter returns the second pass of loudnorm for a measured stream
+func (t LoudnessTarget) filter(loudness medias.Loudness) string {
+	return fmt.Sprintf("loudnorm=I=%g:TP=%g:LRA=%g:measured_I=%.2f:measured_TP=%.2f:measured_LRA=%.2f:measured_thresh=%.2f:linear=true",
+		t.Integrated, t.TruePeak, t.LRA,
+		loudness.Integrated, loudness.TruePeak, loudness.LRA, loudness.Threshold,
+	)
+}
+
+// LoudnessResult is the outcome of the loudness analysis of a file
+type LoudnessResult struct {
+	File *medias.FfprobeResult
+	Err  error
+}
+
+// loudnormReport is the JSON report printed by the first pass of loudnorm
+type loudnormReport struct {
+	InputI      string `json:"input_i"`
+	InputTP     string `json:"input_tp"`
+	InputLRA    string `json:"input_lra"`
+	InputThresh string `json:"input_thresh"`
+}
+
+// LoudnessMeasured reports whether every audio stream of a file was analyzed
+func LoudnessMeasured(file *medias.FfprobeResult) bool {
+	for _, audio := range file.Audios {
+		if audio.Loudness == nil {
+			return false
+		}
+	}
+	return true
+}
+
+// AnalyzeLoudness measures the loudness of every audio stream of a file and
+// stores it in the streams. It is the first pass of the normalization.
+func (fs *FFmpegService) AnalyzeLoudness(ctx context.Context, file *medias.FfprobeResult, progress ProgressCallback) error {
+	inputPath := file.Format.Filename
+	duration := file.Format.DurationSeconds.Seconds()
+	total := len(file.Audios)
+
+	measured := make([]*medias.Loudness, total)
+	for i, audio := range file.Audios {
+		streamProgress := func(streamProgressPercent float64, _ string) {
+			if progress != nil {
+				progress((float64(i)+streamProgressPercent)/float64(total), fmt.Sprintf("Measuring audio stream %d/%d...", i+1, total))
+			}
+		}
+
+		loudness, err := fs.measureLoudness(ctx, inputPath, audio.StreamIndex, duration, streamProgress)
+		if err != nil {
+			return fmt.Errorf("failed to measure audio stream #%d: %w", audio.StreamIndex, err)
+		}
+		measured[i] = loudness
+	}
+
+	// The streams are only updated once every one of them was measured
+	for i := range file.Audios {
+		file.Audios[i].Loudness = measured[i]
+	}
+	logger.Infof("Measured the loudness of %d audio streams of %s", total, inputPath)
+	return nil
+}
+
+// measureLoudness runs the first pass of loudnorm on a stream
+func (fs *FFmpegService) measureLoudness(ctx context.Context, inputPath string, streamIndex int, duration float64, progress ProgressCallback) (*medias.Loudness, error) {
+	args := []string{
+		"-hide_banner",
+		"-nostats",
+		"-progress", "pipe:2",
+		"-i", inputPath,
+		"-map", fmt.Sprintf("0:%d", streamIndex),
+		"-af", "loudnorm=print_format=json",
+		"-f", "null",
+		"-",
+	}
+
+	cmd := exec.CommandContext(ctx, fs.ffmpegPath, args...)
+	stderr, err := cmd.StderrPipe()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get stderr pipe: %w", err)
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("failed to start ffmpeg: %w", err)
+	}
+
+	output := fs.captureProgress(stderr, duration, progress)
+	waitErr := cmd.Wait()
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+
+	loudness, err := parseLoudnormReport(output)
+	if err != nil && waitErr != nil {
+		return nil, fmt.Errorf("ffmpeg failed: %w", waitErr)
+	}
+	return loudness, err
+}
+
+// parseLoudnormReport reads the measurements of the first pass of loudnorm,
+// the last JSON object of the ffmpeg output
+func parseLoudnormReport(output string) (*medias.Loudness, error) {
+	start := strings.LastIndex(output, "{")
+	end := strings.LastIndex(output, "}")
+	if start < 0 || end < start {
+		return nil, fmt.Errorf("no loudness report in ffmpeg output")
+	}
+
+	var report loudnormReport
+	if err := json.Unmarshal([]byte(output[start:end+1]), &report); err != nil {
+		return nil, fmt.Errorf("invalid loudness report: %w", err)
+	}
+
+	values := make([]float64, 4)
+	for i, text := range []string{report.InputI, report.InputTP, report.InputLRA, report.InputThresh} {
+		value, err := strconv.ParseFloat(strings.TrimSpace(text), 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid loudness value %q: %w", text, err)
+		}
+		values[i] = max(value, minLoudness)
+	}
+
+	return &medias.Loudness{
+		Integrated: values[0],
+		TruePeak:   values[1],
+		LRA:        values[2],
+		Threshold:  values[3],
+	}, nil
+}
+
+// BatchAnalyzeLoudness measures the audio streams of multiple files in
+// parallel. A file that fails doesn't stop the batch.
+func (fs *FFmpegService) BatchAnalyzeLoudness(ctx context.Context, files []*medias.FfprobeResult, progress ProgressCallback) ([]*LoudnessResult, error) {
+	// Analyses only read the files: they aren't journaled for resume
+	journal := &BatchJournal{Entries: make([]*JournalEntry, len(files))}
+	for i, file := range files {
+		journal.Entries[i] = &JournalEntry{InputPath: file.Format.Filename, Status: EntryPending}
+	}
+	results := make([]*LoudnessResult, len(files))
+	tracker := newProgressTracker(journal, progress)
+
+	paths := func(entry *JournalEntry) []string {
+		return []string{entry.InputPath}
+	}
+
+	err := fs.runEntries(ctx, journal, fs.parallel.CheckJobs, paths, func(ctx context.Context, index int, entry *JournalEntry) {
+		fileProgress := func(fileProgressPercent float64, message string) {
+			tracker.update(index, fileProgressPercent, fmt.Sprintf("[%d/%d] %s: %s", index+1, len(files), filepath.Base(entry.InputPath), message))
+		}
+
+		err := fs.AnalyzeLoudness(ctx, files[index], fileProgress)
+		if err != nil && ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			logger.Warnf("Failed to analyze %s: %v", entry.InputPath, err)
+		}
+		results[index] = &LoudnessResult{File: files[index], Err: err}
+
+		tracker.complete(index, func(done, total int) string {
+			return fmt.Sprintf("Analyzed %d/%d files", done, total)
+		})
+	})
+
+	analyzed := make([]*LoudnessResult, 0, len(files))
+	for _, result := range results {
+		if result != nil {
+			analyzed = append(analyzed, result)
+		}
+	}
+	return analyzed, err
+}
+
+// PlanNormalizeLoudness plans a two-pass EBU R128 normalization of the audio
+// streams of files. Streams that weren't analyzed yet are measured first;
+// the plan re-encodes the audio streams away from the target and copies
+// every other stream.
+func (fs *FFmpegService) PlanNormalizeLoudness(ctx context.Context, files []*medias.FfprobeResult, outputDir string, target LoudnessTarget, progress ProgressCallback) (*Plan, error) {
+	if err := target.validate(); err != nil {
+		return nil, err
+	}
+	plan := &Plan{Kind: BatchKindNormalizeLoudness, Operation: "normalize_loudness"}
+
+	// First pass, on the files not analyzed yet
+	unmeasured := make([]*medias.FfprobeResult, 0)
+	for _, file := range files {
+		if !LoudnessMeasured(file) {
+			unmeasured = append(unmeasured, file)
+		}
+	}
+	failed := make(map[*medias.FfprobeResult]error)
+	if len(unmeasured) > 0 {
+		results, err := fs.BatchAnalyzeLoudness(ctx, unmeasured, progress)
+		if err != nil {
+			return plan, err
+		}
+		for _, result := range results {
+			if result.Err != nil {
+				failed[result.File] = result.Err
+			}
+		}
+	}
+
+	for _, file := range files {
+		select {
+		case <-ctx.Done():
+			return plan, ctx.Err()
+		default:
+		}
+
+		inputPath := file.Format.Filename
+		outputPath := filepath.Join(outputDir, fmt.Sprintf("normalized_%s", filepath.Base(inputPath)))
+
+		err := failed[file]
+		var step *PlanStep
+		if err == nil {
+			var probeResult *medias.FfprobeResult
+			probeResult, err = fs.probeFile(ctx, inputPath)
+			if err == nil {
+				step, err = normalizeLoudnessStep(inputPath, outputPath, file, probeResult, target)
+			}
+		}
+		if err != nil {
+			logger.Warnf("Failed to plan %s: %v", inputPath, err)
+			plan.Issues = append(plan.Issues, PlanIssue{InputPath: inputPath, Err: err})
+			continue
+		}
+
+		plan.Steps = append(plan.Steps, step)
+	}
+
+	return plan, nil
+}
+
+// normalizeLoudnessStep plans the second pass of loudnorm on the audio
+// streams of a file, with the measurements stored in file
+func normalizeLoudnessStep(inputPath, outputPath string, file, probeResult *medias.FfprobeResult, target LoudnessTarget) (*PlanStep, error) {
+	if len(probeResult.Audios) == 0 {
+		return nil, fmt.Errorf("no audio stream to normalize")
+	}
+	if len(probeResult.Audios) != len(file.Audios) {
+		return nil, fmt.Errorf("the audio streams changed since the scan, scan the file again")
+	}
+
+	args := []string{
+		"-i", inputPath,
+		"-map", "0",
+		"-c", "copy",
+	}
+	changes := make([]string, 0, len(probeResult.Audios))
+	normalized := 0
+	for k, audio := range probeResult.Audios {
+		loudness := file.Audios[k].Loudness
+		if loudness == nil || file.Audios[k].StreamIndex != audio.StreamIndex {
+			return nil, fmt.Errorf("the audio streams changed since the scan, scan the file again")
+		}
+
+		switch {
+		case loudness.Integrated <= silentLoudness:
+			changes = append(changes, fmt.Sprintf("audio #%d silent, copied", k))
+			continue
+		case target.reached(*loudness):
+			changes = append(changes, fmt.Sprintf("audio #%d at %.1f LUFS, copied", k, loudness.Integrated))
+			continue
+		}
+
+		encoder, bitrate := loudnessEncoder(audio)
+		sampleRate := audio.SampleRate
+		if sampleRate == "" || sampleRate == "0" {
+			sampleRate = defaultAudioSampleRate
+		}
+		// loudnorm resamples to 192 kHz: the original rate is restored
+		args = append(args,
+			fmt.Sprintf("-filter:a:%d", k), target.filter(*loudness),
+			fmt.Sprintf("-c:a:%d", k), encoder,
+			fmt.Sprintf("-ar:a:%d", k), sampleRate,
+		)
+		if bitrate != "" {
+			args = append(args, fmt.Sprintf("-b:a:%d", k), bitrate)
+		}
+		changes = append(changes, fmt.Sprintf("audio #%d %.1f → %g LUFS (%s)", k, loudness.Integrated, target.Integrated, encoder))
+		normalized++
+	}
+
+	note := strings.Join(changes, "; ")
+	if normalized == 0 {
+		// Still mapped with -map 0: the default mapping drops all but one audio and subtitle stream
+		note = "Already at the target loudness; file copied"
+	}
+
+	args = append(args, outputPath, "-y")
+	return newPlanStep(inputPath, outputPath, args, probeResult, note), nil
+}
+
+// loudnessEncoder returns the encoder and bitrate of a normalized stream:
+// the codec and bitrate of the stream when ffmpeg can encode it, AAC otherwise
+func loudnessEncoder(audio medias.Audio) (string, string) {
+	encoder, ok := loudnessEncoders[audio.CodecName]
+	if strings.HasPrefix(audio.CodecName, "pcm_") {
+		encoder, ok = audio.CodecName, true
+	}
+	if !ok {
+		encoder = "aac"
+	}
+
+	if losslessEncoders[encoder] || strings.HasPrefix(encoder, "pcm_") {
+		return encoder, ""
+	}
+	if bitrate, err := strconv.ParseInt(audio.Bitrate, 10, 64); ok && err == nil && bitrate > 0 {
+		return encoder, strconv.FormatInt(bitrate, 10)
+	}
+	return encoder, fmt.Sprintf("%dk", 64*max(2, audio.Channels))
+}
@@ -61,6 +61,7 @@ func (ms *MediaService) GetMediaInfo(ctx context.Context, filePath string) (*med
 		medias.PRINT_FORMAT_JSON,
 		medias.SHOW_FORMAT,
 		medias.SHOW_STREAMS,
+		medias.SHOW_CHAPTERS,
 		medias.EXPERIMENTAL,
 	)
 
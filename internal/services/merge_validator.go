@@ -0,0 +1,151 @@
+package services
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/Developpeur-du-dimanche/MediaTools/pkg/medias"
+)
+
+// MergeMode selects how MergeVideos joins its inputs
+type MergeMode string
+
+const (
+	// MergeModeCopy uses the concat demuxer with stream copy (fast, needs compatible inputs)
+	MergeModeCopy MergeMode = "copy"
+	// MergeModeReencode uses the concat filter and re-encodes to a common format
+	MergeModeReencode MergeMode = "reencode"
+)
+
+// MergeMismatch describes a property that differs between the reference input and another input
+type MergeMismatch struct {
+	FilePath string
+	Property string
+	Expected string
+	Actual   string
+}
+
+// String returns a human-readable description of the mismatch
+func (m MergeMismatch) String() string {
+	return fmt.Sprintf("%s: %s is %s (expected %s)", filepath.Base(m.FilePath), m.Property, m.Actual, m.Expected)
+}
+
+// MergeCompatibilityReport lists every mismatch found between merge inputs.
+// The first input is used as the reference all other inputs are compared to.
+type MergeCompatibilityReport struct {
+	Reference  string
+	Mismatches []MergeMismatch
+	// MissingVideo lists the inputs without a video stream, which can't be merged at all
+	MissingVideo []string
+}
+
+// IsCompatible reports whether the inputs can be joined with stream copy
+func (r *MergeCompatibilityReport) IsCompatible() bool {
+	return len(r.Mismatches) == 0 && len(r.MissingVideo) == 0
+}
+
+// CanMerge reports whether the inputs can be merged, with stream copy or by re-encoding
+func (r *MergeCompatibilityReport) CanMerge() bool {
+	return len(r.MissingVideo) == 0
+}
+
+// String returns the report as a multi-line text
+func (r *MergeCompatibilityReport) String() string {
+	if r.IsCompatible() {
+		return "All inputs are compatible for stream copy."
+	}
+
+	lines := make([]string, 0, len(r.Mismatches)+len(r.MissingVideo)+2)
+	if len(r.MissingVideo) > 0 {
+		lines = append(lines, fmt.Sprintf("%d input(s) without a video stream can't be merged:", len(r.MissingVideo)))
+		for _, path := range r.MissingVideo {
+			lines = append(lines, "  - "+filepath.Base(path))
+		}
+	}
+	if len(r.Mismatches) > 0 {
+		lines = append(lines, fmt.Sprintf("%d mismatch(es) with %s:", len(r.Mismatches), filepath.Base(r.Reference)))
+		for _, mismatch := range r.Mismatches {
+			lines = append(lines, "  - "+mismatch.String())
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// ValidateMergeInputs compares the probe results of all merge inputs and
+// reports the properties that would break a concat with stream copy
+func ValidateMergeInputs(files []*medias.FfprobeResult) *MergeCompatibilityReport {
+	report := &MergeCompatibilityReport{
+		Mismatches: make([]MergeMismatch, 0),
+	}
+	if len(files) == 0 {
+		return report
+	}
+
+	for _, file := range files {
+		if len(file.Videos) == 0 {
+			report.MissingVideo = append(report.MissingVideo, file.Format.Filename)
+		}
+	}
+
+	reference := files[0]
+	report.Reference = reference.Format.Filename
+
+	for _, file := range files[1:] {
+		report.Mismatches = append(report.Mismatches, compareMergeInput(reference, file)...)
+	}
+
+	return report
+}
+
+// compareMergeInput returns the mismatches between a file and the reference
+func compareMergeInput(reference, file *medias.FfprobeResult) []MergeMismatch {
+	mismatches := make([]MergeMismatch, 0)
+	path := file.Format.Filename
+
+	check := func(property, expected, actual string) {
+		if !strings.EqualFold(expected, actual) {
+			mismatches = append(mismatches, MergeMismatch{
+				FilePath: path,
+				Property: property,
+				Expected: displayValue(expected),
+				Actual:   displayValue(actual),
+			})
+		}
+	}
+
+	check("container", strings.TrimPrefix(filepath.Ext(reference.Format.Filename), "."), strings.TrimPrefix(filepath.Ext(path), "."))
+	check("video stream count", fmt.Sprint(len(reference.Videos)), fmt.Sprint(len(file.Videos)))
+	check("audio stream count", fmt.Sprint(len(reference.Audios)), fmt.Sprint(len(file.Audios)))
+	check("subtitle stream count", fmt.Sprint(len(reference.Subtitles)), fmt.Sprint(len(file.Subtitles)))
+
+	for i := 0; i < len(reference.Videos) && i < len(file.Videos); i++ {
+		expected, actual := reference.Videos[i], file.Videos[i]
+		prefix := fmt.Sprintf("video #%d ", i)
+		check(prefix+"codec", expected.CodecName, actual.CodecName)
+		check(prefix+"resolution", fmt.Sprintf("%dx%d", expected.Width, expected.Height), fmt.Sprintf("%dx%d", actual.Width, actual.Height))
+		check(prefix+"frame rate", expected.FrameRate, actual.FrameRate)
+		check(prefix+"pixel format", expected.PixFmt, actual.PixFmt)
+	}
+
+	for i := 0; i < len(reference.Audios) && i < len(file.Audios); i++ {
+		expected, actual := reference.Audios[i], file.Audios[i]
+		prefix := fmt.Sprintf("audio #%d ", i)
+		check(prefix+"codec", expected.CodecName, actual.CodecName)
+		check(prefix+"sample rate", expected.SampleRate, actual.SampleRate)
+		check(prefix+"channels", fmt.Sprint(expected.Channels), fmt.Sprint(actual.Channels))
+	}
+
+	for i := 0; i < len(reference.Subtitles) && i < len(file.Subtitles); i++ {
+		check(fmt.Sprintf("subtitle #%d codec", i), reference.Subtitles[i].CodecName, file.Subtitles[i].CodecName)
+	}
+
+	return mismatches
+}
+
+func displayValue(value string) string {
+	if value == "" {
+		return "unknown"
+	}
+	return value
+}
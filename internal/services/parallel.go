@@ -0,0 +1,213 @@
+package services
+
+import (
+	"context"
+	"runtime"
+	"sort"
+	"sync"
+)
+
+const (
+	// PreferenceKeyStreamJobs stores ParallelOptions.StreamJobs in preferences
+	PreferenceKeyStreamJobs = "parallel_stream_jobs"
+	// PreferenceKeyCheckJobs stores ParallelOptions.CheckJobs in preferences
+	PreferenceKeyCheckJobs = "parallel_check_jobs"
+	// PreferenceKeyPerDiskJobs stores ParallelOptions.PerDiskJobs in preferences
+	PreferenceKeyPerDiskJobs = "parallel_per_disk_jobs"
+)
+
+// ParallelOptions limits how many files a batch processes at once
+type ParallelOptions struct {
+	// StreamJobs is the number of stream copy jobs at once. They are I/O bound.
+	StreamJobs int
+	// CheckJobs is the number of integrity checks at once. They are CPU bound.
+	CheckJobs int
+	// PerDiskJobs is the number of jobs reading or writing the same disk at
+	// once, so that jobs don't thrash a hard drive; zero disables the limit
+	PerDiskJobs int
+}
+
+// DefaultParallelOptions runs two stream copies at once, one check per two
+// CPU cores, without a per-disk limit
+func DefaultParallelOptions() ParallelOptions {
+	return ParallelOptions{
+		StreamJobs: 2,
+		CheckJobs:  max(1, runtime.NumCPU()/2),
+	}
+}
+
+// normalized returns the options with at least one job of each kind
+func (po ParallelOptions) normalized() ParallelOptions {
+	po.StreamJobs = max(1, po.StreamJobs)
+	po.CheckJobs = max(1, po.CheckJobs)
+	po.PerDiskJobs = max(0, po.PerDiskJobs)
+	return po
+}
+
+// runEntries runs job on the pending entries of a journal with at most jobs
+// at once, and at most PerDiskJobs at once on the disks of paths(entry). It
+// returns the context error when the batch was cancelled; the entries not
+// started stay pending.
+func (fs *FFmpegService) runEntries(ctx context.Context, journal *BatchJournal, jobs int, paths func(entry *JournalEntry) []string, job func(ctx context.Context, index int, entry *JournalEntry)) error {
+	slots := make(chan struct{}, jobs)
+	disks := newDiskLimiter(fs.parallel.PerDiskJobs)
+	var wg sync.WaitGroup
+
+	for i, entry := range journal.Entries {
+		if entry.Status != EntryPending {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+		case slots <- struct{}{}:
+		}
+		if ctx.Err() != nil {
+			break
+		}
+
+		wg.Add(1)
+		go func(index int, entry *JournalEntry) {
+			defer wg.Done()
+			defer func() { <-slots }()
+
+			release, ok := disks.acquire(ctx, paths(entry))
+			if !ok {
+				return
+			}
+			defer release()
+
+			job(ctx, index, entry)
+		}(i, entry)
+	}
+
+	wg.Wait()
+	return ctx.Err()
+}
+
+// diskLimiter limits the number of jobs on each disk
+type diskLimiter struct {
+	limit int
+	mu    sync.Mutex
+	slots map[string]chan struct{}
+}
+
+func newDiskLimiter(limit int) *diskLimiter {
+	return &diskLimiter{
+		limit: limit,
+		slots: make(map[string]chan struct{}),
+	}
+}
+
+// acquire waits for a slot on the disk of every path. Disks are always taken
+// in the same order so that two jobs can't wait for each other. It returns
+// false when the context is cancelled while waiting.
+func (dl *diskLimiter) acquire(ctx context.Context, paths []string) (func(), bool) {
+	if dl.limit <= 0 {
+		return func() {}, true
+	}
+
+	seen := make(map[string]bool)
+	disks := make([]string, 0, len(paths))
+	for _, path := range paths {
+		if id := diskID(path); id != "" && !seen[id] {
+			seen[id] = true
+			disks = append(disks, id)
+		}
+	}
+	sort.Strings(disks)
+
+	taken := make([]chan struct{}, 0, len(disks))
+	release := func() {
+		for _, slot := range taken {
+			<-slot
+		}
+	}
+
+	for _, disk := range disks {
+		slot := dl.slot(disk)
+		select {
+		case slot <- struct{}{}:
+			taken = append(taken, slot)
+		case <-ctx.Done():
+			release()
+			return nil, false
+		}
+	}
+	return release, true
+}
+
+func (dl *diskLimiter) slot(disk string) chan struct{} {
+	dl.mu.Lock()
+	defer dl.mu.Unlock()
+	slot, ok := dl.slots[disk]
+	if !ok {
+		slot = make(chan struct{}, dl.limit)
+		dl.slots[disk] = slot
+	}
+	return slot
+}
+
+// progressTracker adds up the progress of files processed in parallel so
+// that the overall progress stays accurate
+type progressTracker struct {
+	mu       sync.Mutex
+	callback ProgressCallback
+	files    []float64
+}
+
+// newProgressTracker tracks total files; the files already finished count as done
+func newProgressTracker(journal *BatchJournal, callback ProgressCallback) *progressTracker {
+	pt := &progressTracker{
+		callback: callback,
+		files:    make([]float64, len(journal.Entries)),
+	}
+	for i, entry := range journal.Entries {
+		if entry.Status != EntryPending {
+			pt.files[i] = 1
+		}
+	}
+	return pt
+}
+
+// update records the progress of a file, from 0 to 1, and reports the overall progress
+func (pt *progressTracker) update(index int, progress float64, message string) {
+	if pt.callback == nil {
+		return
+	}
+
+	pt.mu.Lock()
+	// A file only counts as finished once complete is called
+	pt.files[index] = min(0.999, max(progress, pt.files[index]))
+	overall, _ := pt.totals()
+	pt.mu.Unlock()
+
+	pt.callback(overall, message)
+}
+
+// complete records that a file is finished; message receives the number of
+// finished files and the total
+func (pt *progressTracker) complete(index int, message func(done, total int) string) {
+	if pt.callback == nil {
+		return
+	}
+
+	pt.mu.Lock()
+	pt.files[index] = 1
+	overall, done := pt.totals()
+	pt.mu.Unlock()
+
+	pt.callback(overall, message(done, len(pt.files)))
+}
+
+// totals returns the overall progress and the number of finished files; the caller holds the lock
+func (pt *progressTracker) totals() (float64, int) {
+	total, done := 0.0, 0
+	for _, file := range pt.files {
+		total += file
+		if file >= 1 {
+			done++
+		}
+	}
+	return total / float64(len(pt.files)), done
+}
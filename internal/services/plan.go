@@ -0,0 +1,392 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/Developpeur-du-dimanche/MediaTools/pkg/languages"
+	"github.com/Developpeur-du-dimanche/MediaTools/pkg/logger"
+	"github.com/Developpeur-du-dimanche/MediaTools/pkg/medias"
+)
+
+// planWorkDir is replaced by a temporary directory holding the plan files
+// when a step runs, and by a shell variable in scripts
+const planWorkDir = "{workdir}"
+
+// PlannedStream is a stream of the output predicted by a plan
+type PlannedStream struct {
+	Type string `json:"type"` // video, audio or subtitle
+	// Index is the index of the stream among the input streams of its type
+	Index    int    `json:"index"`
+	Codec    string `json:"codec"`
+	Language string `json:"language,omitempty"`
+	// Copy is false when the stream is encoded again
+	Copy bool `json:"copy"`
+}
+
+// String returns a short description of the stream
+func (ps PlannedStream) String() string {
+	description := fmt.Sprintf("%s #%d: %s", ps.Type, ps.Index, ps.Codec)
+	if ps.Language != "" {
+		description += ", " + languages.DisplayName(ps.Language)
+	}
+	if ps.Copy {
+		return description + " (copy)"
+	}
+	return description + " (encode)"
+}
+
+// PlanFile is a file written in the plan work directory before a step runs
+type PlanFile struct {
+	Name    string `json:"name"`
+	Content string `json:"content"`
+}
+
+// PlanStep is a single ffmpeg run of a plan
+type PlanStep struct {
+	InputPath  string `json:"input_path"`
+	OutputPath string `json:"output_path"`
+	// Args are the resolved ffmpeg arguments. Paths of plan files start with the work directory placeholder.
+	Args    []string        `json:"args"`
+	Files   []PlanFile      `json:"files,omitempty"`
+	Streams []PlannedStream `json:"streams"`
+	// Expect is what the output is verified against before it is kept
+	Expect OutputExpectation `json:"expect"`
+	// Note explains a decision taken while planning, e.g. why a file is only copied
+	Note string `json:"note,omitempty"`
+}
+
+// PlanIssue is a file that couldn't be planned
+type PlanIssue struct {
+	InputPath string
+	Err       error
+}
+
+// Plan is the list of ffmpeg runs an operation will do. The same plan is
+// shown in dry runs and executed, so the preview matches the real run.
+type Plan struct {
+	// Kind is the kind of the journal of the run; empty journals the plan as
+	// BatchKindRemoveStreams
+	Kind      BatchKind
+	Operation string
+	Steps     []*PlanStep
+	Issues    []PlanIssue
+}
+
+// newPlanStep creates a step and predicts its output streams from the args
+func newPlanStep(inputPath, outputPath string, args []string, probe *medias.FfprobeResult, note string) *PlanStep {
+	step := &PlanStep{
+		InputPath:  inputPath,
+		OutputPath: outputPath,
+		Args:       args,
+		Streams:    predictStreams(probe, args),
+		Note:       note,
+	}
+	if probe != nil {
+		step.Expect = expectPlannedStreams(probe.Format.DurationSeconds, step.Streams)
+	}
+	return step
+}
+
+// copyStep creates a step copying a file without modification
+func copyStep(inputPath, outputPath string, probe *medias.FfprobeResult, note string) *PlanStep {
+	args := []string{
+		"-i", inputPath,
+		"-c", "copy",
+		outputPath,
+		"-y",
+	}
+	return newPlanStep(inputPath, outputPath, args, probe, note)
+}
+
+// ExecutePlan runs every step of a plan and returns the outcome of every
+// file. Failed steps, including outputs that fail verification, don't stop
+// the batch; files that couldn't be planned are reported as failed. The batch
+// is journaled so it can be resumed with ResumeBatch.
+func (fs *FFmpegService) ExecutePlan(ctx context.Context, plan *Plan, progress ProgressCallback) (*BatchResult, error) {
+	entries := make([]*JournalEntry, 0, len(plan.Steps)+len(plan.Issues))
+	for _, step := range plan.Steps {
+		entries = append(entries, &JournalEntry{
+			InputPath:  step.InputPath,
+			OutputPath: step.OutputPath,
+			Status:     EntryPending,
+			Step:       step,
+		})
+	}
+	for _, issue := range plan.Issues {
+		entries = append(entries, &JournalEntry{
+			InputPath: issue.InputPath,
+			Status:    EntryFailed,
+			Error:     issue.Err.Error(),
+		})
+	}
+	kind := plan.Kind
+	if kind == "" {
+		kind = BatchKindRemoveStreams
+	}
+	journal := fs.journals.newJournal(kind, plan.Operation, entries)
+
+	err := fs.runStepEntries(ctx, journal, false, progress)
+	return newBatchResult(journal), err
+}
+
+// runStepEntries runs the pending steps of a journal in parallel. When
+// resuming, outputs that already exist and pass verification are kept and
+// their step is skipped.
+func (fs *FFmpegService) runStepEntries(ctx context.Context, journal *BatchJournal, resume bool, progress ProgressCallback) error {
+	tracker := newProgressTracker(journal, progress)
+
+	paths := func(entry *JournalEntry) []string {
+		return []string{entry.InputPath, entry.OutputPath}
+	}
+
+	return fs.runEntries(ctx, journal, fs.parallel.StreamJobs, paths, func(ctx context.Context, index int, entry *JournalEntry) {
+		if resume {
+			if _, err := fs.verifyOutput(ctx, entry.OutputPath, entry.Step.Expect); err == nil {
+				logger.Infof("Keeping verified output %s", entry.OutputPath)
+				fs.journals.record(journal, func() {
+					entry.Status = EntryDone
+				})
+				tracker.complete(index, processedMessage)
+				return
+			}
+		}
+
+		started := time.Now()
+		err := fs.runStep(ctx, entry.Step)
+		if err != nil && ctx.Err() != nil {
+			// Cancelled: the file stays pending and runs again on resume
+			return
+		}
+
+		fs.journals.record(journal, func() {
+			entry.Duration = time.Since(started)
+			if err != nil {
+				logger.Warnf("Failed to process %s: %v", entry.InputPath, err)
+				entry.Status = EntryFailed
+				entry.Error = err.Error()
+			} else {
+				entry.Status = EntryDone
+			}
+		})
+
+		tracker.complete(index, processedMessage)
+	})
+}
+
+func processedMessage(done, total int) string {
+	return fmt.Sprintf("Processed %d/%d files", done, total)
+}
+
+// ResumeBatch runs the pending files of an interrupted batch. Half-written
+// outputs are deleted first.
+func (fs *FFmpegService) ResumeBatch(ctx context.Context, journal *BatchJournal, progress ProgressCallback) (*BatchResult, error) {
+	logger.Infof("Resuming %s batch started at %s", journal.Kind, journal.StartedAt)
+	cleanPartialOutputs(journal)
+
+	var err error
+	switch journal.Kind {
+	case BatchKindRemoveStreams, BatchKindNormalizeLoudness:
+		err = fs.runStepEntries(ctx, journal, true, progress)
+	case BatchKindCheckVideos:
+		err = fs.runCheckEntries(ctx, journal, progress)
+	default:
+		return nil, fmt.Errorf("unknown batch kind: %s", journal.Kind)
+	}
+	return newBatchResult(journal), err
+}
+
+// runStep writes the files of a step in a temporary directory, runs ffmpeg
+// and verifies the output
+func (fs *FFmpegService) runStep(ctx context.Context, step *PlanStep) error {
+	args := step.Args
+	if len(step.Files) > 0 {
+		workDir, err := os.MkdirTemp("", "mediatools_plan_*")
+		if err != nil {
+			return fmt.Errorf("failed to create work directory: %w", err)
+		}
+		defer os.RemoveAll(workDir)
+
+		for _, file := range step.Files {
+			if err := os.WriteFile(filepath.Join(workDir, file.Name), []byte(file.Content), 0644); err != nil {
+				return fmt.Errorf("failed to write %s: %w", file.Name, err)
+			}
+		}
+
+		args = make([]string, len(step.Args))
+		for i, arg := range step.Args {
+			args[i] = strings.ReplaceAll(arg, planWorkDir, workDir)
+		}
+	}
+
+	return fs.runVerified(ctx, args, step.OutputPath, step.Expect)
+}
+
+// ShellScript returns the plan as a POSIX shell script running ffmpegPath
+func (p *Plan) ShellScript(ffmpegPath string) string {
+	var script strings.Builder
+	script.WriteString("#!/bin/sh\n")
+	fmt.Fprintf(&script, "# MediaTools plan: %s, %d files\n", p.Operation, len(p.Steps))
+
+	for _, issue := range p.Issues {
+		fmt.Fprintf(&script, "# Skipped %s: %v\n", issue.InputPath, issue.Err)
+	}
+
+	for _, step := range p.Steps {
+		if len(step.Files) > 0 {
+			script.WriteString("\nPLAN_WORKDIR=\"$(mktemp -d)\"\n")
+			script.WriteString("trap 'rm -rf \"$PLAN_WORKDIR\"' EXIT\n")
+			break
+		}
+	}
+
+	for i, step := range p.Steps {
+		fmt.Fprintf(&script, "\n# [%d/%d] %s -> %s\n", i+1, len(p.Steps), step.InputPath, step.OutputPath)
+		if step.Note != "" {
+			fmt.Fprintf(&script, "# %s\n", step.Note)
+		}
+		script.WriteString("# Output streams:\n")
+		for _, stream := range step.Streams {
+			fmt.Fprintf(&script, "#   %s\n", stream)
+		}
+
+		for _, file := range step.Files {
+			fmt.Fprintf(&script, "cat > \"$PLAN_WORKDIR\"/%s <<'MEDIATOOLS_EOF'\n%s\nMEDIATOOLS_EOF\n", shellQuote(file.Name), strings.TrimRight(file.Content, "\n"))
+		}
+
+		words := make([]string, 0, len(step.Args)+1)
+		words = append(words, shellQuote(ffmpegPath))
+		for _, arg := range step.Args {
+			words = append(words, shellArg(arg))
+		}
+		script.WriteString(strings.Join(words, " "))
+		script.WriteString("\n")
+	}
+
+	return script.String()
+}
+
+// shellArg quotes an argument, expanding the work directory placeholder
+func shellArg(arg string) string {
+	if rest, ok := strings.CutPrefix(arg, planWorkDir); ok {
+		return "\"$PLAN_WORKDIR\"" + shellQuote(rest)
+	}
+	return shellQuote(arg)
+}
+
+func shellQuote(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+}
+
+// predictStreams predicts the output streams of single input ffmpeg args
+// from their -map, -vn/-an/-sn and codec options
+func predictStreams(probe *medias.FfprobeResult, args []string) []PlannedStream {
+	if probe == nil {
+		return nil
+	}
+
+	all := make([]PlannedStream, 0, len(probe.Videos)+len(probe.Audios)+len(probe.Subtitles))
+	for i, video := range probe.Videos {
+		all = append(all, PlannedStream{Type: "video", Index: i, Codec: video.CodecName})
+	}
+	for i, audio := range probe.Audios {
+		all = append(all, PlannedStream{Type: "audio", Index: i, Codec: audio.CodecName, Language: audio.Language})
+	}
+	for i, subtitle := range probe.Subtitles {
+		all = append(all, PlannedStream{Type: "subtitle", Index: i, Codec: subtitle.CodecName, Language: subtitle.Language})
+	}
+
+	options := make(map[string]string)
+	var maps []string
+	for i := 0; i < len(args); i++ {
+		switch args[i] {
+		case "-vn", "-an", "-sn":
+			options[args[i]] = "true"
+		case "-map":
+			if i+1 < len(args) {
+				maps = append(maps, args[i+1])
+				i++
+			}
+		default:
+			if (args[i] == "-c" || strings.HasPrefix(args[i], "-c:")) && i+1 < len(args) {
+				options[args[i]] = args[i+1]
+				i++
+			}
+		}
+	}
+
+	var streams []PlannedStream
+	if len(maps) == 0 {
+		// Without -map, ffmpeg keeps one stream of each type
+		for _, streamType := range []string{"video", "audio", "subtitle"} {
+			if options["-"+streamType[:1]+"n"] != "" {
+				continue
+			}
+			for _, stream := range all {
+				if stream.Type == streamType {
+					streams = append(streams, stream)
+					break
+				}
+			}
+		}
+	} else {
+		for _, spec := range maps {
+			negative := strings.HasPrefix(spec, "-")
+			spec = strings.TrimSuffix(strings.TrimPrefix(spec, "-"), "?")
+			if negative {
+				kept := streams[:0]
+				for _, stream := range streams {
+					if !mapMatches(spec, stream) {
+						kept = append(kept, stream)
+					}
+				}
+				streams = kept
+				continue
+			}
+			for _, stream := range all {
+				if mapMatches(spec, stream) {
+					streams = append(streams, stream)
+				}
+			}
+		}
+	}
+
+	// An encoder of an output stream, such as -c:a:1, wins over one of its type
+	typeIndex := make(map[string]int)
+	for i := range streams {
+		streamType := streams[i].Type[:1]
+		encoder := options[fmt.Sprintf("-c:%s:%d", streamType, typeIndex[streamType])]
+		typeIndex[streamType]++
+		if encoder == "" {
+			encoder = options["-c:"+streamType]
+		}
+		if encoder == "" {
+			encoder = options["-c"]
+		}
+		streams[i].Copy = encoder == "copy"
+		if encoder != "" && encoder != "copy" {
+			streams[i].Codec = encoder
+		}
+	}
+	return streams
+}
+
+// mapMatches reports whether a -map specifier of the first input, such as
+// "0", "0:a" or "0:s:2", selects a stream
+func mapMatches(spec string, stream PlannedStream) bool {
+	parts := strings.Split(spec, ":")
+	if parts[0] != "0" {
+		return false
+	}
+	if len(parts) == 1 {
+		return true
+	}
+	if parts[1] != stream.Type[:1] {
+		return false
+	}
+	return len(parts) == 2 || parts[2] == fmt.Sprint(stream.Index)
+}
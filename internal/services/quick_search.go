@@ -0,0 +1,133 @@
+package services
+
+import (
+	"strings"
+
+	"github.com/Developpeur-du-dimanche/MediaTools/internal/filters"
+	"github.com/Developpeur-du-dimanche/MediaTools/pkg/medias"
+)
+
+// quickSearchAliases maps the short field names of the quick search to filter fields.
+// The filter keys themselves (e.g. "video_codec") are accepted too.
+var quickSearchAliases = map[string]FilterField{
+	"codec":    FieldVideoCodec,
+	"vcodec":   FieldVideoCodec,
+	"acodec":   FieldAudioCodec,
+	"lang":     FieldAudioLanguage,
+	"alang":    FieldAudioLanguage,
+	"sub":      FieldSubLanguage,
+	"slang":    FieldSubLanguage,
+	"width":    FieldWidth,
+	"height":   FieldHeight,
+	"duration": FieldDuration,
+	"bitrate":  FieldBitrate,
+	"fps":      FieldFramerate,
+	"channels": FieldAudioChannels,
+	"chapters": FieldChapterCount,
+	"score":    FieldQualityScore,
+	"lufs":     FieldAudioLoudness,
+	"peak":     FieldAudioTruePeak,
+	"lra":      FieldAudioLRA,
+}
+
+// QuickSearch is a parsed quick search query. A media matches when its path
+// contains every term and it satisfies every condition.
+type QuickSearch struct {
+	Terms      []string
+	Conditions []FilterCondition
+}
+
+// IsEmpty reports whether the query matches everything
+func (qs *QuickSearch) IsEmpty() bool {
+	return qs == nil || (len(qs.Terms) == 0 && len(qs.Conditions) == 0)
+}
+
+// ParseQuickSearch parses a quick search query like "holiday codec:hevc lang:fre height:>=1080".
+//
+// A "field:value" word becomes a filter condition when field is a known alias
+// or filter key. Text fields match when they contain the value, numeric and
+// boolean fields when they are equal; a leading comparison operator (>, >=,
+// <, <=) or "!" changes that. Every other word is matched against the path.
+func (fs *FilterService) ParseQuickSearch(query string) *QuickSearch {
+	qs := &QuickSearch{}
+
+	for _, word := range strings.Fields(query) {
+		name, value, found := strings.Cut(word, ":")
+		if found && value != "" {
+			if condition, ok := fs.quickSearchCondition(name, value); ok {
+				qs.Conditions = append(qs.Conditions, condition)
+				continue
+			}
+		}
+		qs.Terms = append(qs.Terms, strings.ToLower(word))
+	}
+
+	return qs
+}
+
+// quickSearchCondition builds the filter condition of a "field:value" word
+func (fs *FilterService) quickSearchCondition(name, value string) (FilterCondition, bool) {
+	field, ok := quickSearchAliases[strings.ToLower(name)]
+	if !ok {
+		field = FilterField(strings.ToUpper(name))
+	}
+
+	filter, ok := fs.filterRegistry[field]
+	if !ok {
+		return FilterCondition{}, false
+	}
+	fieldType := filter.GetFieldConfig().Type
+
+	negate := strings.HasPrefix(value, "!")
+	value = strings.TrimPrefix(value, "!")
+
+	operator := OpEquals
+	switch {
+	case fieldType == filters.FieldTypeString && negate:
+		operator = OpNotContains
+	case fieldType == filters.FieldTypeString:
+		operator = OpContains
+	case negate:
+		operator = OpNotEquals
+	case fieldType == filters.FieldTypeNumeric:
+		for _, op := range []FilterOperator{OpGreaterEq, OpLessEq, OpGreater, OpLess} {
+			if rest, found := strings.CutPrefix(value, string(op)); found {
+				operator = op
+				value = rest
+				break
+			}
+		}
+	}
+
+	if value == "" {
+		return FilterCondition{}, false
+	}
+
+	return FilterCondition{
+		Field:    field,
+		Operator: operator,
+		Value:    value,
+	}, true
+}
+
+// MatchQuickSearch reports whether a media matches a quick search query
+func (fs *FilterService) MatchQuickSearch(media *medias.FfprobeResult, qs *QuickSearch) bool {
+	if qs.IsEmpty() {
+		return true
+	}
+
+	path := strings.ToLower(media.Format.Filename)
+	for _, term := range qs.Terms {
+		if !strings.Contains(path, term) {
+			return false
+		}
+	}
+
+	for _, condition := range qs.Conditions {
+		if !fs.evaluateCondition(media, condition) {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,172 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"github.com/Developpeur-du-dimanche/MediaTools/pkg/logger"
+	"github.com/Developpeur-du-dimanche/MediaTools/pkg/medias"
+)
+
+const (
+	// SessionVersion is the version of the session file format
+	SessionVersion = 1
+	// SessionFileExtension is the extension of session files
+	SessionFileExtension = ".mtsession"
+	// autosaveFileName is the name of the session saved on exit and restored on startup
+	autosaveFileName = "autosave" + SessionFileExtension
+)
+
+// SessionItem is a scanned file of a session, with the file state at scan time
+type SessionItem struct {
+	Media    *medias.FfprobeResult `json:"media"`
+	Selected bool                  `json:"selected,omitempty"`
+	Size     int64                 `json:"size"`
+	ModTime  time.Time             `json:"mod_time"`
+}
+
+// Session is the saved state of a scan: the scanned items, their selection,
+// the active filters and the output settings of each operation tab
+type Session struct {
+	Version     int                          `json:"version"`
+	SavedAt     time.Time                    `json:"saved_at"`
+	Items       []SessionItem                `json:"items"`
+	QuickSearch string                       `json:"quick_search,omitempty"`
+	Filter      string                       `json:"filter,omitempty"`
+	TabSettings map[string]map[string]string `json:"tab_settings,omitempty"`
+}
+
+// RevalidationReport summarizes the revalidation of a session against the filesystem
+type RevalidationReport struct {
+	Unchanged int
+	Refreshed int
+	Removed   int
+}
+
+// SessionService saves and restores scan sessions
+type SessionService struct {
+	app fyne.App
+}
+
+// NewSessionService creates a new session service instance
+func NewSessionService(app fyne.App) *SessionService {
+	return &SessionService{
+		app: app,
+	}
+}
+
+// NewSessionItem records a scanned file with its current size and modification time
+func NewSessionItem(media *medias.FfprobeResult, selected bool) SessionItem {
+	item := SessionItem{
+		Media:    media,
+		Selected: selected,
+	}
+	if info, err := os.Stat(media.Format.Filename); err == nil {
+		item.Size = info.Size()
+		item.ModTime = info.ModTime()
+	}
+	return item
+}
+
+// AutosavePath returns the path of the session saved on exit
+func (ss *SessionService) AutosavePath() string {
+	return filepath.Join(ss.app.Storage().RootURI().Path(), autosaveFileName)
+}
+
+// Save writes a session to path
+func (ss *SessionService) Save(path string, session *Session) error {
+	session.Version = SessionVersion
+	session.SavedAt = time.Now()
+
+	data, err := json.MarshalIndent(session, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode session: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create session directory: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write session: %w", err)
+	}
+
+	logger.Infof("Saved session with %d items to %s", len(session.Items), path)
+	return nil
+}
+
+// Load reads a session from path
+func (ss *SessionService) Load(path string) (*Session, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read session: %w", err)
+	}
+
+	var session Session
+	if err := json.Unmarshal(data, &session); err != nil {
+		return nil, fmt.Errorf("invalid session file: %w", err)
+	}
+	if session.Version > SessionVersion {
+		return nil, fmt.Errorf("unsupported session version %d", session.Version)
+	}
+
+	logger.Infof("Loaded session with %d items from %s", len(session.Items), path)
+	return &session, nil
+}
+
+// Revalidate checks the items of a session against the filesystem. Missing
+// files are dropped and files whose size or modification time changed are probed again.
+func (ss *SessionService) Revalidate(ctx context.Context, session *Session, mediaService *MediaService) (RevalidationReport, error) {
+	var report RevalidationReport
+	items := make([]SessionItem, 0, len(session.Items))
+
+	for _, item := range session.Items {
+		select {
+		case <-ctx.Done():
+			return report, ctx.Err()
+		default:
+		}
+
+		if item.Media == nil {
+			report.Removed++
+			continue
+		}
+
+		path := item.Media.Format.Filename
+		info, err := os.Stat(path)
+		if err != nil {
+			logger.Infof("Dropping missing session item: %s", path)
+			report.Removed++
+			continue
+		}
+
+		if info.Size() == item.Size && info.ModTime().Equal(item.ModTime) {
+			items = append(items, item)
+			report.Unchanged++
+			continue
+		}
+
+		media, err := mediaService.GetMediaInfo(ctx, path)
+		if err != nil {
+			logger.Warnf("Dropping session item that can't be probed anymore: %s: %v", path, err)
+			report.Removed++
+			continue
+		}
+
+		items = append(items, SessionItem{
+			Media:    media,
+			Selected: item.Selected,
+			Size:     info.Size(),
+			ModTime:  info.ModTime(),
+		})
+		report.Refreshed++
+	}
+
+	session.Items = items
+	logger.Infof("Session revalidated: %d unchanged, %d refreshed, %d removed", report.Unchanged, report.Refreshed, report.Removed)
+	return report, nil
+}
@@ -0,0 +1,240 @@
+package services
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Developpeur-du-dimanche/MediaTools/pkg/languages"
+	"github.com/Developpeur-du-dimanche/MediaTools/pkg/medias"
+)
+
+// DefaultWastedSpaceCount is the number of files listed in LibraryStats.MostWastedSpace
+const DefaultWastedSpaceCount = 10
+
+// Resolution buckets, from the largest to the smallest
+const (
+	Resolution2160p   = "2160p"
+	Resolution1440p   = "1440p"
+	Resolution1080p   = "1080p"
+	Resolution720p    = "720p"
+	ResolutionSD      = "SD"
+	ResolutionUnknown = "unknown"
+)
+
+// referenceBitrates is the video bitrate (bps) considered sufficient for an
+// H.264 encode of each resolution bucket. Anything above is counted as wasted space.
+var referenceBitrates = map[string]int64{
+	Resolution2160p: 20_000_000,
+	Resolution1440p: 10_000_000,
+	Resolution1080p: 8_000_000,
+	Resolution720p:  4_000_000,
+	ResolutionSD:    2_000_000,
+}
+
+// efficientCodecs need about half the bitrate of H.264 for the same quality
+var efficientCodecs = map[string]bool{
+	"hevc": true,
+	"av1":  true,
+	"vp9":  true,
+}
+
+// WastedSpace describes a file whose bitrate is above the reference for its resolution
+type WastedSpace struct {
+	Path             string `json:"path"`
+	Resolution       string `json:"resolution"`
+	VideoCodec       string `json:"video_codec"`
+	Size             int64  `json:"size"`
+	Bitrate          int64  `json:"bitrate"`
+	ReferenceBitrate int64  `json:"reference_bitrate"`
+	WastedBytes      int64  `json:"wasted_bytes"`
+}
+
+// LanguageCoverage counts the files having at least one track in each language
+type LanguageCoverage struct {
+	Languages    map[string]int `json:"languages"`
+	FilesWithout int            `json:"files_without"`
+}
+
+// LibraryStats summarizes a set of scanned files
+type LibraryStats struct {
+	FileCount            int            `json:"file_count"`
+	TotalSize            int64          `json:"total_size"`
+	TotalDuration        time.Duration  `json:"-"`
+	TotalDurationSeconds float64        `json:"total_duration_seconds"`
+	TotalBitrate         int64          `json:"total_bitrate"`
+	AverageBitrate       int64          `json:"average_bitrate"`
+	VideoCodecs          map[string]int `json:"video_codecs"`
+	AudioCodecs          map[string]int `json:"audio_codecs"`
+	SubtitleCodecs       map[string]int `json:"subtitle_codecs"`
+	Resolutions          map[string]int `json:"resolutions"`
+
+	AudioLanguages    LanguageCoverage `json:"audio_languages"`
+	SubtitleLanguages LanguageCoverage `json:"subtitle_languages"`
+
+	MostWastedSpace []WastedSpace `json:"most_wasted_space"`
+}
+
+// ComputeLibraryStats builds the statistics of the given files, listing the
+// wastedCount files with the most wasted space
+func ComputeLibraryStats(files []*medias.FfprobeResult, wastedCount int) *LibraryStats {
+	stats := &LibraryStats{
+		VideoCodecs:       make(map[string]int),
+		AudioCodecs:       make(map[string]int),
+		SubtitleCodecs:    make(map[string]int),
+		Resolutions:       make(map[string]int),
+		AudioLanguages:    LanguageCoverage{Languages: make(map[string]int)},
+		SubtitleLanguages: LanguageCoverage{Languages: make(map[string]int)},
+	}
+
+	wasted := make([]WastedSpace, 0)
+	bitrateCount := 0
+
+	for _, file := range files {
+		if file == nil {
+			continue
+		}
+		stats.FileCount++
+
+		size, _ := strconv.ParseInt(file.Format.Size, 10, 64)
+		stats.TotalSize += size
+		stats.TotalDuration += file.Format.DurationSeconds
+
+		bitrate, err := strconv.ParseInt(file.Format.Bitrate, 10, 64)
+		if err == nil && bitrate > 0 {
+			stats.TotalBitrate += bitrate
+			bitrateCount++
+		}
+
+		for _, video := range file.Videos {
+			stats.VideoCodecs[codecKey(video.CodecName)]++
+		}
+		for _, audio := range file.Audios {
+			stats.AudioCodecs[codecKey(audio.CodecName)]++
+		}
+		for _, subtitle := range file.Subtitles {
+			stats.SubtitleCodecs[codecKey(subtitle.CodecName)]++
+		}
+
+		resolution := ResolutionUnknown
+		if len(file.Videos) > 0 {
+			resolution = ResolutionBucket(file.Videos[0].Width, file.Videos[0].Height)
+		}
+		stats.Resolutions[resolution]++
+
+		audioLanguages := make([]string, 0, len(file.Audios))
+		for _, audio := range file.Audios {
+			audioLanguages = append(audioLanguages, audio.Language)
+		}
+		stats.AudioLanguages.add(audioLanguages)
+
+		subtitleLanguages := make([]string, 0, len(file.Subtitles))
+		for _, subtitle := range file.Subtitles {
+			subtitleLanguages = append(subtitleLanguages, subtitle.Language)
+		}
+		stats.SubtitleLanguages.add(subtitleLanguages)
+
+		if w, ok := wastedSpace(file, resolution, size, bitrate); ok {
+			wasted = append(wasted, w)
+		}
+	}
+
+	stats.TotalDurationSeconds = stats.TotalDuration.Seconds()
+	if bitrateCount > 0 {
+		stats.AverageBitrate = stats.TotalBitrate / int64(bitrateCount)
+	}
+
+	sort.Slice(wasted, func(i, j int) bool { return wasted[i].WastedBytes > wasted[j].WastedBytes })
+	if wastedCount >= 0 && len(wasted) > wastedCount {
+		wasted = wasted[:wastedCount]
+	}
+	stats.MostWastedSpace = wasted
+
+	return stats
+}
+
+// add records the languages of the tracks of one file
+func (lc *LanguageCoverage) add(tags []string) {
+	if len(tags) == 0 {
+		lc.FilesWithout++
+		return
+	}
+
+	seen := make(map[string]bool)
+	for _, tag := range tags {
+		language := languages.Canonical(tag)
+		if seen[language] {
+			continue
+		}
+		seen[language] = true
+		lc.Languages[language]++
+	}
+}
+
+// ResolutionBucket returns the resolution bucket of a video. The width is
+// checked too so that letterboxed encodes land in the right bucket.
+func ResolutionBucket(width, height int) string {
+	switch {
+	case width <= 0 || height <= 0:
+		return ResolutionUnknown
+	case height >= 2000 || width >= 3200:
+		return Resolution2160p
+	case height >= 1300 || width >= 2200:
+		return Resolution1440p
+	case height >= 900 || width >= 1700:
+		return Resolution1080p
+	case height >= 650 || width >= 1100:
+		return Resolution720p
+	default:
+		return ResolutionSD
+	}
+}
+
+// wastedSpace compares the video bitrate of a file with the reference bitrate of
+// its resolution and codec. The container bitrate, which includes the audio and
+// subtitle tracks, is only used when the video stream doesn't report its own.
+func wastedSpace(file *medias.FfprobeResult, resolution string, size, bitrate int64) (WastedSpace, bool) {
+	reference, ok := referenceBitrates[resolution]
+	if !ok || len(file.Videos) == 0 {
+		return WastedSpace{}, false
+	}
+	if videoBitrate, err := strconv.ParseInt(file.Videos[0].Bitrate, 10, 64); err == nil && videoBitrate > 0 {
+		bitrate = videoBitrate
+	}
+	if bitrate <= 0 {
+		return WastedSpace{}, false
+	}
+
+	codec := strings.ToLower(file.Videos[0].CodecName)
+	if efficientCodecs[codec] {
+		reference /= 2
+	}
+	if bitrate <= reference {
+		return WastedSpace{}, false
+	}
+
+	wastedBytes := int64(float64(bitrate-reference) / 8 * file.Format.DurationSeconds.Seconds())
+	if size > 0 && wastedBytes > size {
+		wastedBytes = size
+	}
+
+	return WastedSpace{
+		Path:             file.Format.Filename,
+		Resolution:       resolution,
+		VideoCodec:       codec,
+		Size:             size,
+		Bitrate:          bitrate,
+		ReferenceBitrate: reference,
+		WastedBytes:      wastedBytes,
+	}, true
+}
+
+// codecKey normalizes a codec name used as a map key
+func codecKey(codec string) string {
+	codec = strings.ToLower(strings.TrimSpace(codec))
+	if codec == "" {
+		return "unknown"
+	}
+	return codec
+}
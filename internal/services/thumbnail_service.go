@@ -0,0 +1,362 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Developpeur-du-dimanche/MediaTools/pkg/logger"
+	"github.com/Developpeur-du-dimanche/MediaTools/pkg/medias"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/math/fixed"
+)
+
+const (
+	// DefaultPosterPercent is the position of the poster frame, in percent of the duration
+	DefaultPosterPercent = 10.0
+	// DefaultThumbnailWidth is the width of cached poster frames
+	DefaultThumbnailWidth = 320
+	// maxConcurrentThumbnails limits the number of ffmpeg processes started for poster frames
+	maxConcurrentThumbnails = 2
+)
+
+// ContactSheetOptions configures a contact sheet
+type ContactSheetOptions struct {
+	Columns   int
+	Rows      int
+	TileWidth int
+}
+
+// DefaultContactSheetOptions returns a 4x4 grid of 320px wide frames
+func DefaultContactSheetOptions() ContactSheetOptions {
+	return ContactSheetOptions{
+		Columns:   4,
+		Rows:      4,
+		TileWidth: 320,
+	}
+}
+
+// ThumbnailService generates poster frames and contact sheets
+type ThumbnailService struct {
+	ffmpegService *FFmpegService
+	cacheDir      string
+
+	slots         chan struct{}
+	inFlightMutex sync.Mutex
+	inFlight      map[string]*posterLock
+}
+
+// posterLock serializes the generation of a poster frame. It is removed from
+// the in-flight map once the last caller waiting for it is done.
+type posterLock struct {
+	sync.Mutex
+	waiters int
+}
+
+// NewThumbnailService creates a new thumbnail service caching poster frames in cacheDir.
+// When cacheDir is empty, the user cache directory is used.
+func NewThumbnailService(ffmpegService *FFmpegService, cacheDir string) *ThumbnailService {
+	if cacheDir == "" {
+		userCacheDir, err := os.UserCacheDir()
+		if err != nil {
+			userCacheDir = os.TempDir()
+		}
+		cacheDir = filepath.Join(userCacheDir, "mediatools", "thumbnails")
+	}
+	return &ThumbnailService{
+		ffmpegService: ffmpegService,
+		cacheDir:      cacheDir,
+		slots:         make(chan struct{}, maxConcurrentThumbnails),
+		inFlight:      make(map[string]*posterLock),
+	}
+}
+
+// PosterFrame returns the path of a cached PNG poster frame taken at percent of the duration,
+// generating it if needed
+func (ts *ThumbnailService) PosterFrame(ctx context.Context, file *medias.FfprobeResult, percent float64) (string, error) {
+	inputPath := file.Format.Filename
+
+	cachePath, err := ts.cachePath(inputPath, percent)
+	if err != nil {
+		return "", err
+	}
+	if _, err := os.Stat(cachePath); err == nil {
+		return cachePath, nil
+	}
+
+	// Only one generation per poster frame, the other callers wait for it
+	unlock := ts.lockPoster(cachePath)
+	defer unlock()
+	if _, err := os.Stat(cachePath); err == nil {
+		return cachePath, nil
+	}
+
+	select {
+	case ts.slots <- struct{}{}:
+		defer func() { <-ts.slots }()
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+
+	at := time.Duration(float64(file.Format.DurationSeconds) * percent / 100)
+	frame, err := ts.ffmpegService.ExtractFrame(ctx, inputPath, at, DefaultThumbnailWidth)
+	if err != nil {
+		return "", err
+	}
+
+	if err := os.MkdirAll(ts.cacheDir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create thumbnail cache: %w", err)
+	}
+	if err := writePNG(cachePath, frame); err != nil {
+		return "", err
+	}
+
+	logger.Debugf("Cached poster frame for %s", inputPath)
+	return cachePath, nil
+}
+
+// lockPoster waits until no other caller generates the poster frame at cachePath
+// and returns the function releasing it
+func (ts *ThumbnailService) lockPoster(cachePath string) func() {
+	ts.inFlightMutex.Lock()
+	lock, ok := ts.inFlight[cachePath]
+	if !ok {
+		lock = &posterLock{}
+		ts.inFlight[cachePath] = lock
+	}
+	lock.waiters++
+	ts.inFlightMutex.Unlock()
+
+	lock.Lock()
+	return func() {
+		lock.Unlock()
+
+		ts.inFlightMutex.Lock()
+		lock.waiters--
+		if lock.waiters == 0 {
+			delete(ts.inFlight, cachePath)
+		}
+		ts.inFlightMutex.Unlock()
+	}
+}
+
+// cachePath returns the cache file of a poster frame. The key includes the
+// size and modification time so that changed files get a new thumbnail.
+func (ts *ThumbnailService) cachePath(inputPath string, percent float64) (string, error) {
+	info, err := os.Stat(inputPath)
+	if err != nil {
+		return "", fmt.Errorf("%w: %s", ErrInvalidPath, inputPath)
+	}
+
+	hash := sha1.Sum([]byte(fmt.Sprintf("%s|%d|%d|%.2f|%d", inputPath, info.Size(), info.ModTime().UnixNano(), percent, DefaultThumbnailWidth)))
+	return filepath.Join(ts.cacheDir, hex.EncodeToString(hash[:])+".png"), nil
+}
+
+// ContactSheet builds a grid of evenly spaced frames, each labelled with its timestamp
+func (ts *ThumbnailService) ContactSheet(ctx context.Context, file *medias.FfprobeResult, options ContactSheetOptions) (image.Image, error) {
+	if options.Columns <= 0 || options.Rows <= 0 || options.TileWidth <= 0 {
+		return nil, fmt.Errorf("invalid contact sheet layout: %dx%d, %dpx", options.Columns, options.Rows, options.TileWidth)
+	}
+
+	count := options.Columns * options.Rows
+	duration := file.Format.DurationSeconds
+
+	frames := make([]image.Image, 0, count)
+	timestamps := make([]time.Duration, 0, count)
+	for i := 0; i < count; i++ {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		default:
+		}
+
+		at := duration * time.Duration(i+1) / time.Duration(count+1)
+		frame, err := ts.ffmpegService.ExtractFrame(ctx, file.Format.Filename, at, options.TileWidth)
+		if err != nil {
+			logger.Warnf("Could not extract frame at %s from %s: %v", at, file.Format.Filename, err)
+			continue
+		}
+		frames = append(frames, frame)
+		timestamps = append(timestamps, at)
+	}
+
+	if len(frames) == 0 {
+		return nil, fmt.Errorf("no frame could be extracted from %s", file.Format.Filename)
+	}
+
+	return composeContactSheet(frames, timestamps, options), nil
+}
+
+// ExportContactSheet writes the contact sheet of a file as PNG
+func (ts *ThumbnailService) ExportContactSheet(ctx context.Context, file *medias.FfprobeResult, outputPath string, options ContactSheetOptions) error {
+	sheet, err := ts.ContactSheet(ctx, file, options)
+	if err != nil {
+		return err
+	}
+	return writePNG(outputPath, sheet)
+}
+
+// BatchExportContactSheets writes one contact sheet per file in outputDir. A
+// file that fails doesn't stop the batch; the result tells which ones did.
+func (ts *ThumbnailService) BatchExportContactSheets(ctx context.Context, files []*medias.FfprobeResult, outputDir string, options ContactSheetOptions, progress ProgressCallback) (*BatchResult, error) {
+	if err := os.MkdirAll(outputDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	// Exports only write new images: they aren't journaled for resume
+	journal := &BatchJournal{
+		Kind:      BatchKindContactSheets,
+		Operation: "contact_sheets",
+		StartedAt: time.Now(),
+		Entries:   make([]*JournalEntry, len(files)),
+	}
+	for i, file := range files {
+		journal.Entries[i] = &JournalEntry{InputPath: file.Format.Filename, Status: EntryPending}
+	}
+
+	for i, file := range files {
+		if ctx.Err() != nil {
+			return newBatchResult(journal), ctx.Err()
+		}
+
+		entry := journal.Entries[i]
+		base := filepath.Base(file.Format.Filename)
+		entry.OutputPath = filepath.Join(outputDir, strings.TrimSuffix(base, filepath.Ext(base))+"_sheet.png")
+
+		started := time.Now()
+		err := ts.ExportContactSheet(ctx, file, entry.OutputPath, options)
+		if err != nil && ctx.Err() != nil {
+			// Cancelled: the file stays pending
+			return newBatchResult(journal), ctx.Err()
+		}
+
+		entry.Duration = time.Since(started)
+		if err != nil {
+			logger.Warnf("Failed to export contact sheet for %s: %v", file.Format.Filename, err)
+			entry.Status = EntryFailed
+			entry.Error = err.Error()
+		} else {
+			entry.Status = EntryDone
+		}
+
+		if progress != nil {
+			progress(float64(i+1)/float64(len(files)), fmt.Sprintf("Exported %d/%d contact sheets", i+1, len(files)))
+		}
+	}
+
+	return newBatchResult(journal), nil
+}
+
+// composeContactSheet draws the frames in a grid with their timestamps
+func composeContactSheet(frames []image.Image, timestamps []time.Duration, options ContactSheetOptions) image.Image {
+	const padding = 4
+
+	tileWidth := options.TileWidth
+	tileHeight := frames[0].Bounds().Dy()
+	rows := (len(frames) + options.Columns - 1) / options.Columns
+
+	sheet := image.NewRGBA(image.Rect(0, 0,
+		options.Columns*(tileWidth+padding)+padding,
+		rows*(tileHeight+padding)+padding,
+	))
+	draw.Draw(sheet, sheet.Bounds(), image.NewUniform(color.Black), image.Point{}, draw.Src)
+
+	face := basicfont.Face7x13
+	for i, frame := range frames {
+		x := padding + (i%options.Columns)*(tileWidth+padding)
+		y := padding + (i/options.Columns)*(tileHeight+padding)
+		tile := image.Rect(x, y, x+tileWidth, y+tileHeight)
+		draw.Draw(sheet, tile, frame, frame.Bounds().Min, draw.Src)
+
+		label := formatTimestamp(timestamps[i])
+		labelWidth := font.MeasureString(face, label).Ceil()
+		labelBox := image.Rect(x, tile.Max.Y-face.Height-4, x+labelWidth+6, tile.Max.Y)
+		draw.Draw(sheet, labelBox, image.NewUniform(color.RGBA{0, 0, 0, 160}), image.Point{}, draw.Over)
+
+		drawer := &font.Drawer{
+			Dst:  sheet,
+			Src:  image.White,
+			Face: face,
+			Dot:  fixed.P(x+3, tile.Max.Y-4-face.Descent),
+		}
+		drawer.DrawString(label)
+	}
+
+	return sheet
+}
+
+// ExtractFrame decodes a single frame at the given position, scaled to width
+func (fs *FFmpegService) ExtractFrame(ctx context.Context, inputFile string, at time.Duration, width int) (image.Image, error) {
+	args := []string{
+		"-ss", formatSeconds(at),
+		"-i", inputFile,
+		"-frames:v", "1",
+		"-vf", fmt.Sprintf("scale=%d:-2", width),
+		"-f", "image2pipe",
+		"-c:v", "png",
+		"-",
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, fs.ffmpegPath, args...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("ffmpeg frame extraction failed: %w\nOutput: %s", err, stderr.String())
+	}
+
+	frame, err := png.Decode(&stdout)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode frame: %w", err)
+	}
+	return frame, nil
+}
+
+// writePNG encodes img to a temporary file renamed to path once complete, so
+// that an interrupted write never leaves a truncated image behind
+func writePNG(path string, img image.Image) error {
+	file, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	tempPath := file.Name()
+
+	if err := file.Chmod(0644); err != nil {
+		file.Close()
+		os.Remove(tempPath)
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	if err := png.Encode(file, img); err != nil {
+		file.Close()
+		os.Remove(tempPath)
+		return fmt.Errorf("failed to encode %s: %w", path, err)
+	}
+	if err := file.Close(); err != nil {
+		os.Remove(tempPath)
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	if err := os.Rename(tempPath, path); err != nil {
+		os.Remove(tempPath)
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
+
+// formatTimestamp formats a duration as HH:MM:SS
+func formatTimestamp(d time.Duration) string {
+	d = d.Round(time.Second)
+	return fmt.Sprintf("%02d:%02d:%02d", int(d/time.Hour), int(d/time.Minute)%60, int(d/time.Second)%60)
+}
@@ -0,0 +1,505 @@
+package services
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Developpeur-du-dimanche/MediaTools/pkg/logger"
+	"github.com/Developpeur-du-dimanche/MediaTools/pkg/medias"
+)
+
+// TrimOptions configures TrimVideo
+type TrimOptions struct {
+	Start time.Duration
+	// End is the end timestamp in the input; zero keeps everything after Start
+	End time.Duration
+	// Precise re-encodes the GOP before the first keyframe after Start so the
+	// output starts exactly at Start. Otherwise the cut snaps to the previous
+	// keyframe. Only H.264 and HEVC videos can be cut precisely.
+	Precise bool
+}
+
+// SplitMode selects how SplitVideo computes the cut points
+type SplitMode string
+
+const (
+	// SplitByDuration cuts the file in segments of a fixed length
+	SplitByDuration SplitMode = "duration"
+	// SplitBySize cuts the file in segments of an approximate size
+	SplitBySize SplitMode = "size"
+	// SplitByChapters cuts the file at every chapter boundary
+	SplitByChapters SplitMode = "chapters"
+)
+
+// SplitOptions configures SplitVideo
+type SplitOptions struct {
+	Mode SplitMode
+	// SegmentDuration is used with SplitByDuration
+	SegmentDuration time.Duration
+	// SegmentSize is the target size in bytes, used with SplitBySize
+	SegmentSize int64
+}
+
+// preciseCutCodec is how the boundary GOP of a codec is re-encoded
+type preciseCutCodec struct {
+	encoder string
+	// bitstreamFilter writes the parameter sets in band, so that the
+	// re-encoded head and the copied tail each carry their own
+	bitstreamFilter string
+}
+
+// preciseCutCodecs are the codecs that can be cut precisely, by input codec
+// name. Their parts are joined as Annex-B streams, where a decoder picks up
+// the parameter sets of each part; other codecs can't be safely joined.
+var preciseCutCodecs = map[string]preciseCutCodec{
+	"h264": {encoder: "libx264", bitstreamFilter: "h264_mp4toannexb"},
+	"hevc": {encoder: "libx265", bitstreamFilter: "hevc_mp4toannexb"},
+}
+
+// preciseCutProfiles maps the profiles reported by ffprobe to the profiles of
+// the precise cut encoders, by input codec name. The re-encoded head must use
+// the profile of the copied tail for the joined stream to play everywhere.
+var preciseCutProfiles = map[string]map[string]string{
+	"h264": {
+		"Constrained Baseline":  "baseline",
+		"Baseline":              "baseline",
+		"Main":                  "main",
+		"High":                  "high",
+		"High 10":               "high10",
+		"High 4:2:2":            "high422",
+		"High 4:4:4 Predictive": "high444",
+	},
+	"hevc": {
+		"Main":               "main",
+		"Main 10":            "main10",
+		"Main 12":            "main12",
+		"Main Still Picture": "mainstillpicture",
+	},
+}
+
+// TrimVideo keeps the part of a video between two timestamps using stream copy
+func (fs *FFmpegService) TrimVideo(ctx context.Context, inputFile, outputPath string, options TrimOptions, progress ProgressCallback) error {
+	logger.Infof("Trimming %s from %s to %s", inputFile, options.Start, options.End)
+
+	if options.End != 0 && options.End <= options.Start {
+		return fmt.Errorf("end (%s) must be after start (%s)", options.End, options.Start)
+	}
+
+	probeResult, err := fs.probeFile(ctx, inputFile)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(outputPath), 0755); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	keyframes, err := fs.getKeyframes(ctx, inputFile)
+	if err != nil {
+		return fmt.Errorf("failed to read keyframes: %w", err)
+	}
+
+	if options.Precise {
+		nextKeyframe, found := keyframeAtOrAfter(keyframes, options.Start)
+		if found && nextKeyframe > options.Start && (options.End == 0 || nextKeyframe < options.End) {
+			return fs.preciseTrim(ctx, inputFile, outputPath, options, nextKeyframe, probeResult, progress)
+		}
+	}
+
+	start := keyframeAtOrBefore(keyframes, options.Start)
+	if start != options.Start {
+		logger.Infof("Start snapped from %s to keyframe at %s", options.Start, start)
+	}
+
+	args := fs.buildTrimArgs(inputFile, outputPath, start, options.End)
+	if err := fs.runVerified(ctx, args, outputPath, expectTrim(probeResult, start, options.End)); err != nil {
+		return fmt.Errorf("ffmpeg trim failed: %w", err)
+	}
+
+	if progress != nil {
+		progress(1.0, fmt.Sprintf("Trimmed from %s", start))
+	}
+
+	logger.Infof("Successfully trimmed %s", inputFile)
+	return nil
+}
+
+// expectTrim expects the streams of the input and the duration between start and end
+func expectTrim(probeResult *medias.FfprobeResult, start, end time.Duration) OutputExpectation {
+	expect := expectSameStreams(probeResult)
+	if end == 0 || end > expect.Duration {
+		end = expect.Duration
+	}
+	expect.Duration = end - start
+	return expect
+}
+
+// buildTrimArgs builds FFmpeg arguments for a stream copy between start and end
+func (fs *FFmpegService) buildTrimArgs(inputFile, outputPath string, start, end time.Duration) []string {
+	// Start is a keyframe timestamp: rounding it down to milliseconds would
+	// make the seek snap back to the previous keyframe
+	args := []string{
+		"-ss", formatPreciseSeconds(start),
+		"-i", inputFile,
+	}
+	if end != 0 {
+		args = append(args, "-t", formatPreciseSeconds(end-start))
+	}
+	args = append(args,
+		"-map", "0",
+		"-map_metadata", "0",
+		"-c", "copy",
+		"-avoid_negative_ts", "make_zero",
+		outputPath,
+		"-y",
+	)
+	return args
+}
+
+// preciseTrim re-encodes the head of the cut up to the next keyframe, stream
+// copies the rest and joins both parts. The parts are MPEG-TS files holding the
+// video and audio; subtitles and attachments are copied from the input while joining.
+func (fs *FFmpegService) preciseTrim(ctx context.Context, inputFile, outputPath string, options TrimOptions, keyframe time.Duration, probeResult *medias.FfprobeResult, progress ProgressCallback) error {
+	if len(probeResult.Videos) == 0 {
+		return fmt.Errorf("precise trim needs a video stream")
+	}
+
+	codec, ok := preciseCutCodecs[strings.ToLower(probeResult.Videos[0].CodecName)]
+	if !ok {
+		return fmt.Errorf("precise trim is not supported for codec %s", probeResult.Videos[0].CodecName)
+	}
+
+	headFile, err := os.CreateTemp("", "ffmpeg_trim_head_*.ts")
+	if err != nil {
+		return err
+	}
+	headFile.Close()
+	defer os.Remove(headFile.Name())
+
+	tailFile, err := os.CreateTemp("", "ffmpeg_trim_tail_*.ts")
+	if err != nil {
+		return err
+	}
+	tailFile.Close()
+	defer os.Remove(tailFile.Name())
+
+	headArgs := []string{
+		"-ss", formatSeconds(options.Start),
+		"-i", inputFile,
+		"-t", formatPreciseSeconds(keyframe - options.Start),
+		"-map", "0:v:0",
+		"-map", "0:a?",
+		"-c", "copy",
+		"-c:v", codec.encoder,
+	}
+	headArgs = append(headArgs, matchVideoArgs(probeResult.Videos[0])...)
+	headArgs = append(headArgs, "-f", "mpegts", headFile.Name(), "-y")
+	if err := fs.runFFmpeg(ctx, headArgs); err != nil {
+		return fmt.Errorf("ffmpeg head re-encode failed: %w", err)
+	}
+
+	if progress != nil {
+		progress(0.5, "Boundary GOP re-encoded")
+	}
+
+	tailArgs := []string{
+		"-ss", formatPreciseSeconds(keyframe),
+		"-i", inputFile,
+	}
+	if options.End != 0 {
+		tailArgs = append(tailArgs, "-t", formatPreciseSeconds(options.End-keyframe))
+	}
+	tailArgs = append(tailArgs,
+		"-map", "0:v:0",
+		"-map", "0:a?",
+		"-c", "copy",
+		"-bsf:v", codec.bitstreamFilter,
+		"-avoid_negative_ts", "make_zero",
+		"-f", "mpegts",
+		tailFile.Name(),
+		"-y",
+	)
+	if err := fs.runFFmpeg(ctx, tailArgs); err != nil {
+		return fmt.Errorf("ffmpeg tail copy failed: %w", err)
+	}
+
+	listFile, err := fs.createConcatList([]string{headFile.Name(), tailFile.Name()})
+	if err != nil {
+		return fmt.Errorf("failed to create concat list: %w", err)
+	}
+	defer os.Remove(listFile)
+
+	joinArgs := []string{
+		"-f", "concat",
+		"-safe", "0",
+		"-i", listFile,
+		"-ss", formatSeconds(options.Start),
+	}
+	if options.End != 0 {
+		joinArgs = append(joinArgs, "-t", formatPreciseSeconds(options.End-options.Start))
+	}
+	joinArgs = append(joinArgs,
+		"-i", inputFile,
+		"-map", "0",
+		"-map", "1:s?",
+		"-map", "1:t?",
+		"-map_metadata", "1",
+		"-c", "copy",
+		outputPath,
+		"-y",
+	)
+
+	// The join is decoded around the cut, where the parameter sets change
+	expect := expectTrim(probeResult, options.Start, options.End)
+	expect.DecodeAround = keyframe - options.Start
+	if err := fs.runVerified(ctx, joinArgs, outputPath, expect); err != nil {
+		return fmt.Errorf("ffmpeg join failed: %w", err)
+	}
+
+	if progress != nil {
+		progress(1.0, fmt.Sprintf("Trimmed precisely from %s", options.Start))
+	}
+
+	logger.Infof("Successfully trimmed %s (precise)", inputFile)
+	return nil
+}
+
+// SplitVideo cuts a video into several parts using stream copy and returns the
+// created files. Cuts always happen on keyframes. The parts are moved to
+// outputDir once all of them are verified.
+func (fs *FFmpegService) SplitVideo(ctx context.Context, inputFile, outputDir string, options SplitOptions, progress ProgressCallback) ([]string, error) {
+	logger.Infof("Splitting %s (mode: %s)", inputFile, options.Mode)
+
+	probeResult, err := fs.probeFile(ctx, inputFile)
+	if err != nil {
+		return nil, err
+	}
+
+	var segmentArgs []string
+	switch options.Mode {
+	case SplitByDuration:
+		if options.SegmentDuration <= 0 {
+			return nil, fmt.Errorf("segment duration must be positive")
+		}
+		segmentArgs = []string{"-segment_time", formatSeconds(options.SegmentDuration)}
+	case SplitBySize:
+		if options.SegmentSize <= 0 {
+			return nil, fmt.Errorf("segment size must be positive")
+		}
+		bitrate, err := strconv.ParseInt(probeResult.Format.Bitrate, 10, 64)
+		if err != nil || bitrate <= 0 {
+			return nil, fmt.Errorf("unknown bitrate, can't split by size")
+		}
+		segmentDuration := time.Duration(float64(options.SegmentSize*8) / float64(bitrate) * float64(time.Second))
+		segmentArgs = []string{"-segment_time", formatSeconds(segmentDuration)}
+	case SplitByChapters:
+		if len(probeResult.Chapters) < 2 {
+			return nil, fmt.Errorf("file has no chapters to split at")
+		}
+		times := make([]string, 0, len(probeResult.Chapters)-1)
+		for _, chapter := range probeResult.Chapters[1:] {
+			times = append(times, formatSeconds(chapter.StartTime))
+		}
+		segmentArgs = []string{"-segment_times", strings.Join(times, ",")}
+	default:
+		return nil, fmt.Errorf("unsupported split mode: %s", options.Mode)
+	}
+
+	if err := os.MkdirAll(outputDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	segmentList, err := os.CreateTemp("", "ffmpeg_segments_*.txt")
+	if err != nil {
+		return nil, err
+	}
+	segmentList.Close()
+	defer os.Remove(segmentList.Name())
+
+	// The parts are written to a staging folder next to their final place, so
+	// the parts of an earlier split are only replaced once the new ones are verified
+	stagingDir, err := os.MkdirTemp(outputDir, ".split_*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create staging directory: %w", err)
+	}
+	defer os.RemoveAll(stagingDir)
+
+	base := filepath.Base(inputFile)
+	ext := filepath.Ext(base)
+	pattern := filepath.Join(stagingDir, fmt.Sprintf("%s_part%%03d%s", strings.TrimSuffix(base, ext), ext))
+
+	args := []string{
+		"-i", inputFile,
+		"-map", "0",
+		"-c", "copy",
+		"-f", "segment",
+	}
+	args = append(args, segmentArgs...)
+	args = append(args,
+		"-reset_timestamps", "1",
+		"-segment_list", segmentList.Name(),
+		"-segment_list_type", "flat",
+		pattern,
+		"-y",
+	)
+
+	if err := fs.runFFmpeg(ctx, args); err != nil {
+		return nil, fmt.Errorf("ffmpeg split failed: %w", err)
+	}
+
+	staged, err := readSegmentList(segmentList.Name(), stagingDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read segment list: %w", err)
+	}
+
+	if err := fs.verifySegments(ctx, staged, probeResult.Format.DurationSeconds); err != nil {
+		if verificationErr, ok := err.(*VerificationError); ok {
+			verificationErr.OutputPath = filepath.Join(outputDir, filepath.Base(verificationErr.OutputPath))
+		}
+		return nil, err
+	}
+
+	outputs := make([]string, 0, len(staged))
+	for _, part := range staged {
+		output := filepath.Join(outputDir, filepath.Base(part))
+		if err := os.Rename(part, output); err != nil {
+			return outputs, fmt.Errorf("failed to move verified part: %w", err)
+		}
+		outputs = append(outputs, output)
+	}
+
+	if progress != nil {
+		progress(1.0, fmt.Sprintf("Split into %d parts", len(outputs)))
+	}
+
+	logger.Infof("Successfully split %s into %d parts", inputFile, len(outputs))
+	return outputs, nil
+}
+
+// readSegmentList reads the flat segment list written by the segment muxer
+func readSegmentList(listPath, outputDir string) ([]string, error) {
+	file, err := os.Open(listPath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	outputs := make([]string, 0)
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		if !filepath.IsAbs(line) {
+			line = filepath.Join(outputDir, filepath.Base(line))
+		}
+		outputs = append(outputs, line)
+	}
+	return outputs, scanner.Err()
+}
+
+// getKeyframes returns the sorted timestamps of the keyframes of the first video stream
+func (fs *FFmpegService) getKeyframes(ctx context.Context, inputFile string) ([]time.Duration, error) {
+	args := []string{
+		"-v", "error",
+		"-select_streams", "v:0",
+		"-show_entries", "packet=pts_time,flags",
+		"-of", "csv=print_section=0",
+		inputFile,
+	}
+
+	cmd := exec.CommandContext(ctx, "ffprobe", args...)
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, err
+	}
+
+	keyframes := make([]time.Duration, 0)
+	for _, line := range strings.Split(string(output), "\n") {
+		fields := strings.Split(strings.TrimSpace(line), ",")
+		if len(fields) < 2 || !strings.Contains(fields[1], "K") {
+			continue
+		}
+		seconds, err := strconv.ParseFloat(fields[0], 64)
+		if err != nil {
+			continue
+		}
+		keyframes = append(keyframes, time.Duration(seconds*float64(time.Second)))
+	}
+
+	sort.Slice(keyframes, func(i, j int) bool { return keyframes[i] < keyframes[j] })
+	return keyframes, nil
+}
+
+// keyframeAtOrBefore returns the last keyframe at or before t
+func keyframeAtOrBefore(keyframes []time.Duration, t time.Duration) time.Duration {
+	result := time.Duration(0)
+	for _, keyframe := range keyframes {
+		if keyframe > t {
+			break
+		}
+		result = keyframe
+	}
+	return result
+}
+
+// keyframeAtOrAfter returns the first keyframe at or after t
+func keyframeAtOrAfter(keyframes []time.Duration, t time.Duration) (time.Duration, bool) {
+	for _, keyframe := range keyframes {
+		if keyframe >= t {
+			return keyframe, true
+		}
+	}
+	return 0, false
+}
+
+// formatSeconds formats a duration as seconds for FFmpeg time options
+func formatSeconds(d time.Duration) string {
+	return strconv.FormatFloat(d.Seconds(), 'f', 3, 64)
+}
+
+// formatPreciseSeconds formats a duration as seconds with the microsecond
+// precision of the timestamps reported by ffprobe
+func formatPreciseSeconds(d time.Duration) string {
+	return strconv.FormatFloat(d.Seconds(), 'f', 6, 64)
+}
+
+// matchVideoArgs returns the encoder options giving a re-encoded part the
+// profile, level and pixel format of the source video
+func matchVideoArgs(video medias.Video) []string {
+	codec := strings.ToLower(video.CodecName)
+	args := make([]string, 0)
+	if video.PixFmt != "" {
+		args = append(args, "-pix_fmt", video.PixFmt)
+	}
+
+	profile := preciseCutProfiles[codec][video.Profile]
+	switch codec {
+	case "h264":
+		if profile != "" {
+			args = append(args, "-profile:v", profile)
+		}
+		if video.Level > 0 {
+			// ffprobe reports H.264 levels times 10, e.g. 41 for 4.1
+			args = append(args, "-level", strconv.FormatFloat(float64(video.Level)/10, 'f', -1, 64))
+		}
+	case "hevc":
+		if profile != "" {
+			args = append(args, "-profile:v", profile)
+		}
+		if video.Level > 0 {
+			// ffprobe reports HEVC levels times 30, e.g. 123 for 4.1
+			args = append(args, "-x265-params", "level-idc="+strconv.FormatFloat(float64(video.Level)/30, 'f', 1, 64))
+		}
+	}
+	return args
+}
@@ -0,0 +1,242 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/Developpeur-du-dimanche/MediaTools/pkg/logger"
+	"github.com/Developpeur-du-dimanche/MediaTools/pkg/medias"
+)
+
+const (
+	// durationTolerance is the allowed gap between the expected and the output duration
+	durationTolerance = time.Second
+	// durationToleranceRatio widens the tolerance for long files, where
+	// stream copies may shift the end by a few frames per segment
+	durationToleranceRatio = 0.01
+	// cutDecodeMargin is how much video is decoded on each side of a cut
+	cutDecodeMargin = 2 * time.Second
+)
+
+// streamTypes are the stream types checked by output verification
+var streamTypes = []string{"video", "audio", "subtitle"}
+
+// OutputExpectation is what an output file must look like once ffmpeg is done
+type OutputExpectation struct {
+	// Duration is the expected duration; zero skips the check
+	Duration time.Duration `json:"duration"`
+	// Streams is the expected number of streams by type ("video", "audio",
+	// "subtitle"). Zero means the type must be absent; types not listed aren't checked.
+	Streams map[string]int `json:"streams,omitempty"`
+	// DecodeAround is a position of the output, such as the join of a
+	// re-encoded and a copied part, where the video must decode without
+	// errors; zero skips the check
+	DecodeAround time.Duration `json:"decode_around,omitempty"`
+}
+
+// VerificationError lists the problems found in an output
+type VerificationError struct {
+	OutputPath string
+	Problems   []string
+}
+
+func (e *VerificationError) Error() string {
+	return fmt.Sprintf("verification of %s failed: %s", filepath.Base(e.OutputPath), strings.Join(e.Problems, "; "))
+}
+
+// expectSameStreams expects an output with the duration and the streams of a probed file
+func expectSameStreams(probe *medias.FfprobeResult) OutputExpectation {
+	return OutputExpectation{
+		Duration: probe.Format.DurationSeconds,
+		Streams: map[string]int{
+			"video":    len(probe.Videos),
+			"audio":    len(probe.Audios),
+			"subtitle": len(probe.Subtitles),
+		},
+	}
+}
+
+// expectPlannedStreams expects an output with the given duration and exactly the planned streams
+func expectPlannedStreams(duration time.Duration, streams []PlannedStream) OutputExpectation {
+	expect := OutputExpectation{
+		Duration: duration,
+		Streams:  make(map[string]int, len(streamTypes)),
+	}
+	for _, streamType := range streamTypes {
+		expect.Streams[streamType] = 0
+	}
+	for _, stream := range streams {
+		expect.Streams[stream.Type]++
+	}
+	return expect
+}
+
+// runVerified runs ffmpeg writing to a partial file next to outputPath,
+// verifies it and only then moves it to outputPath. Partial outputs are
+// deleted on failure, so an existing file at outputPath, even the input
+// itself, is only ever replaced by a verified output.
+func (fs *FFmpegService) runVerified(ctx context.Context, args []string, outputPath string, expect OutputExpectation) error {
+	partialPath := partialOutputPath(outputPath)
+	args = replaceOutputArg(args, outputPath, partialPath)
+
+	if err := fs.runFFmpeg(ctx, args); err != nil {
+		os.Remove(partialPath)
+		return err
+	}
+
+	if _, err := fs.verifyOutput(ctx, partialPath, expect); err != nil {
+		os.Remove(partialPath)
+		if verificationErr, ok := err.(*VerificationError); ok {
+			verificationErr.OutputPath = outputPath
+		}
+		return err
+	}
+
+	if err := os.Rename(partialPath, outputPath); err != nil {
+		os.Remove(partialPath)
+		return fmt.Errorf("failed to move verified output: %w", err)
+	}
+	return nil
+}
+
+// verifyOutput probes an output and checks its size, duration and streams
+func (fs *FFmpegService) verifyOutput(ctx context.Context, outputPath string, expect OutputExpectation) (*medias.FfprobeResult, error) {
+	verificationErr := &VerificationError{OutputPath: outputPath}
+
+	info, err := os.Stat(outputPath)
+	if err != nil {
+		verificationErr.Problems = append(verificationErr.Problems, "output is missing")
+		return nil, verificationErr
+	}
+	if info.Size() == 0 {
+		verificationErr.Problems = append(verificationErr.Problems, "output is empty")
+		return nil, verificationErr
+	}
+
+	probe, err := fs.probeFile(ctx, outputPath)
+	if err != nil {
+		verificationErr.Problems = append(verificationErr.Problems, fmt.Sprintf("output can't be probed: %v", err))
+		return nil, verificationErr
+	}
+
+	if expect.Duration > 0 {
+		tolerance := time.Duration(float64(expect.Duration) * durationToleranceRatio)
+		if tolerance < durationTolerance {
+			tolerance = durationTolerance
+		}
+		gap := probe.Format.DurationSeconds - expect.Duration
+		if gap < -tolerance || gap > tolerance {
+			verificationErr.Problems = append(verificationErr.Problems, fmt.Sprintf("duration is %s, expected %s", probe.Format.DurationSeconds.Round(time.Millisecond), expect.Duration.Round(time.Millisecond)))
+		}
+	}
+
+	found := map[string]int{
+		"video":    len(probe.Videos),
+		"audio":    len(probe.Audios),
+		"subtitle": len(probe.Subtitles),
+	}
+	for _, streamType := range streamTypes {
+		expected, checked := expect.Streams[streamType]
+		if !checked || found[streamType] == expected {
+			continue
+		}
+		if expected == 0 {
+			verificationErr.Problems = append(verificationErr.Problems, fmt.Sprintf("%d %s streams should have been removed", found[streamType], streamType))
+		} else {
+			verificationErr.Problems = append(verificationErr.Problems, fmt.Sprintf("%d %s streams, expected %d", found[streamType], streamType, expected))
+		}
+	}
+
+	if expect.DecodeAround > 0 && len(probe.Videos) > 0 {
+		if problem := fs.decodeAround(ctx, outputPath, expect.DecodeAround); problem != "" {
+			verificationErr.Problems = append(verificationErr.Problems, problem)
+		}
+	}
+
+	if len(verificationErr.Problems) > 0 {
+		logger.Warnf("Output %s failed verification: %v", outputPath, verificationErr.Problems)
+		return nil, verificationErr
+	}
+	return probe, nil
+}
+
+// decodeAround decodes the video on both sides of a position and returns the
+// problem found, or an empty string when it decodes cleanly
+func (fs *FFmpegService) decodeAround(ctx context.Context, outputPath string, at time.Duration) string {
+	start := max(0, at-cutDecodeMargin)
+	options := CheckOptions{Streams: CheckStreamsVideo}
+	cmd := exec.CommandContext(ctx, fs.ffmpegPath, options.decodeArgs(outputPath, start, at+cutDecodeMargin-start)...)
+	output, err := cmd.CombinedOutput()
+
+	messages := strings.TrimSpace(string(output))
+	if err != nil && messages == "" {
+		messages = err.Error()
+	}
+	if messages == "" {
+		return ""
+	}
+	first, _, _ := strings.Cut(messages, "\n")
+	return fmt.Sprintf("video doesn't decode around %s: %s", formatTimestamp(at), first)
+}
+
+// verifySegments checks the parts written by SplitVideo: each one must be a
+// readable, non-empty file and together they must last as long as the input.
+// All the parts are deleted when one of them fails.
+func (fs *FFmpegService) verifySegments(ctx context.Context, segments []string, duration time.Duration) error {
+	var total time.Duration
+	var failure error
+	for _, segment := range segments {
+		probe, err := fs.verifyOutput(ctx, segment, OutputExpectation{})
+		if err != nil {
+			failure = err
+			break
+		}
+		total += probe.Format.DurationSeconds
+	}
+
+	if failure == nil && len(segments) == 0 {
+		failure = fmt.Errorf("no parts were written")
+	}
+	if failure == nil && duration > 0 {
+		// Each part may be a few frames off, allow the tolerance once per part
+		tolerance := time.Duration(len(segments)) * durationTolerance
+		if gap := total - duration; gap < -tolerance || gap > tolerance {
+			failure = &VerificationError{
+				OutputPath: segments[0],
+				Problems:   []string{fmt.Sprintf("parts last %s, expected %s", total.Round(time.Millisecond), duration.Round(time.Millisecond))},
+			}
+		}
+	}
+
+	if failure != nil {
+		for _, segment := range segments {
+			os.Remove(segment)
+		}
+	}
+	return failure
+}
+
+// partialOutputPath returns the path ffmpeg writes to before verification.
+// The extension is kept so ffmpeg picks the same container.
+func partialOutputPath(outputPath string) string {
+	ext := filepath.Ext(outputPath)
+	return strings.TrimSuffix(outputPath, ext) + ".partial" + ext
+}
+
+// replaceOutputArg returns a copy of args where the last occurrence of the output path is replaced
+func replaceOutputArg(args []string, outputPath, replacement string) []string {
+	result := make([]string, len(args))
+	copy(result, args)
+	for i := len(result) - 1; i >= 0; i-- {
+		if result[i] == outputPath {
+			result[i] = replacement
+			break
+		}
+	}
+	return result
+}
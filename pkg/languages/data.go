@@ -0,0 +1,192 @@
+package languages
+
+// languages lists the ISO 639-1 languages and the ISO 639-2 codes found in media tags
+var languages = []Language{
+	{Alpha2: "aa", Bibliographic: "aar", Terminology: "aar", Name: "Afar"},
+	{Alpha2: "ab", Bibliographic: "abk", Terminology: "abk", Name: "Abkhazian"},
+	{Alpha2: "ae", Bibliographic: "ave", Terminology: "ave", Name: "Avestan"},
+	{Alpha2: "af", Bibliographic: "afr", Terminology: "afr", Name: "Afrikaans"},
+	{Alpha2: "ak", Bibliographic: "aka", Terminology: "aka", Name: "Akan"},
+	{Alpha2: "am", Bibliographic: "amh", Terminology: "amh", Name: "Amharic"},
+	{Alpha2: "an", Bibliographic: "arg", Terminology: "arg", Name: "Aragonese"},
+	{Alpha2: "ar", Bibliographic: "ara", Terminology: "ara", Name: "Arabic"},
+	{Alpha2: "as", Bibliographic: "asm", Terminology: "asm", Name: "Assamese"},
+	{Alpha2: "av", Bibliographic: "ava", Terminology: "ava", Name: "Avaric"},
+	{Alpha2: "ay", Bibliographic: "aym", Terminology: "aym", Name: "Aymara"},
+	{Alpha2: "az", Bibliographic: "aze", Terminology: "aze", Name: "Azerbaijani"},
+	{Alpha2: "ba", Bibliographic: "bak", Terminology: "bak", Name: "Bashkir"},
+	{Alpha2: "be", Bibliographic: "bel", Terminology: "bel", Name: "Belarusian"},
+	{Alpha2: "bg", Bibliographic: "bul", Terminology: "bul", Name: "Bulgarian"},
+	{Alpha2: "bi", Bibliographic: "bis", Terminology: "bis", Name: "Bislama"},
+	{Alpha2: "bm", Bibliographic: "bam", Terminology: "bam", Name: "Bambara"},
+	{Alpha2: "bn", Bibliographic: "ben", Terminology: "ben", Name: "Bengali"},
+	{Alpha2: "bo", Bibliographic: "tib", Terminology: "bod", Name: "Tibetan"},
+	{Alpha2: "br", Bibliographic: "bre", Terminology: "bre", Name: "Breton"},
+	{Alpha2: "bs", Bibliographic: "bos", Terminology: "bos", Name: "Bosnian"},
+	{Alpha2: "ca", Bibliographic: "cat", Terminology: "cat", Name: "Catalan"},
+	{Alpha2: "ce", Bibliographic: "che", Terminology: "che", Name: "Chechen"},
+	{Alpha2: "ch", Bibliographic: "cha", Terminology: "cha", Name: "Chamorro"},
+	{Alpha2: "co", Bibliographic: "cos", Terminology: "cos", Name: "Corsican"},
+	{Alpha2: "cr", Bibliographic: "cre", Terminology: "cre", Name: "Cree"},
+	{Alpha2: "cs", Bibliographic: "cze", Terminology: "ces", Name: "Czech"},
+	{Alpha2: "cu", Bibliographic: "chu", Terminology: "chu", Name: "Church Slavic"},
+	{Alpha2: "cv", Bibliographic: "chv", Terminology: "chv", Name: "Chuvash"},
+	{Alpha2: "cy", Bibliographic: "wel", Terminology: "cym", Name: "Welsh"},
+	{Alpha2: "da", Bibliographic: "dan", Terminology: "dan", Name: "Danish"},
+	{Alpha2: "de", Bibliographic: "ger", Terminology: "deu", Name: "German"},
+	{Alpha2: "dv", Bibliographic: "div", Terminology: "div", Name: "Divehi"},
+	{Alpha2: "dz", Bibliographic: "dzo", Terminology: "dzo", Name: "Dzongkha"},
+	{Alpha2: "ee", Bibliographic: "ewe", Terminology: "ewe", Name: "Ewe"},
+	{Alpha2: "el", Bibliographic: "gre", Terminology: "ell", Name: "Greek"},
+	{Alpha2: "en", Bibliographic: "eng", Terminology: "eng", Name: "English"},
+	{Alpha2: "eo", Bibliographic: "epo", Terminology: "epo", Name: "Esperanto"},
+	{Alpha2: "es", Bibliographic: "spa", Terminology: "spa", Name: "Spanish"},
+	{Alpha2: "et", Bibliographic: "est", Terminology: "est", Name: "Estonian"},
+	{Alpha2: "eu", Bibliographic: "baq", Terminology: "eus", Name: "Basque"},
+	{Alpha2: "fa", Bibliographic: "per", Terminology: "fas", Name: "Persian"},
+	{Alpha2: "ff", Bibliographic: "ful", Terminology: "ful", Name: "Fulah"},
+	{Alpha2: "fi", Bibliographic: "fin", Terminology: "fin", Name: "Finnish"},
+	{Alpha2: "fj", Bibliographic: "fij", Terminology: "fij", Name: "Fijian"},
+	{Alpha2: "fo", Bibliographic: "fao", Terminology: "fao", Name: "Faroese"},
+	{Alpha2: "fr", Bibliographic: "fre", Terminology: "fra", Name: "French"},
+	{Alpha2: "fy", Bibliographic: "fry", Terminology: "fry", Name: "Western Frisian"},
+	{Alpha2: "ga", Bibliographic: "gle", Terminology: "gle", Name: "Irish"},
+	{Alpha2: "gd", Bibliographic: "gla", Terminology: "gla", Name: "Scottish Gaelic"},
+	{Alpha2: "gl", Bibliographic: "glg", Terminology: "glg", Name: "Galician"},
+	{Alpha2: "gn", Bibliographic: "grn", Terminology: "grn", Name: "Guarani"},
+	{Alpha2: "gu", Bibliographic: "guj", Terminology: "guj", Name: "Gujarati"},
+	{Alpha2: "gv", Bibliographic: "glv", Terminology: "glv", Name: "Manx"},
+	{Alpha2: "ha", Bibliographic: "hau", Terminology: "hau", Name: "Hausa"},
+	{Alpha2: "he", Bibliographic: "heb", Terminology: "heb", Name: "Hebrew"},
+	{Alpha2: "hi", Bibliographic: "hin", Terminology: "hin", Name: "Hindi"},
+	{Alpha2: "ho", Bibliographic: "hmo", Terminology: "hmo", Name: "Hiri Motu"},
+	{Alpha2: "hr", Bibliographic: "hrv", Terminology: "hrv", Name: "Croatian"},
+	{Alpha2: "ht", Bibliographic: "hat", Terminology: "hat", Name: "Haitian"},
+	{Alpha2: "hu", Bibliographic: "hun", Terminology: "hun", Name: "Hungarian"},
+	{Alpha2: "hy", Bibliographic: "arm", Terminology: "hye", Name: "Armenian"},
+	{Alpha2: "hz", Bibliographic: "her", Terminology: "her", Name: "Herero"},
+	{Alpha2: "ia", Bibliographic: "ina", Terminology: "ina", Name: "Interlingua"},
+	{Alpha2: "id", Bibliographic: "ind", Terminology: "ind", Name: "Indonesian"},
+	{Alpha2: "ie", Bibliographic: "ile", Terminology: "ile", Name: "Interlingue"},
+	{Alpha2: "ig", Bibliographic: "ibo", Terminology: "ibo", Name: "Igbo"},
+	{Alpha2: "ii", Bibliographic: "iii", Terminology: "iii", Name: "Sichuan Yi"},
+	{Alpha2: "ik", Bibliographic: "ipk", Terminology: "ipk", Name: "Inupiaq"},
+	{Alpha2: "io", Bibliographic: "ido", Terminology: "ido", Name: "Ido"},
+	{Alpha2: "is", Bibliographic: "ice", Terminology: "isl", Name: "Icelandic"},
+	{Alpha2: "it", Bibliographic: "ita", Terminology: "ita", Name: "Italian"},
+	{Alpha2: "iu", Bibliographic: "iku", Terminology: "iku", Name: "Inuktitut"},
+	{Alpha2: "ja", Bibliographic: "jpn", Terminology: "jpn", Name: "Japanese"},
+	{Alpha2: "jv", Bibliographic: "jav", Terminology: "jav", Name: "Javanese"},
+	{Alpha2: "ka", Bibliographic: "geo", Terminology: "kat", Name: "Georgian"},
+	{Alpha2: "kg", Bibliographic: "kon", Terminology: "kon", Name: "Kongo"},
+	{Alpha2: "ki", Bibliographic: "kik", Terminology: "kik", Name: "Kikuyu"},
+	{Alpha2: "kj", Bibliographic: "kua", Terminology: "kua", Name: "Kuanyama"},
+	{Alpha2: "kk", Bibliographic: "kaz", Terminology: "kaz", Name: "Kazakh"},
+	{Alpha2: "kl", Bibliographic: "kal", Terminology: "kal", Name: "Kalaallisut"},
+	{Alpha2: "km", Bibliographic: "khm", Terminology: "khm", Name: "Khmer"},
+	{Alpha2: "kn", Bibliographic: "kan", Terminology: "kan", Name: "Kannada"},
+	{Alpha2: "ko", Bibliographic: "kor", Terminology: "kor", Name: "Korean"},
+	{Alpha2: "kr", Bibliographic: "kau", Terminology: "kau", Name: "Kanuri"},
+	{Alpha2: "ks", Bibliographic: "kas", Terminology: "kas", Name: "Kashmiri"},
+	{Alpha2: "ku", Bibliographic: "kur", Terminology: "kur", Name: "Kurdish"},
+	{Alpha2: "kv", Bibliographic: "kom", Terminology: "kom", Name: "Komi"},
+	{Alpha2: "kw", Bibliographic: "cor", Terminology: "cor", Name: "Cornish"},
+	{Alpha2: "ky", Bibliographic: "kir", Terminology: "kir", Name: "Kirghiz"},
+	{Alpha2: "la", Bibliographic: "lat", Terminology: "lat", Name: "Latin"},
+	{Alpha2: "lb", Bibliographic: "ltz", Terminology: "ltz", Name: "Luxembourgish"},
+	{Alpha2: "lg", Bibliographic: "lug", Terminology: "lug", Name: "Ganda"},
+	{Alpha2: "li", Bibliographic: "lim", Terminology: "lim", Name: "Limburgish"},
+	{Alpha2: "ln", Bibliographic: "lin", Terminology: "lin", Name: "Lingala"},
+	{Alpha2: "lo", Bibliographic: "lao", Terminology: "lao", Name: "Lao"},
+	{Alpha2: "lt", Bibliographic: "lit", Terminology: "lit", Name: "Lithuanian"},
+	{Alpha2: "lu", Bibliographic: "lub", Terminology: "lub", Name: "Luba-Katanga"},
+	{Alpha2: "lv", Bibliographic: "lav", Terminology: "lav", Name: "Latvian"},
+	{Alpha2: "mg", Bibliographic: "mlg", Terminology: "mlg", Name: "Malagasy"},
+	{Alpha2: "mh", Bibliographic: "mah", Terminology: "mah", Name: "Marshallese"},
+	{Alpha2: "mi", Bibliographic: "mao", Terminology: "mri", Name: "Maori"},
+	{Alpha2: "mk", Bibliographic: "mac", Terminology: "mkd", Name: "Macedonian"},
+	{Alpha2: "ml", Bibliographic: "mal", Terminology: "mal", Name: "Malayalam"},
+	{Alpha2: "mn", Bibliographic: "mon", Terminology: "mon", Name: "Mongolian"},
+	{Alpha2: "mr", Bibliographic: "mar", Terminology: "mar", Name: "Marathi"},
+	{Alpha2: "ms", Bibliographic: "may", Terminology: "msa", Name: "Malay"},
+	{Alpha2: "mt", Bibliographic: "mlt", Terminology: "mlt", Name: "Maltese"},
+	{Alpha2: "my", Bibliographic: "bur", Terminology: "mya", Name: "Burmese"},
+	{Alpha2: "na", Bibliographic: "nau", Terminology: "nau", Name: "Nauru"},
+	{Alpha2: "nb", Bibliographic: "nob", Terminology: "nob", Name: "Norwegian Bokmål"},
+	{Alpha2: "nd", Bibliographic: "nde", Terminology: "nde", Name: "North Ndebele"},
+	{Alpha2: "ne", Bibliographic: "nep", Terminology: "nep", Name: "Nepali"},
+	{Alpha2: "ng", Bibliographic: "ndo", Terminology: "ndo", Name: "Ndonga"},
+	{Alpha2: "nl", Bibliographic: "dut", Terminology: "nld", Name: "Dutch"},
+	{Alpha2: "nn", Bibliographic: "nno", Terminology: "nno", Name: "Norwegian Nynorsk"},
+	{Alpha2: "no", Bibliographic: "nor", Terminology: "nor", Name: "Norwegian"},
+	{Alpha2: "nr", Bibliographic: "nbl", Terminology: "nbl", Name: "South Ndebele"},
+	{Alpha2: "nv", Bibliographic: "nav", Terminology: "nav", Name: "Navajo"},
+	{Alpha2: "ny", Bibliographic: "nya", Terminology: "nya", Name: "Chichewa"},
+	{Alpha2: "oc", Bibliographic: "oci", Terminology: "oci", Name: "Occitan"},
+	{Alpha2: "oj", Bibliographic: "oji", Terminology: "oji", Name: "Ojibwa"},
+	{Alpha2: "om", Bibliographic: "orm", Terminology: "orm", Name: "Oromo"},
+	{Alpha2: "or", Bibliographic: "ori", Terminology: "ori", Name: "Oriya"},
+	{Alpha2: "os", Bibliographic: "oss", Terminology: "oss", Name: "Ossetian"},
+	{Alpha2: "pa", Bibliographic: "pan", Terminology: "pan", Name: "Punjabi"},
+	{Alpha2: "pi", Bibliographic: "pli", Terminology: "pli", Name: "Pali"},
+	{Alpha2: "pl", Bibliographic: "pol", Terminology: "pol", Name: "Polish"},
+	{Alpha2: "ps", Bibliographic: "pus", Terminology: "pus", Name: "Pashto"},
+	{Alpha2: "pt", Bibliographic: "por", Terminology: "por", Name: "Portuguese"},
+	{Alpha2: "qu", Bibliographic: "que", Terminology: "que", Name: "Quechua"},
+	{Alpha2: "rm", Bibliographic: "roh", Terminology: "roh", Name: "Romansh"},
+	{Alpha2: "rn", Bibliographic: "run", Terminology: "run", Name: "Rundi"},
+	{Alpha2: "ro", Bibliographic: "rum", Terminology: "ron", Name: "Romanian"},
+	{Alpha2: "ru", Bibliographic: "rus", Terminology: "rus", Name: "Russian"},
+	{Alpha2: "rw", Bibliographic: "kin", Terminology: "kin", Name: "Kinyarwanda"},
+	{Alpha2: "sa", Bibliographic: "san", Terminology: "san", Name: "Sanskrit"},
+	{Alpha2: "sc", Bibliographic: "srd", Terminology: "srd", Name: "Sardinian"},
+	{Alpha2: "sd", Bibliographic: "snd", Terminology: "snd", Name: "Sindhi"},
+	{Alpha2: "se", Bibliographic: "sme", Terminology: "sme", Name: "Northern Sami"},
+	{Alpha2: "sg", Bibliographic: "sag", Terminology: "sag", Name: "Sango"},
+	{Alpha2: "si", Bibliographic: "sin", Terminology: "sin", Name: "Sinhala"},
+	{Alpha2: "sk", Bibliographic: "slo", Terminology: "slk", Name: "Slovak"},
+	{Alpha2: "sl", Bibliographic: "slv", Terminology: "slv", Name: "Slovenian"},
+	{Alpha2: "sm", Bibliographic: "smo", Terminology: "smo", Name: "Samoan"},
+	{Alpha2: "sn", Bibliographic: "sna", Terminology: "sna", Name: "Shona"},
+	{Alpha2: "so", Bibliographic: "som", Terminology: "som", Name: "Somali"},
+	{Alpha2: "sq", Bibliographic: "alb", Terminology: "sqi", Name: "Albanian"},
+	{Alpha2: "sr", Bibliographic: "srp", Terminology: "srp", Name: "Serbian"},
+	{Alpha2: "ss", Bibliographic: "ssw", Terminology: "ssw", Name: "Swati"},
+	{Alpha2: "st", Bibliographic: "sot", Terminology: "sot", Name: "Southern Sotho"},
+	{Alpha2: "su", Bibliographic: "sun", Terminology: "sun", Name: "Sundanese"},
+	{Alpha2: "sv", Bibliographic: "swe", Terminology: "swe", Name: "Swedish"},
+	{Alpha2: "sw", Bibliographic: "swa", Terminology: "swa", Name: "Swahili"},
+	{Alpha2: "ta", Bibliographic: "tam", Terminology: "tam", Name: "Tamil"},
+	{Alpha2: "te", Bibliographic: "tel", Terminology: "tel", Name: "Telugu"},
+	{Alpha2: "tg", Bibliographic: "tgk", Terminology: "tgk", Name: "Tajik"},
+	{Alpha2: "th", Bibliographic: "tha", Terminology: "tha", Name: "Thai"},
+	{Alpha2: "ti", Bibliographic: "tir", Terminology: "tir", Name: "Tigrinya"},
+	{Alpha2: "tk", Bibliographic: "tuk", Terminology: "tuk", Name: "Turkmen"},
+	{Alpha2: "tl", Bibliographic: "tgl", Terminology: "tgl", Name: "Tagalog"},
+	{Alpha2: "tn", Bibliographic: "tsn", Terminology: "tsn", Name: "Tswana"},
+	{Alpha2: "to", Bibliographic: "ton", Terminology: "ton", Name: "Tonga"},
+	{Alpha2: "tr", Bibliographic: "tur", Terminology: "tur", Name: "Turkish"},
+	{Alpha2: "ts", Bibliographic: "tso", Terminology: "tso", Name: "Tsonga"},
+	{Alpha2: "tt", Bibliographic: "tat", Terminology: "tat", Name: "Tatar"},
+	{Alpha2: "tw", Bibliographic: "twi", Terminology: "twi", Name: "Twi"},
+	{Alpha2: "ty", Bibliographic: "tah", Terminology: "tah", Name: "Tahitian"},
+	{Alpha2: "ug", Bibliographic: "uig", Terminology: "uig", Name: "Uighur"},
+	{Alpha2: "uk", Bibliographic: "ukr", Terminology: "ukr", Name: "Ukrainian"},
+	{Alpha2: "ur", Bibliographic: "urd", Terminology: "urd", Name: "Urdu"},
+	{Alpha2: "uz", Bibliographic: "uzb", Terminology: "uzb", Name: "Uzbek"},
+	{Alpha2: "ve", Bibliographic: "ven", Terminology: "ven", Name: "Venda"},
+	{Alpha2: "vi", Bibliographic: "vie", Terminology: "vie", Name: "Vietnamese"},
+	{Alpha2: "vo", Bibliographic: "vol", Terminology: "vol", Name: "Volapük"},
+	{Alpha2: "wa", Bibliographic: "wln", Terminology: "wln", Name: "Walloon"},
+	{Alpha2: "wo", Bibliographic: "wol", Terminology: "wol", Name: "Wolof"},
+	{Alpha2: "xh", Bibliographic: "xho", Terminology: "xho", Name: "Xhosa"},
+	{Alpha2: "yi", Bibliographic: "yid", Terminology: "yid", Name: "Yiddish"},
+	{Alpha2: "yo", Bibliographic: "yor", Terminology: "yor", Name: "Yoruba"},
+	{Alpha2: "za", Bibliographic: "zha", Terminology: "zha", Name: "Zhuang"},
+	{Alpha2: "zh", Bibliographic: "chi", Terminology: "zho", Name: "Chinese"},
+	{Alpha2: "zu", Bibliographic: "zul", Terminology: "zul", Name: "Zulu"},
+	{Alpha2: "", Bibliographic: "fil", Terminology: "fil", Name: "Filipino"},
+	{Alpha2: "", Bibliographic: "mul", Terminology: "mul", Name: "Multiple languages"},
+	{Alpha2: "", Bibliographic: "und", Terminology: "und", Name: "Undetermined"},
+	{Alpha2: "", Bibliographic: "zxx", Terminology: "zxx", Name: "No linguistic content"},
+}
@@ -0,0 +1,144 @@
+// Package languages normalizes the language tags found in media files.
+//
+// Tags may use ISO 639-1 codes ("fr"), ISO 639-2/B codes ("fre"), ISO 639-2/T
+// codes ("fra") or English names ("French"). They are all normalized to the
+// ISO 639-2/B code, the one used by Matroska and FFmpeg.
+package languages
+
+import (
+	"sort"
+	"strings"
+)
+
+// Undetermined is the code of tracks without a language
+const Undetermined = "und"
+
+// Language is an entry of the language database
+type Language struct {
+	// Alpha2 is the ISO 639-1 code, empty for languages without one
+	Alpha2 string
+	// Bibliographic is the ISO 639-2/B code, used as the canonical code
+	Bibliographic string
+	// Terminology is the ISO 639-2/T code
+	Terminology string
+	// Name is the English name
+	Name string
+}
+
+// aliases are other English names of some languages
+var aliases = map[string]string{
+	"castilian":      "spa",
+	"flemish":        "dut",
+	"farsi":          "per",
+	"moldavian":      "rum",
+	"moldovan":       "rum",
+	"gaelic":         "gla",
+	"panjabi":        "pan",
+	"pushto":         "pus",
+	"uyghur":         "uig",
+	"kyrgyz":         "kir",
+	"haitian creole": "hat",
+	"sinhalese":      "sin",
+	"slovene":        "slv",
+	"bokmal":         "nob",
+	"nynorsk":        "nno",
+}
+
+// index maps every lowercase code, name and alias to its language
+var index = buildIndex()
+
+func buildIndex() map[string]*Language {
+	idx := make(map[string]*Language, len(languages)*4)
+	for i := range languages {
+		language := &languages[i]
+		for _, key := range []string{language.Alpha2, language.Bibliographic, language.Terminology, language.Name} {
+			if key != "" {
+				idx[strings.ToLower(key)] = language
+			}
+		}
+	}
+	for alias, code := range aliases {
+		idx[alias] = idx[code]
+	}
+	return idx
+}
+
+// Lookup finds a language by ISO 639-1 code, ISO 639-2/B or /T code, or
+// English name, ignoring case. Region suffixes such as "en-US" are ignored.
+func Lookup(value string) (Language, bool) {
+	key := strings.ToLower(strings.TrimSpace(value))
+	if language, ok := index[key]; ok {
+		return *language, true
+	}
+	if base, _, found := strings.Cut(key, "-"); found {
+		if language, ok := index[base]; ok {
+			return *language, true
+		}
+	}
+	return Language{}, false
+}
+
+// Canonical returns the ISO 639-2/B code of a language. Unknown values are
+// returned lowercased and empty values give Undetermined.
+func Canonical(value string) string {
+	if strings.TrimSpace(value) == "" {
+		return Undetermined
+	}
+	if language, ok := Lookup(value); ok {
+		return language.Bibliographic
+	}
+	return strings.ToLower(strings.TrimSpace(value))
+}
+
+// DisplayName returns the English name of a language, or the value itself when it is unknown
+func DisplayName(value string) string {
+	if language, ok := Lookup(value); ok {
+		return language.Name
+	}
+	return value
+}
+
+// Match reports whether two values designate the same language
+func Match(a, b string) bool {
+	return Canonical(a) == Canonical(b)
+}
+
+// Contains reports whether a language matches a partial search: the codes
+// are compared exactly and the English name must contain the search
+func Contains(value, search string) bool {
+	if Match(value, search) {
+		return true
+	}
+	search = strings.ToLower(strings.TrimSpace(search))
+	return search != "" && strings.Contains(strings.ToLower(DisplayName(value)), search)
+}
+
+// All returns every language, sorted by name
+func All() []Language {
+	all := make([]Language, len(languages))
+	copy(all, languages)
+	sort.Slice(all, func(i, j int) bool { return all[i].Name < all[j].Name })
+	return all
+}
+
+// Names returns the names of every language, sorted
+func Names() []string {
+	all := All()
+	names := make([]string, len(all))
+	for i, language := range all {
+		names[i] = language.Name
+	}
+	return names
+}
+
+// commonCodes are the languages most often found in media libraries
+var commonCodes = []string{"fre", "eng", "spa", "ger", "ita", "jpn", "kor", "chi", "por", "rus", "ara", "hin"}
+
+// CommonNames returns the names of the languages most often found in media libraries
+func CommonNames() []string {
+	names := make([]string, len(commonCodes))
+	for i, code := range commonCodes {
+		names[i] = DisplayName(code)
+	}
+	return names
+}
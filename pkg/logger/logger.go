@@ -117,3 +117,6 @@ func Warnf(format string, v ...interface{})  { defaultLogger.Warnf(format, v...)
 func Error(v ...interface{})                 { defaultLogger.Error(v...) }
 func Errorf(format string, v ...interface{}) { defaultLogger.Errorf(format, v...) }
 func SetLevel(level Level)                   { defaultLogger.SetLevel(level) }
+
+// SetDefault replaces the logger used by the package-level functions
+func SetDefault(l *Logger) { defaultLogger = l }
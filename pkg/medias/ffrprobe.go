@@ -161,8 +161,18 @@ const (
 
 // probeData is the root json data structure returned by an ffprobe.
 type probeData struct {
-	Streams []*stream `json:"streams"`
-	Format  *format   `json:"format"`
+	Streams  []*stream  `json:"streams"`
+	Format   *format    `json:"format"`
+	Chapters []*chapter `json:"chapters"`
+}
+
+// chapter is a json data structure to represent chapters
+type chapter struct {
+	ID               int64   `json:"id"`
+	TimeBase         string  `json:"time_base"`
+	StartTimeSeconds float64 `json:"start_time,string"`
+	EndTimeSeconds   float64 `json:"end_time,string"`
+	TagList          tags    `json:"tags"`
 }
 
 // format is a json data structure to represent formats
@@ -214,6 +224,8 @@ type stream struct {
 	Level              int               `json:"level,omitempty"`
 	ColorRange         string            `json:"color_range,omitempty"`
 	ColorSpace         string            `json:"color_space,omitempty"`
+	ColorTransfer      string            `json:"color_transfer,omitempty"`
+	ColorPrimaries     string            `json:"color_primaries,omitempty"`
 	SampleFmt          string            `json:"sample_fmt,omitempty"`
 	SampleRate         string            `json:"sample_rate,omitempty"`
 	Channels           int               `json:"channels,omitempty"`
@@ -307,20 +319,53 @@ type Video struct {
 	Width       int    `json:"width"`
 	Height      int    `json:"height"`
 	Bitrate     string `json:"bit_rate,omitempty"`
+	FrameRate   string `json:"r_frame_rate,omitempty"`
+	PixFmt      string `json:"pix_fmt,omitempty"`
+	// ColorTransfer is the transfer characteristics, e.g. smpte2084 for HDR10
+	ColorTransfer string `json:"color_transfer,omitempty"`
+	Profile       string `json:"profile,omitempty"`
+	Level         int    `json:"level,omitempty"`
 }
 
 type Audio struct {
-	StreamIndex int    `json:"index"`
-	CodecName   string `json:"codec_name"`
-	Channels    int    `json:"channels"`
-	Language    string `json:"language"`
-	Bitrate     string `json:"bit_rate,omitempty"`
+	StreamIndex   int    `json:"index"`
+	CodecName     string `json:"codec_name"`
+	Channels      int    `json:"channels"`
+	Language      string `json:"language"`
+	Bitrate       string `json:"bit_rate,omitempty"`
+	SampleRate    string `json:"sample_rate,omitempty"`
+	ChannelLayout string `json:"channel_layout,omitempty"`
+	Default       bool   `json:"default,omitempty"`
+	Original      bool   `json:"original,omitempty"`
+	// Loudness is set once the stream was analyzed; ffprobe doesn't measure it
+	Loudness *Loudness `json:"loudness,omitempty"`
+}
+
+// Loudness is the EBU R128 loudness of an audio stream
+type Loudness struct {
+	// Integrated is the integrated loudness in LUFS
+	Integrated float64 `json:"integrated"`
+	// TruePeak is the maximum true peak in dBTP
+	TruePeak float64 `json:"true_peak"`
+	// LRA is the loudness range in LU
+	LRA float64 `json:"lra"`
+	// Threshold is the gating threshold in LUFS, needed to normalize the stream
+	Threshold float64 `json:"threshold"`
 }
 
 type Subtitle struct {
 	StreamIndex int    `json:"index"`
 	CodecName   string `json:"codec_name"`
 	Language    string `json:"language"`
+	Default     bool   `json:"default,omitempty"`
+	Forced      bool   `json:"forced,omitempty"`
+}
+
+type Chapter struct {
+	ID        int64         `json:"id"`
+	StartTime time.Duration `json:"start_time"`
+	EndTime   time.Duration `json:"end_time"`
+	Title     string        `json:"title,omitempty"`
 }
 
 type FfprobeData struct {
@@ -335,6 +380,7 @@ type FfprobeResult struct {
 	Videos    []Video     `json:"video"`
 	Audios    []Audio     `json:"audio"`
 	Subtitles []Subtitle  `json:"subtitle"`
+	Chapters  []Chapter   `json:"chapters,omitempty"`
 }
 
 type FfprobeOptions struct {
@@ -352,8 +398,9 @@ var (
 	FFPROBE_LOGLEVEL_VERBOSE FfprobeOptions = FfprobeOptions{"-loglevel", "verbose"}
 	FFPROBE_LOGLEVEL_DEBUG   FfprobeOptions = FfprobeOptions{"-loglevel", "debug"}
 
-	SHOW_FORMAT  FfprobeOptions = FfprobeOptions{"-show_format", ""}
-	SHOW_STREAMS FfprobeOptions = FfprobeOptions{"-show_streams", ""}
+	SHOW_FORMAT   FfprobeOptions = FfprobeOptions{"-show_format", ""}
+	SHOW_STREAMS  FfprobeOptions = FfprobeOptions{"-show_streams", ""}
+	SHOW_CHAPTERS FfprobeOptions = FfprobeOptions{"-show_chapters", ""}
 
 	PRINT_FORMAT_JSON FfprobeOptions = FfprobeOptions{"-print_format", "json"}
 	EXPERIMENTAL      FfprobeOptions = FfprobeOptions{"-strict", "experimental"}
@@ -446,22 +493,31 @@ func (f *Ffprobe) Probe(ctx context.Context) (*FfprobeResult, error) {
 
 	for i, stream := range data.streamType(StreamVideo) {
 		result.Videos[i] = Video{
-			StreamIndex: stream.Index,
-			CodecName:   stream.CodecName,
-			Width:       stream.Width,
-			Height:      stream.Height,
-			Bitrate:     f.extractBitrate(&stream),
+			StreamIndex:   stream.Index,
+			CodecName:     stream.CodecName,
+			Width:         stream.Width,
+			Height:        stream.Height,
+			Bitrate:       f.extractBitrate(&stream),
+			FrameRate:     stream.RFrameRate,
+			PixFmt:        stream.PixFmt,
+			ColorTransfer: stream.ColorTransfer,
+			Profile:       stream.Profile,
+			Level:         stream.Level,
 		}
 	}
 
 	for i, stream := range data.streamType(StreamAudio) {
 
 		result.Audios[i] = Audio{
-			StreamIndex: stream.Index,
-			CodecName:   stream.CodecName,
-			Channels:    stream.Channels,
-			Language:    stream.tags.Language,
-			Bitrate:     f.extractBitrate(&stream),
+			StreamIndex:   stream.Index,
+			CodecName:     stream.CodecName,
+			Channels:      stream.Channels,
+			Language:      stream.tags.Language,
+			Bitrate:       f.extractBitrate(&stream),
+			SampleRate:    stream.SampleRate,
+			ChannelLayout: stream.ChannelLayout,
+			Default:       stream.Disposition.Default == 1,
+			Original:      stream.Disposition.Original == 1,
 		}
 	}
 
@@ -470,7 +526,22 @@ func (f *Ffprobe) Probe(ctx context.Context) (*FfprobeResult, error) {
 			StreamIndex: stream.Index,
 			CodecName:   stream.CodecName,
 			Language:    stream.tags.Language,
+			Default:     stream.Disposition.Default == 1,
+			Forced:      stream.Disposition.Forced == 1,
+		}
+	}
+
+	for _, chapter := range data.Chapters {
+		if chapter == nil {
+			continue
 		}
+		title, _ := chapter.TagList.GetString("title")
+		result.Chapters = append(result.Chapters, Chapter{
+			ID:        chapter.ID,
+			StartTime: time.Duration(chapter.StartTimeSeconds * float64(time.Second)),
+			EndTime:   time.Duration(chapter.EndTimeSeconds * float64(time.Second)),
+			Title:     title,
+		})
 	}
 
 	return result, nil
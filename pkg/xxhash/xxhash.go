@@ -0,0 +1,157 @@
+// Package xxhash implements the 64-bit xxHash algorithm (XXH64), a fast
+// non-cryptographic hash suited to detecting accidental changes in files
+package xxhash
+
+import (
+	"encoding/binary"
+	"hash"
+	"math/bits"
+)
+
+const (
+	prime1 uint64 = 11400714785074694791
+	prime2 uint64 = 14029467366897019727
+	prime3 uint64 = 1609587929392839161
+	prime4 uint64 = 9650029242287828579
+	prime5 uint64 = 2870177450012600261
+
+	// Size is the size of an XXH64 checksum in bytes
+	Size = 8
+	// BlockSize is the number of bytes the hash consumes at once
+	BlockSize = 32
+)
+
+// Digest computes an XXH64 checksum incrementally
+type Digest struct {
+	seed  uint64
+	v1    uint64
+	v2    uint64
+	v3    uint64
+	v4    uint64
+	total uint64
+	mem   [BlockSize]byte
+	n     int // number of bytes buffered in mem
+}
+
+var _ hash.Hash64 = (*Digest)(nil)
+
+// New returns a digest with a seed of zero
+func New() *Digest {
+	return NewWithSeed(0)
+}
+
+// NewWithSeed returns a digest with the given seed
+func NewWithSeed(seed uint64) *Digest {
+	d := &Digest{seed: seed}
+	d.Reset()
+	return d
+}
+
+// Sum64 returns the XXH64 checksum of data with a seed of zero
+func Sum64(data []byte) uint64 {
+	d := New()
+	d.Write(data)
+	return d.Sum64()
+}
+
+// Reset clears the digest to its initial state
+func (d *Digest) Reset() {
+	d.v1 = d.seed + prime1 + prime2
+	d.v2 = d.seed + prime2
+	d.v3 = d.seed
+	d.v4 = d.seed - prime1
+	d.total = 0
+	d.n = 0
+}
+
+// Size returns the number of bytes Sum appends
+func (d *Digest) Size() int { return Size }
+
+// BlockSize returns the block size of the hash
+func (d *Digest) BlockSize() int { return BlockSize }
+
+// Write adds data to the digest; it never fails
+func (d *Digest) Write(data []byte) (int, error) {
+	n := len(data)
+	d.total += uint64(n)
+
+	// Fill the buffered block first
+	if d.n+len(data) < BlockSize {
+		d.n += copy(d.mem[d.n:], data)
+		return n, nil
+	}
+	if d.n > 0 {
+		copied := copy(d.mem[d.n:], data)
+		d.consume(d.mem[:])
+		data = data[copied:]
+		d.n = 0
+	}
+
+	for len(data) >= BlockSize {
+		d.consume(data[:BlockSize])
+		data = data[BlockSize:]
+	}
+	d.n = copy(d.mem[:], data)
+	return n, nil
+}
+
+// consume processes a block of BlockSize bytes
+func (d *Digest) consume(block []byte) {
+	d.v1 = round(d.v1, binary.LittleEndian.Uint64(block[0:8]))
+	d.v2 = round(d.v2, binary.LittleEndian.Uint64(block[8:16]))
+	d.v3 = round(d.v3, binary.LittleEndian.Uint64(block[16:24]))
+	d.v4 = round(d.v4, binary.LittleEndian.Uint64(block[24:32]))
+}
+
+// Sum appends the big-endian checksum to b
+func (d *Digest) Sum(b []byte) []byte {
+	return binary.BigEndian.AppendUint64(b, d.Sum64())
+}
+
+// Sum64 returns the checksum of the data written so far
+func (d *Digest) Sum64() uint64 {
+	var h uint64
+	if d.total >= BlockSize {
+		h = bits.RotateLeft64(d.v1, 1) + bits.RotateLeft64(d.v2, 7) + bits.RotateLeft64(d.v3, 12) + bits.RotateLeft64(d.v4, 18)
+		h = mergeRound(h, d.v1)
+		h = mergeRound(h, d.v2)
+		h = mergeRound(h, d.v3)
+		h = mergeRound(h, d.v4)
+	} else {
+		h = d.seed + prime5
+	}
+	h += d.total
+
+	tail := d.mem[:d.n]
+	for ; len(tail) >= 8; tail = tail[8:] {
+		h ^= round(0, binary.LittleEndian.Uint64(tail))
+		h = bits.RotateLeft64(h, 27)*prime1 + prime4
+	}
+	if len(tail) >= 4 {
+		h ^= uint64(binary.LittleEndian.Uint32(tail)) * prime1
+		h = bits.RotateLeft64(h, 23)*prime2 + prime3
+		tail = tail[4:]
+	}
+	for _, b := range tail {
+		h ^= uint64(b) * prime5
+		h = bits.RotateLeft64(h, 11) * prime1
+	}
+
+	h ^= h >> 33
+	h *= prime2
+	h ^= h >> 29
+	h *= prime3
+	h ^= h >> 32
+	return h
+}
+
+func round(acc, input uint64) uint64 {
+	acc += input * prime2
+	acc = bits.RotateLeft64(acc, 31)
+	return acc * prime1
+}
+
+func mergeRound(acc, val uint64) uint64 {
+	acc ^= round(0, val)
+	return acc*prime1 + prime4
+}
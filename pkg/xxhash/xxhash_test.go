@@ -0,0 +1,92 @@
+package xxhash
+
+import (
+	"encoding/binary"
+	"strings"
+	"testing"
+)
+
+// referenceVectors are XXH64 checksums computed by the reference implementation
+var referenceVectors = []struct {
+	input string
+	seed  uint64
+	want  uint64
+}{
+	{"", 0, 0xef46db3751d8e999},
+	{"a", 0, 0xd24ec4f1a98c6e5b},
+	{"abc", 0, 0x44bc2cf5ad770999},
+	{"message digest", 0, 0x066ed728fceeb3be},
+	{"abcdefghijklmnopqrstuvwxyz", 0, 0xcfe1f278fa89835c},
+	{"Nobody inspects the spammish repetition", 0, 0xfbcea83c8a378bf1},
+	{strings.Repeat("1234567890", 8), 0, 0xe04a477f19ee145d},
+	{"", 1, 0xd5afba1336a3be4b},
+	{"abc", 1, 0xbea9ca8199328908},
+}
+
+func TestReferenceVectors(t *testing.T) {
+	for _, vector := range referenceVectors {
+		d := NewWithSeed(vector.seed)
+		d.Write([]byte(vector.input))
+		if got := d.Sum64(); got != vector.want {
+			t.Errorf("XXH64(%q, seed %d) = %#016x, want %#016x", vector.input, vector.seed, got, vector.want)
+		}
+
+		if vector.seed == 0 {
+			if got := Sum64([]byte(vector.input)); got != vector.want {
+				t.Errorf("Sum64(%q) = %#016x, want %#016x", vector.input, got, vector.want)
+			}
+		}
+	}
+}
+
+func TestSplitWrites(t *testing.T) {
+	// Long enough to cross several blocks and leave a partial one
+	data := make([]byte, 3*BlockSize+13)
+	for i := range data {
+		data[i] = byte(i*7 + 3)
+	}
+	want := Sum64(data)
+
+	for split := 0; split <= len(data); split++ {
+		d := New()
+		d.Write(data[:split])
+		d.Write(data[split:])
+		if got := d.Sum64(); got != want {
+			t.Fatalf("split at %d: got %#016x, want %#016x", split, got, want)
+		}
+	}
+
+	for size := 1; size <= BlockSize+1; size++ {
+		d := New()
+		for i := 0; i < len(data); i += size {
+			d.Write(data[i:min(i+size, len(data))])
+		}
+		if got := d.Sum64(); got != want {
+			t.Fatalf("writes of %d bytes: got %#016x, want %#016x", size, got, want)
+		}
+	}
+}
+
+func TestSumAndReset(t *testing.T) {
+	d := New()
+	d.Write([]byte("abc"))
+
+	sum := d.Sum([]byte("prefix"))
+	if !strings.HasPrefix(string(sum), "prefix") || len(sum) != len("prefix")+Size {
+		t.Fatalf("Sum did not append %d bytes: %x", Size, sum)
+	}
+	if got := binary.BigEndian.Uint64(sum[len("prefix"):]); got != 0x44bc2cf5ad770999 {
+		t.Errorf("Sum = %#016x, want %#016x", got, uint64(0x44bc2cf5ad770999))
+	}
+
+	// Summing doesn't change the state of the digest
+	d.Write([]byte("def"))
+	if got, want := d.Sum64(), Sum64([]byte("abcdef")); got != want {
+		t.Errorf("after Sum and Write: got %#016x, want %#016x", got, want)
+	}
+
+	d.Reset()
+	if got := d.Sum64(); got != 0xef46db3751d8e999 {
+		t.Errorf("after Reset: got %#016x, want the empty checksum", got)
+	}
+}